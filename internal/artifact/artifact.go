@@ -0,0 +1,180 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package artifact reads the version information of the app contained in a build artifact
+package artifact
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cidertool/cider/internal/closer"
+	"github.com/cidertool/cider/internal/plist"
+)
+
+// Keys of the Info.plist values read from an app.
+const (
+	keyBundleID = "CFBundleIdentifier"
+	keyVersion  = "CFBundleShortVersionString"
+	keyBuild    = "CFBundleVersion"
+)
+
+// ErrNoApp happens when an artifact doesn't contain an app.
+var ErrNoApp = errors.New("no app found in artifact")
+
+// ErrUnsupported happens when the type of an artifact isn't recognized.
+type ErrUnsupported struct {
+	Path string
+}
+
+func (e ErrUnsupported) Error() string {
+	return fmt.Sprintf("unsupported artifact %s, expected an .ipa, .pkg or .xcarchive", e.Path)
+}
+
+// ErrMissingKey happens when the app in an artifact is missing a required Info.plist key.
+type ErrMissingKey struct {
+	Key string
+}
+
+func (e ErrMissingKey) Error() string {
+	return fmt.Sprintf("app in artifact has no %s", e.Key)
+}
+
+// Info describes the app contained in an artifact.
+type Info struct {
+	BundleID string
+	Version  string
+	Build    string
+}
+
+// Read returns information about the app contained in the .ipa, .pkg or .xcarchive at the given path.
+func Read(path string) (*Info, error) {
+	var (
+		info *Info
+		err  error
+	)
+
+	switch strings.ToLower(filepath.Ext(strings.TrimRight(path, `/\`))) {
+	case ".ipa":
+		info, err = readIPA(path)
+	case ".xcarchive":
+		info, err = readXCArchive(path)
+	case ".pkg":
+		info, err = readPkg(path)
+	default:
+		return nil, ErrUnsupported{Path: path}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := info.validate(); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (i *Info) validate() error {
+	switch {
+	case i.BundleID == "":
+		return ErrMissingKey{Key: keyBundleID}
+	case i.Version == "":
+		return ErrMissingKey{Key: keyVersion}
+	case i.Build == "":
+		return ErrMissingKey{Key: keyBuild}
+	}
+
+	return nil
+}
+
+func infoFromDict(dict plist.Dict) *Info {
+	return &Info{
+		BundleID: dict.String(keyBundleID),
+		Version:  dict.String(keyVersion),
+		Build:    dict.String(keyBuild),
+	}
+}
+
+// readIPA reads the Info.plist of the app in the Payload directory of an .ipa.
+func readIPA(filename string) (*Info, error) {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close(r)
+
+	for _, f := range r.File {
+		dir, name := path.Split(f.Name)
+		if name != "Info.plist" || path.Ext(strings.TrimSuffix(dir, "/")) != ".app" || path.Dir(path.Dir(f.Name)) != "Payload" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(rc)
+		closer.Close(rc)
+
+		if err != nil {
+			return nil, err
+		}
+
+		dict, err := plist.Decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return infoFromDict(dict), nil
+	}
+
+	return nil, ErrNoApp
+}
+
+// readXCArchive reads the properties of the archived app from the archive's own Info.plist.
+func readXCArchive(dir string) (*Info, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "Info.plist"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoApp
+	} else if err != nil {
+		return nil, err
+	}
+
+	dict, err := plist.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	props := dict.Dict("ApplicationProperties")
+	if props == nil {
+		return nil, ErrNoApp
+	}
+
+	return infoFromDict(props), nil
+}
@@ -0,0 +1,296 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package artifact
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>%s</string>
+	<key>CFBundleShortVersionString</key>
+	<string>1.2.3</string>
+	<key>CFBundleVersion</key>
+	<string>42</string>
+</dict>
+</plist>`
+
+var testInfo = &Info{BundleID: "com.app.bundleid", Version: "1.2.3", Build: "42"}
+
+// Test Read
+
+func TestRead_ErrUnsupported(t *testing.T) {
+	t.Parallel()
+
+	info, err := Read("App.zip")
+	assert.EqualError(t, err, "unsupported artifact App.zip, expected an .ipa, .pkg or .xcarchive")
+	assert.Nil(t, info)
+}
+
+// Test IPA
+
+func TestRead_IPA(t *testing.T) {
+	t.Parallel()
+
+	path := writeIPA(t, map[string]string{
+		"Payload/App.app/Frameworks/Kit.framework/Info.plist": fmt.Sprintf(testInfoPlist, "com.app.kit"),
+		"Payload/App.app/PlugIns/Widget.appex/Info.plist":     fmt.Sprintf(testInfoPlist, "com.app.widget"),
+		"Payload/App.app/Info.plist":                          fmt.Sprintf(testInfoPlist, "com.app.bundleid"),
+	})
+
+	info, err := Read(path)
+	assert.NoError(t, err)
+	assert.Equal(t, testInfo, info)
+}
+
+func TestRead_IPAErrNoApp(t *testing.T) {
+	t.Parallel()
+
+	path := writeIPA(t, map[string]string{
+		"Payload/App.app/PlugIns/Widget.appex/Info.plist": fmt.Sprintf(testInfoPlist, "com.app.widget"),
+	})
+
+	info, err := Read(path)
+	assert.Equal(t, ErrNoApp, err)
+	assert.Nil(t, info)
+}
+
+func TestRead_IPAErrMissingKey(t *testing.T) {
+	t.Parallel()
+
+	path := writeIPA(t, map[string]string{
+		"Payload/App.app/Info.plist": `<plist><dict><key>CFBundleIdentifier</key><string>com.app</string></dict></plist>`,
+	})
+
+	info, err := Read(path)
+	assert.EqualError(t, err, "app in artifact has no CFBundleShortVersionString")
+	assert.Nil(t, info)
+}
+
+func TestRead_IPAErrNotZip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "App.ipa")
+	err := os.WriteFile(path, []byte("not a zip"), 0600)
+	assert.NoError(t, err)
+
+	info, err := Read(path)
+	assert.Error(t, err)
+	assert.Nil(t, info)
+}
+
+// Test XCArchive
+
+func TestRead_XCArchive(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "App.xcarchive")
+	err := os.Mkdir(dir, 0750)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "Info.plist"), []byte(`<plist version="1.0"><dict>
+	<key>ApplicationProperties</key>
+	<dict>
+		<key>CFBundleIdentifier</key><string>com.app.bundleid</string>
+		<key>CFBundleShortVersionString</key><string>1.2.3</string>
+		<key>CFBundleVersion</key><string>42</string>
+	</dict>
+	<key>Name</key><string>App</string>
+</dict></plist>`), 0600)
+	assert.NoError(t, err)
+
+	info, err := Read(dir + string(filepath.Separator))
+	assert.NoError(t, err)
+	assert.Equal(t, testInfo, info)
+}
+
+func TestRead_XCArchiveErrNoApp(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "App.xcarchive")
+	err := os.Mkdir(dir, 0750)
+	assert.NoError(t, err)
+
+	info, err := Read(dir)
+	assert.Equal(t, ErrNoApp, err)
+	assert.Nil(t, info)
+
+	err = os.WriteFile(filepath.Join(dir, "Info.plist"), []byte(`<plist><dict/></plist>`), 0600)
+	assert.NoError(t, err)
+
+	info, err = Read(dir)
+	assert.Equal(t, ErrNoApp, err)
+	assert.Nil(t, info)
+}
+
+// Test Pkg
+
+const testPackageInfo = `<?xml version="1.0" encoding="utf-8"?>
+<pkg-info format-version="2" identifier="com.app.bundleid.pkg" version="1.2.3" install-location="/Applications" auth="root">
+    <payload numberOfFiles="12" installKBytes="512"/>
+    <bundle path="./App.app" id="com.app.bundleid" CFBundleShortVersionString="1.2.3" CFBundleVersion="42">
+        <bundle path="./Contents/Frameworks/Kit.framework" id="com.app.kit" CFBundleShortVersionString="1.0" CFBundleVersion="1"/>
+    </bundle>
+    <bundle-version>
+        <bundle id="com.app.bundleid"/>
+    </bundle-version>
+</pkg-info>`
+
+func TestRead_PkgProductArchive(t *testing.T) {
+	t.Parallel()
+
+	path := writePkg(t, true, []byte(testPackageInfo))
+
+	info, err := Read(path)
+	assert.NoError(t, err)
+	assert.Equal(t, testInfo, info)
+}
+
+func TestRead_PkgComponent(t *testing.T) {
+	t.Parallel()
+
+	path := writePkg(t, false, []byte(testPackageInfo))
+
+	info, err := Read(path)
+	assert.NoError(t, err)
+	assert.Equal(t, testInfo, info)
+}
+
+func TestRead_PkgErrNoApp(t *testing.T) {
+	t.Parallel()
+
+	path := writePkg(t, true, []byte(`<pkg-info identifier="com.app.tool"><bundle path="./tool.bundle" id="com.app.tool"/></pkg-info>`))
+
+	info, err := Read(path)
+	assert.Equal(t, ErrNoApp, err)
+	assert.Nil(t, info)
+}
+
+func TestRead_PkgErrNotXar(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "App.pkg")
+	err := os.WriteFile(path, bytes.Repeat([]byte("x"), 64), 0600)
+	assert.NoError(t, err)
+
+	info, err := Read(path)
+	assert.EqualError(t, err, "invalid pkg: not a xar archive")
+	assert.Nil(t, info)
+
+	err = os.WriteFile(path, []byte("xar!"), 0600)
+	assert.NoError(t, err)
+
+	info, err = Read(path)
+	assert.EqualError(t, err, "invalid pkg: couldn't read header")
+	assert.Nil(t, info)
+}
+
+func writeIPA(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "App.ipa")
+
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+
+	for name, contents := range files {
+		fw, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = fw.Write([]byte(contents))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, w.Close())
+
+	return path
+}
+
+// writePkg writes a xar archive containing the given PackageInfo, either at its root like a
+// component package or inside a component package like a product archive.
+func writePkg(t *testing.T, product bool, packageInfo []byte) string {
+	t.Helper()
+
+	var heap bytes.Buffer
+
+	// Pad the heap so the PackageInfo doesn't start at offset 0.
+	heap.WriteString("checksum")
+
+	var compressed bytes.Buffer
+
+	zw := zlib.NewWriter(&compressed)
+	_, err := zw.Write(packageInfo)
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+
+	file := fmt.Sprintf(`<file id="2"><name>PackageInfo</name><type>file</type><data>
+<length>%d</length><offset>%d</offset><size>%d</size><encoding style="application/x-gzip"/>
+</data></file>`, compressed.Len(), heap.Len(), len(packageInfo))
+	heap.Write(compressed.Bytes())
+
+	if product {
+		file = `<file id="1"><name>Distribution</name><type>file</type></file>
+<file id="3"><name>App.pkg</name><type>directory</type>` + file + `</file>`
+	}
+
+	toc := []byte(`<?xml version="1.0" encoding="UTF-8"?><xar><toc>` + file + `</toc></xar>`)
+
+	var tocCompressed bytes.Buffer
+
+	zw = zlib.NewWriter(&tocCompressed)
+	_, err = zw.Write(toc)
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+
+	var out bytes.Buffer
+
+	err = binary.Write(&out, binary.BigEndian, xarHeader{
+		Magic:           xarMagic,
+		Size:            xarHeaderSize,
+		Version:         1,
+		TOCCompressed:   uint64(tocCompressed.Len()),
+		TOCUncompressed: uint64(len(toc)),
+	})
+	assert.NoError(t, err)
+	out.Write(tocCompressed.Bytes())
+	out.Write(heap.Bytes())
+
+	path := filepath.Join(t.TempDir(), "App.pkg")
+	assert.NoError(t, os.WriteFile(path, out.Bytes(), 0600))
+
+	return path
+}
@@ -0,0 +1,208 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package artifact
+
+import (
+	"compress/bzip2"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cidertool/cider/internal/closer"
+)
+
+const (
+	xarMagic      = 0x78617221 // "xar!"
+	xarHeaderSize = 28
+	// Upper bound on the size of a table of contents or PackageInfo file, to avoid
+	// allocating unreasonable amounts of memory for a corrupt archive.
+	xarMaxFileSize = 16 << 20
+)
+
+// ErrInvalidPkg happens when a .pkg isn't a valid flat package.
+type ErrInvalidPkg struct {
+	Reason string
+}
+
+func (e ErrInvalidPkg) Error() string {
+	return fmt.Sprintf("invalid pkg: %s", e.Reason)
+}
+
+type xarHeader struct {
+	Magic             uint32
+	Size              uint16
+	Version           uint16
+	TOCCompressed     uint64
+	TOCUncompressed   uint64
+	ChecksumAlgorithm uint32
+}
+
+type xarTOC struct {
+	Files []xarFile `xml:"toc>file"`
+}
+
+type xarFile struct {
+	Name  string    `xml:"name"`
+	Type  string    `xml:"type"`
+	Data  *xarData  `xml:"data"`
+	Files []xarFile `xml:"file"`
+}
+
+type xarData struct {
+	Offset   int64 `xml:"offset"`
+	Length   int64 `xml:"length"`
+	Size     int64 `xml:"size"`
+	Encoding struct {
+		Style string `xml:"style,attr"`
+	} `xml:"encoding"`
+}
+
+// packageInfo is the PackageInfo file of a component package, which records the Info.plist
+// values of the bundles it installs.
+type packageInfo struct {
+	Bundles []struct {
+		Path    string `xml:"path,attr"`
+		ID      string `xml:"id,attr"`
+		Version string `xml:"CFBundleShortVersionString,attr"`
+		Build   string `xml:"CFBundleVersion,attr"`
+	} `xml:"bundle"`
+}
+
+// readPkg reads the app bundle recorded in the PackageInfo of a flat installer package. Flat
+// packages are xar archives, either of a single component package or of a product archive
+// containing component packages.
+func readPkg(filename string) (*Info, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close(f)
+
+	var header xarHeader
+	if err := binary.Read(f, binary.BigEndian, &header); err != nil {
+		return nil, ErrInvalidPkg{"couldn't read header"}
+	}
+
+	if header.Magic != xarMagic || header.Size < xarHeaderSize {
+		return nil, ErrInvalidPkg{"not a xar archive"}
+	}
+
+	if header.TOCCompressed > xarMaxFileSize || header.TOCUncompressed > xarMaxFileSize {
+		return nil, ErrInvalidPkg{"table of contents is too large"}
+	}
+
+	heap := int64(header.Size) + int64(header.TOCCompressed)
+
+	tocData, err := readXarData(f, int64(header.Size), int64(header.TOCCompressed), "application/x-gzip")
+	if err != nil {
+		return nil, err
+	}
+
+	var toc xarTOC
+	if err := xml.Unmarshal(tocData, &toc); err != nil {
+		return nil, ErrInvalidPkg{err.Error()}
+	}
+
+	for _, file := range packageInfoFiles(toc.Files) {
+		data, err := readXarData(f, heap+file.Offset, file.Length, file.Encoding.Style)
+		if err != nil {
+			return nil, err
+		}
+
+		var pkg packageInfo
+		if err := xml.Unmarshal(data, &pkg); err != nil {
+			return nil, ErrInvalidPkg{err.Error()}
+		}
+
+		for _, bundle := range pkg.Bundles {
+			if strings.HasSuffix(bundle.Path, ".app") {
+				return &Info{BundleID: bundle.ID, Version: bundle.Version, Build: bundle.Build}, nil
+			}
+		}
+	}
+
+	return nil, ErrNoApp
+}
+
+// packageInfoFiles returns the PackageInfo files at the root of the archive or in one of
+// the component packages at its root.
+func packageInfoFiles(files []xarFile) []xarData {
+	var found []xarData
+
+	for _, file := range files {
+		if file.Name == "PackageInfo" && file.Data != nil {
+			found = append(found, *file.Data)
+		}
+	}
+
+	for _, file := range files {
+		if file.Type != "directory" || !strings.HasSuffix(file.Name, ".pkg") {
+			continue
+		}
+
+		for _, child := range file.Files {
+			if child.Name == "PackageInfo" && child.Data != nil {
+				found = append(found, *child.Data)
+			}
+		}
+	}
+
+	return found
+}
+
+func readXarData(r io.ReaderAt, offset, length int64, encoding string) ([]byte, error) {
+	if offset < 0 || length < 0 || length > xarMaxFileSize {
+		return nil, ErrInvalidPkg{"file is out of range"}
+	}
+
+	var data io.Reader = io.NewSectionReader(r, offset, length)
+
+	switch encoding {
+	case "", "application/octet-stream":
+	case "application/x-gzip":
+		// Despite the name, xar compresses with zlib.
+		zr, err := zlib.NewReader(data)
+		if err != nil {
+			return nil, ErrInvalidPkg{err.Error()}
+		}
+		defer closer.Close(zr)
+
+		data = zr
+	case "application/x-bzip2":
+		data = bzip2.NewReader(data)
+	default:
+		return nil, ErrInvalidPkg{"unsupported encoding " + encoding}
+	}
+
+	b, err := io.ReadAll(io.LimitReader(data, xarMaxFileSize))
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, ErrInvalidPkg{"file is truncated"}
+	} else if err != nil {
+		return nil, ErrInvalidPkg{err.Error()}
+	}
+
+	return b, nil
+}
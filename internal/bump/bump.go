@@ -0,0 +1,216 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package bump computes the next version of a project from the Conventional Commits made since its latest tag
+package bump
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/cidertool/cider/internal/git"
+	"github.com/cidertool/cider/internal/template"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+// Increment is the part of a version that changes from one release to the next.
+type Increment int
+
+const (
+	// None means no commits warrant a new version.
+	None Increment = iota
+	// Patch means the commits only contain fixes.
+	Patch
+	// Minor means the commits contain new features.
+	Minor
+	// Major means the commits contain breaking changes.
+	Major
+)
+
+// ErrNoChanges happens when none of the commits since the latest tag warrant a new version.
+type ErrNoChanges struct {
+	Tag string
+}
+
+func (e ErrNoChanges) Error() string {
+	if e.Tag == "" {
+		return "no commits warrant a new version"
+	}
+
+	return fmt.Sprintf("no commits since %s warrant a new version", e.Tag)
+}
+
+// Result is the outcome of computing the next version.
+type Result struct {
+	// PreviousTag is the latest tag the next version is computed from, or empty if there are no tags.
+	PreviousTag string
+	// Previous is the version of the latest tag, or 0.0.0 if there are no tags.
+	Previous string
+	// Next is the next version. It is the same as Previous if Increment is None.
+	Next string
+	// Increment is the part of the version that changed.
+	Increment Increment
+}
+
+var headerRegex = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?:`)
+
+func (i Increment) String() string {
+	switch i {
+	case Patch:
+		return "patch"
+	case Minor:
+		return "minor"
+	case Major:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+// Analyze returns the largest increment warranted by the given Conventional Commit messages. A "feat" commit
+// warrants a minor increment, a "fix" commit a patch increment, and a breaking change, noted by a "!" after
+// the type or a BREAKING CHANGE footer, a major increment. Other commits don't warrant a new version.
+func Analyze(messages []string) Increment {
+	inc := None
+
+	for _, message := range messages {
+		if next := analyzeMessage(message); next > inc {
+			inc = next
+		}
+	}
+
+	return inc
+}
+
+func analyzeMessage(message string) Increment {
+	lines := strings.Split(message, "\n")
+
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			return Major
+		}
+	}
+
+	match := headerRegex.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if match == nil {
+		return None
+	}
+
+	if match[2] == "!" {
+		return Major
+	}
+
+	switch strings.ToLower(match[1]) {
+	case "feat":
+		return Minor
+	case "fix":
+		return Patch
+	default:
+		return None
+	}
+}
+
+// Next returns the version following the given version by the given increment.
+func Next(version string, inc Increment) (string, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse version %s: %w", version, err)
+	}
+
+	var next semver.Version
+
+	switch inc {
+	case Patch:
+		next = v.IncPatch()
+	case Minor:
+		next = v.IncMinor()
+	case Major:
+		next = v.IncMajor()
+	default:
+		next = *v
+	}
+
+	return next.String(), nil
+}
+
+// Compute returns the next version from the commits made since the latest tag starting with prefix. The prefix
+// and any other characters before the first digit, such as a leading "v", are removed from the tag to get its version. If there are no tags, every commit is considered
+// and the next version follows 0.0.0.
+func Compute(client *git.Git, prefix string) (Result, error) {
+	var pattern string
+	if prefix != "" {
+		pattern = prefix + "*"
+	}
+
+	result := Result{Previous: "0.0.0"}
+
+	tag, err := client.LatestTag(pattern)
+
+	var noMatchingTag git.ErrNoMatchingTag
+
+	switch {
+	case err == nil:
+		result.PreviousTag = tag
+		result.Previous = strings.TrimLeftFunc(strings.TrimPrefix(tag, prefix), func(r rune) bool {
+			return !unicode.IsDigit(r)
+		})
+	case errors.Is(err, git.ErrNoTag), errors.As(err, &noMatchingTag):
+		// Without a previous tag, every commit counts towards the first version.
+	default:
+		return result, err
+	}
+
+	messages, err := client.CommitMessagesSince(result.PreviousTag)
+	if err != nil {
+		return result, err
+	}
+
+	result.Increment = Analyze(messages)
+
+	result.Next, err = Next(result.Previous, result.Increment)
+
+	return result, err
+}
+
+// DefaultTagTemplate is the template used for tag names when the project doesn't configure one.
+const DefaultTagTemplate = "v{{ .version }}"
+
+// TagName returns the name of the tag for the given version, rendered from the project's tag template
+// and prepended with the given prefix.
+func TagName(ctx *context.Context, prefix, version string) (string, error) {
+	tagTemplate := ctx.Settings.TagTemplate
+	if tagTemplate == "" {
+		tagTemplate = DefaultTagTemplate
+	}
+
+	tctx := *ctx
+	tctx.Version = version
+
+	name, err := template.New(&tctx).Apply(tagTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + name, nil
+}
@@ -0,0 +1,203 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package bump
+
+import (
+	"testing"
+
+	"github.com/cidertool/cider/internal/git"
+	"github.com/cidertool/cider/internal/shell/shelltest"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, None, Analyze(nil))
+	assert.Equal(t, None, Analyze([]string{"chore: update deps", "Merge branch 'main'", "docs(readme): typo"}))
+	assert.Equal(t, Patch, Analyze([]string{"chore: update deps", "fix: crash on launch"}))
+	assert.Equal(t, Minor, Analyze([]string{"fix(ui): alignment", "feat(sync): add iCloud sync"}))
+	assert.Equal(t, Major, Analyze([]string{"feat: add widget", "refactor!: drop iOS 12"}))
+	assert.Equal(t, Major, Analyze([]string{"feat(api)!: new endpoint"}))
+	assert.Equal(t, Major, Analyze([]string{"fix: crash\n\nBREAKING CHANGE: settings are reset"}))
+	assert.Equal(t, Major, Analyze([]string{"fix: crash\n\nBREAKING-CHANGE: settings are reset"}))
+	assert.Equal(t, Patch, Analyze([]string{"fix: mention BREAKING CHANGE: in the header only"}))
+}
+
+func TestIncrement_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "none", None.String())
+	assert.Equal(t, "patch", Patch.String())
+	assert.Equal(t, "minor", Minor.String())
+	assert.Equal(t, "major", Major.String())
+}
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		version  string
+		inc      Increment
+		expected string
+	}{
+		{"1.2.3", None, "1.2.3"},
+		{"1.2.3", Patch, "1.2.4"},
+		{"1.2.3", Minor, "1.3.0"},
+		{"1.2.3", Major, "2.0.0"},
+		{"1.2", Patch, "1.2.1"},
+		{"0.0.0", Minor, "0.1.0"},
+	} {
+		next, err := Next(c.version, c.inc)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, next)
+	}
+
+	_, err := Next("banana", Patch)
+	assert.Error(t, err)
+}
+
+func TestCompute(t *testing.T) {
+	t.Parallel()
+
+	client := newMockGit(
+		t,
+		shelltest.Command{Stdout: "ios-app/v1.4.2\n"},
+		shelltest.Command{Stdout: "feat: add widget\n\x00\nfix: crash\n\x00\n"},
+	)
+
+	result, err := Compute(client, "ios-app/")
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		PreviousTag: "ios-app/v1.4.2",
+		Previous:    "1.4.2",
+		Next:        "1.5.0",
+		Increment:   Minor,
+	}, result)
+}
+
+func TestCompute_NoTags(t *testing.T) {
+	t.Parallel()
+
+	client := newMockGit(
+		t,
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: No names found, cannot describe anything.\n"},
+		shelltest.Command{Stdout: "fix: crash\n\x00\n"},
+	)
+
+	result, err := Compute(client, "")
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Previous:  "0.0.0",
+		Next:      "0.0.1",
+		Increment: Patch,
+	}, result)
+
+	client = newMockGit(
+		t,
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: No tags can describe '4b825dc'.\n"},
+		shelltest.Command{Stdout: "feat: add widget\n\x00\n"},
+	)
+
+	result, err = Compute(client, "tv-app/")
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Previous:  "0.0.0",
+		Next:      "0.1.0",
+		Increment: Minor,
+	}, result)
+}
+
+func TestCompute_Err(t *testing.T) {
+	t.Parallel()
+
+	client := newMockGit(
+		t,
+		shelltest.Command{Stdout: "v1.0.0\n"},
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: bad revision\n"},
+	)
+
+	_, err := Compute(client, "")
+	assert.EqualError(t, err, "fatal: bad revision")
+
+	client = newMockGit(
+		t,
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: detected dubious ownership in repository\n"},
+	)
+
+	_, err = Compute(client, "")
+	assert.EqualError(t, err, "fatal: detected dubious ownership in repository")
+
+	client = newMockGit(
+		t,
+		shelltest.Command{Stdout: "release-candidate\n"},
+		shelltest.Command{Stdout: "fix: crash\n\x00\n"},
+	)
+
+	_, err = Compute(client, "")
+	assert.Error(t, err)
+}
+
+func TestTagName(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+
+	name, err := TagName(ctx, "", "1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.2.0", name)
+
+	name, err = TagName(ctx, "ios-app/", "1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "ios-app/v1.2.0", name)
+
+	ctx.Settings.TagTemplate = "release-{{ .version }}"
+
+	name, err = TagName(ctx, "", "1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "release-1.2.0", name)
+
+	ctx.Settings.TagTemplate = "{{ .version"
+
+	_, err = TagName(ctx, "", "1.2.0")
+	assert.Error(t, err)
+}
+
+func TestErrNoChanges(t *testing.T) {
+	t.Parallel()
+
+	assert.EqualError(t, ErrNoChanges{}, "no commits warrant a new version")
+	assert.EqualError(t, ErrNoChanges{Tag: "v1.0.0"}, "no commits since v1.0.0 warrant a new version")
+}
+
+func newMockGit(t *testing.T, commands ...shelltest.Command) *git.Git {
+	t.Helper()
+
+	return &git.Git{
+		Shell: &shelltest.Shell{
+			T:        t,
+			Context:  context.New(config.Project{}),
+			Commands: commands,
+		},
+	}
+}
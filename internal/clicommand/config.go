@@ -32,8 +32,14 @@ import (
 var ErrConfigNotFound = errors.New("config file not found at any default path")
 
 func loadConfig(path string, wd string) (config.Project, error) {
+	proj, _, err := loadConfigWithSettings(path, wd)
+
+	return proj, err
+}
+
+func loadConfigWithSettings(path string, wd string) (config.Project, config.Settings, error) {
 	if path != "" {
-		return config.Load(path)
+		return config.LoadWithSettings(path)
 	}
 
 	for _, f := range [4]string{
@@ -42,13 +48,13 @@ func loadConfig(path string, wd string) (config.Project, error) {
 		"cider.yml",
 		"cider.yaml",
 	} {
-		proj, err := config.Load(filepath.Join(wd, f))
+		proj, settings, err := config.LoadWithSettings(filepath.Join(wd, f))
 		if err != nil && os.IsNotExist(err) {
 			continue
 		}
 
-		return proj, err
+		return proj, settings, err
 	}
 
-	return config.Project{}, ErrConfigNotFound
+	return config.Project{}, config.Settings{}, ErrConfigNotFound
 }
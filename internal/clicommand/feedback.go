@@ -0,0 +1,195 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/feedback"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+const defaultFeedbackDirectory = "feedback"
+
+func newTestflightFeedbackCmd(opts *testflightOpts) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "feedback",
+		Short:         "Manage the feedback beta testers submit through TestFlight",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.AddCommand(
+		newTestflightFeedbackExportCmd(opts).cmd,
+	)
+
+	return cmd
+}
+
+type testflightFeedbackExportCmd struct {
+	cmd  *cobra.Command
+	opts feedbackExportOpts
+}
+
+type feedbackExportOpts struct {
+	since  string
+	cursor string
+}
+
+func newTestflightFeedbackExportCmd(opts *testflightOpts) *testflightFeedbackExportCmd {
+	var root = &testflightFeedbackExportCmd{}
+
+	var cmd = &cobra.Command{
+		Use:   "export [directory]",
+		Short: "Export the screenshot and crash feedback of beta testers to a directory",
+		Long: `Export the screenshot and crash feedback beta testers submitted through TestFlight to a directory,
+which defaults to ` + "`" + defaultFeedbackDirectory + "`" + `.
+
+The feedback is listed in ` + "`" + feedback.JSONFile + "`" + ` and ` + "`" + feedback.CSVFile + "`" + `, with the device,
+OS version, build and comment of each submission, and the screenshots attached to it are saved in the
+` + "`" + feedback.ScreenshotsDirectory + "`" + ` directory. Feedback exported to the directory before is kept.
+
+With --cursor, the creation date of the newest feedback of each app is saved to a file, and later
+exports only fetch feedback submitted since then, skipping any already in the directory.`,
+		Example:       "cider testflight feedback export triage --since 2021-03-01 --cursor triage/cursor.json",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var dir = defaultFeedbackDirectory
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			var since time.Time
+
+			if root.opts.since != "" {
+				var err error
+				if since, err = feedback.ParseDate(root.opts.since); err != nil {
+					return err
+				}
+			}
+
+			return runTestflight(opts, func(ctx *context.Context, c client.Client, apps []string) error {
+				return exportFeedback(ctx, c, apps, dir, since, root.opts.cursor)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(
+		&root.opts.since,
+		"since",
+		"",
+		"Only export feedback submitted on or after this date, given as 2006-01-02 or as an RFC 3339 time",
+	)
+	cmd.Flags().StringVar(
+		&root.opts.cursor,
+		"cursor",
+		"",
+		"Path to a file recording the newest feedback exported for each app, to only export newer feedback",
+	)
+
+	root.cmd = cmd
+
+	return root
+}
+
+func exportFeedback(ctx *context.Context, c client.Client, apps []string, dir string, since time.Time, cursorPath string) error {
+	var cursor = make(feedback.Cursor)
+
+	if cursorPath != "" {
+		var err error
+		if cursor, err = feedback.ReadCursor(cursorPath); err != nil {
+			return err
+		}
+	}
+
+	existing, err := feedback.Load(dir)
+	if err != nil {
+		return err
+	}
+
+	// Feedback created at the cursor's position is fetched again, and skipped if it was already exported.
+	var exported = make(map[string]bool, len(existing))
+
+	for _, entry := range existing {
+		exported[entry.Key()] = true
+	}
+
+	var entries []feedback.Entry
+
+	for _, name := range apps {
+		bundleID := ctx.Config[name].BundleID
+
+		app, err := c.GetAppForBundleID(ctx, bundleID)
+		if err != nil {
+			return err
+		}
+
+		submissions, err := c.ListBetaFeedback(ctx, app.ID, cursor.Since(bundleID, since))
+		if err != nil {
+			return err
+		}
+
+		var count int
+
+		for _, submission := range submissions {
+			entry := feedback.NewEntry(bundleID, submission)
+			if exported[entry.Key()] {
+				continue
+			}
+
+			for i, url := range submission.Screenshots {
+				path := feedback.ScreenshotPath(entry, i, url)
+				if err := feedback.Download(ctx, url, filepath.Join(dir, path)); err != nil {
+					return err
+				}
+
+				entry.Screenshots = append(entry.Screenshots, path)
+			}
+
+			entries = append(entries, entry)
+			count++
+		}
+
+		ctx.Log.WithFields(log.Fields{
+			"app":   bundleID,
+			"count": count,
+		}).Info("exported beta feedback")
+	}
+
+	if err := feedback.Save(dir, feedback.Merge(existing, entries)); err != nil {
+		return err
+	}
+
+	if cursorPath == "" {
+		return nil
+	}
+
+	cursor.Advance(entries)
+
+	return cursor.Write(cursorPath)
+}
@@ -0,0 +1,142 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/client/clienttest"
+	"github.com/cidertool/cider/internal/feedback"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+type feedbackClient struct {
+	clienttest.Client
+	screenshotURL string
+	since         []time.Time
+}
+
+func (c *feedbackClient) ListBetaFeedback(ctx *context.Context, appID string, since time.Time) ([]client.BetaFeedback, error) {
+	c.since = append(c.since, since)
+
+	return []client.BetaFeedback{
+		{
+			ID:          "s1",
+			Kind:        client.BetaFeedbackScreenshot,
+			CreatedDate: time.Date(2021, time.March, 2, 0, 0, 0, 0, time.UTC),
+			Comment:     "TEST",
+			Screenshots: []string{c.screenshotURL},
+		},
+	}, nil
+}
+
+func TestTestflightCmd_FeedbackInvalidSince(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newTestflightCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"feedback", "export", "--since", "yesterday"})
+
+	err := cmd.cmd.Execute()
+	assert.EqualError(t, err, feedback.ErrInvalidDate{Value: "yesterday"}.Error())
+}
+
+func TestExportFeedback(t *testing.T) {
+	t.Parallel()
+
+	var downloads int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&downloads, 1)
+		_, _ = w.Write([]byte("PNG"))
+	}))
+	defer server.Close()
+
+	ctx := context.New(config.Project{
+		"TEST": {BundleID: "com.test.TEST"},
+	})
+
+	dir := t.TempDir()
+	cursorPath := filepath.Join(dir, "cursor.json")
+	since := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	c := &feedbackClient{screenshotURL: server.URL + "/s1.jpg"}
+
+	err := exportFeedback(ctx, c, []string{"TEST"}, dir, since, cursorPath)
+	assert.NoError(t, err)
+
+	entries, err := feedback.Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []feedback.Entry{
+		{
+			App:         "com.test.TEST",
+			ID:          "s1",
+			Kind:        client.BetaFeedbackScreenshot,
+			CreatedDate: time.Date(2021, time.March, 2, 0, 0, 0, 0, time.UTC),
+			Comment:     "TEST",
+			Screenshots: []string{filepath.Join(feedback.ScreenshotsDirectory, "com.test.TEST-s1-1.jpg")},
+		},
+	}, entries)
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Screenshots[0]))
+	assert.NoError(t, err)
+	assert.Equal(t, "PNG", string(data))
+	assert.FileExists(t, filepath.Join(dir, feedback.CSVFile))
+
+	// The second export resumes from the newest feedback of the first, which is fetched again but
+	// neither downloaded nor listed twice.
+	err = exportFeedback(ctx, c, []string{"TEST"}, dir, since, cursorPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{since, time.Date(2021, time.March, 2, 0, 0, 0, 0, time.UTC)}, c.since)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&downloads))
+
+	entries, err = feedback.Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestExportFeedback_NoCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {BundleID: "com.test.TEST"},
+	})
+
+	dir := filepath.Join(t.TempDir(), "feedback")
+
+	err := exportFeedback(ctx, &clienttest.Client{}, []string{"TEST"}, dir, time.Time{}, "")
+	assert.NoError(t, err)
+
+	entries, err := feedback.Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.NoFileExists(t, filepath.Join(dir, "cursor.json"))
+}
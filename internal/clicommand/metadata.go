@@ -0,0 +1,279 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cidertool/asc-go/asc"
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/internal/template"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// ErrNoPromotionalText happens when metadata promo has no promotional text to update, either from the
+// configuration or from the --text flag.
+var ErrNoPromotionalText = errors.New("no promotional text is configured for the chosen apps, and none was given with --text")
+
+// ErrInvalidPromotionalText happens when a --text flag isn't given in the form locale=text.
+type ErrInvalidPromotionalText struct {
+	Value string
+}
+
+func (e ErrInvalidPromotionalText) Error() string {
+	return fmt.Sprintf("invalid promotional text %q, expected the form locale=text", e.Value)
+}
+
+type metadataCmd struct {
+	cmd  *cobra.Command
+	opts metadataOpts
+}
+
+type metadataOpts struct {
+	debugFlagValue *bool
+	config         string
+	apps           []string
+	maxProcesses   int
+	timeout        time.Duration
+}
+
+func newMetadataCmd(debugFlagValue *bool) *metadataCmd {
+	var root = &metadataCmd{opts: metadataOpts{debugFlagValue: debugFlagValue}}
+
+	var cmd = &cobra.Command{
+		Use:   "metadata",
+		Short: "Update the App Store metadata of apps outside of a release",
+		Long: `Update the App Store metadata of the apps in the configuration that can change outside of a release.
+
+Cider requires the same environment variables as ` + "`cider release`" + ` to authenticate.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.PersistentFlags().StringVarP(&root.opts.config, "config", "f", "", "Load configuration from file")
+	cmd.PersistentFlags().StringArrayVarP(
+		&root.opts.apps,
+		"app",
+		"a",
+		[]string{},
+		"Name of an app in the configuration to process. Can be repeated. Defaults to every app",
+	)
+	cmd.PersistentFlags().IntVarP(
+		&root.opts.maxProcesses,
+		"max-processes",
+		"p",
+		1,
+		"Maximum number of requests to App Store Connect to make at a time",
+	)
+	cmd.PersistentFlags().DurationVar(&root.opts.timeout, "timeout", defaultTimeout, "Timeout for the entire command.")
+
+	cmd.AddCommand(
+		newMetadataPromoCmd(&root.opts).cmd,
+	)
+
+	root.cmd = cmd
+
+	return root
+}
+
+type metadataPromoCmd struct {
+	cmd   *cobra.Command
+	texts []string
+}
+
+func newMetadataPromoCmd(opts *metadataOpts) *metadataPromoCmd {
+	var root = &metadataPromoCmd{}
+
+	var cmd = &cobra.Command{
+		Use:   "promo",
+		Short: "Update the promotional text of the versions of apps that are ready for sale",
+		Long: `Update the promotional text of the versions of apps that are ready for sale, without a new release.
+
+The text is taken from the promotionalText of each locale in the configuration, or from the --text
+flags if any are given. Only the promotional text is changed, and no build or Git tag is needed.
+Apps released on several platforms are updated on each of them. The locales must already exist on
+the version that is ready for sale.`,
+		Example:       `cider metadata promo --app MyApp --text "en-US=Now with dark mode"`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			texts, err := promotionalTextFlags(root.texts)
+			if err != nil {
+				return err
+			}
+
+			return runMetadata(opts, func(ctx *context.Context, c client.Client, apps []string) error {
+				return updatePromotionalText(ctx, c, apps, texts)
+			})
+		},
+	}
+
+	cmd.Flags().StringArrayVar(
+		&root.texts,
+		"text",
+		[]string{},
+		"Promotional text for a locale in the form locale=text, instead of the configured text. Can be repeated",
+	)
+
+	root.cmd = cmd
+
+	return root
+}
+
+// runMetadata loads the configuration and authenticates before running fn with the names of the
+// chosen apps, sorted.
+func runMetadata(opts *metadataOpts, fn func(ctx *context.Context, c client.Client, apps []string) error) error {
+	logger := newLogger(opts.debugFlagValue)
+
+	cfg, err := loadConfig(opts.config, "")
+	if err != nil {
+		return err
+	}
+
+	apps, err := chosenApps(cfg, opts.apps)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.NewWithTimeout(cfg, opts.timeout)
+	defer cancel()
+
+	ctx.Log = logger
+	ctx.MaxProcesses = opts.maxProcesses
+
+	if err := withClient(ctx, func(c client.Client) error {
+		return fn(ctx, c, apps)
+	}); err != nil {
+		return wrapError(err, color.New(color.Bold).Sprintf("updating metadata failed"))
+	}
+
+	return nil
+}
+
+// promotionalTextFlags parses the --text flags into a map of locales to promotional text.
+func promotionalTextFlags(values []string) (map[string]string, error) {
+	var texts = make(map[string]string, len(values))
+
+	for _, value := range values {
+		locale, text, ok := splitAppOverride(value)
+		if !ok || locale == "" {
+			return nil, ErrInvalidPromotionalText{Value: value}
+		}
+
+		texts[locale] = text
+	}
+
+	return texts, nil
+}
+
+func updatePromotionalText(ctx *context.Context, c client.Client, apps []string, overrides map[string]string) error {
+	var updated bool
+
+	for _, name := range apps {
+		appConfig := ctx.Config[name]
+
+		var app *asc.App
+
+		for _, version := range appConfig.Versions.ForPlatforms() {
+			texts, err := promotionalTexts(ctx, version.Localizations, overrides)
+			if err != nil {
+				return err
+			} else if len(texts) == 0 {
+				continue
+			}
+
+			if app == nil {
+				if app, err = c.GetAppForBundleID(ctx, appConfig.BundleID); err != nil {
+					return err
+				}
+			}
+
+			if err := c.UpdateLivePromotionalText(ctx, app.ID, version.Platform, texts); err != nil {
+				return err
+			}
+
+			updated = true
+
+			ctx.Log.WithFields(log.Fields{
+				"app":      appConfig.BundleID,
+				"platform": version.Platform,
+				"locales":  sortedLocales(texts),
+			}).Info("updated promotional text")
+		}
+	}
+
+	if !updated {
+		return ErrNoPromotionalText
+	}
+
+	return nil
+}
+
+// promotionalTexts returns the promotional text to set for each locale, taken from the overrides if there are
+// any or from the configured localizations otherwise.
+func promotionalTexts(ctx *context.Context, localizations config.VersionLocalizations, overrides map[string]string) (map[string]string, error) {
+	var texts = make(map[string]string)
+
+	if len(overrides) > 0 {
+		for locale, text := range overrides {
+			texts[locale] = text
+		}
+	} else {
+		for locale, loc := range localizations {
+			if loc.PromotionalText != "" {
+				texts[locale] = loc.PromotionalText
+			}
+		}
+	}
+
+	tmpl := template.New(ctx)
+
+	for locale, text := range texts {
+		applied, err := tmpl.Apply(text)
+		if err != nil {
+			return nil, err
+		}
+
+		texts[locale] = applied
+	}
+
+	return texts, nil
+}
+
+func sortedLocales(texts map[string]string) []string {
+	locales := make([]string, 0, len(texts))
+	for locale := range texts {
+		locales = append(locales, locale)
+	}
+
+	sort.Strings(locales)
+
+	return locales
+}
@@ -0,0 +1,134 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"testing"
+
+	"github.com/cidertool/cider/internal/client/clienttest"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+type promoRecordingClient struct {
+	clienttest.Client
+	texts map[config.Platform]map[string]string
+}
+
+func (c *promoRecordingClient) UpdateLivePromotionalText(ctx *context.Context, appID string, platform config.Platform, texts map[string]string) error {
+	if c.texts == nil {
+		c.texts = make(map[config.Platform]map[string]string)
+	}
+
+	c.texts[platform] = texts
+
+	return nil
+}
+
+func TestMetadataCmd_InvalidText(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newMetadataCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"promo", "--text", "no separator"})
+
+	err := cmd.cmd.Execute()
+	assert.EqualError(t, err, ErrInvalidPromotionalText{Value: "no separator"}.Error())
+}
+
+func TestPromotionalTextFlags(t *testing.T) {
+	t.Parallel()
+
+	texts, err := promotionalTextFlags([]string{"en-US=Now with dark mode", "fr-FR=Mode sombre = disponible"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"en-US": "Now with dark mode",
+		"fr-FR": "Mode sombre = disponible",
+	}, texts)
+
+	_, err = promotionalTextFlags([]string{"=text"})
+	assert.EqualError(t, err, ErrInvalidPromotionalText{Value: "=text"}.Error())
+}
+
+func TestUpdatePromotionalText_FromConfig(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Versions: config.Version{
+				Platform: config.PlatformiOS,
+				Localizations: config.VersionLocalizations{
+					"en-US": {PromotionalText: "Hello {{ .env.NAME }}"},
+					"ja":    {Description: "No promotional text"},
+				},
+			},
+		},
+	})
+	ctx.Env = context.Env{"NAME": "TEST"}
+
+	client := &promoRecordingClient{}
+
+	err := updatePromotionalText(ctx, client, []string{"TEST"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[config.Platform]map[string]string{
+		config.PlatformiOS: {"en-US": "Hello TEST"},
+	}, client.texts)
+}
+
+func TestUpdatePromotionalText_Overrides(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Versions: config.Version{
+				Platforms: config.PlatformVersions{
+					config.PlatformiOS:   {Localizations: config.VersionLocalizations{"en-US": {PromotionalText: "Old"}}},
+					config.PlatformMacOS: {},
+				},
+			},
+		},
+	})
+
+	client := &promoRecordingClient{}
+
+	err := updatePromotionalText(ctx, client, []string{"TEST"}, map[string]string{"en-US": "New"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[config.Platform]map[string]string{
+		config.PlatformiOS:   {"en-US": "New"},
+		config.PlatformMacOS: {"en-US": "New"},
+	}, client.texts)
+}
+
+func TestUpdatePromotionalText_Nothing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {BundleID: "com.test.TEST"},
+	})
+
+	err := updatePromotionalText(ctx, &promoRecordingClient{}, []string{"TEST"}, nil)
+	assert.EqualError(t, err, ErrNoPromotionalText.Error())
+}
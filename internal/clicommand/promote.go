@@ -0,0 +1,259 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/internal/pipe/env"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// ErrPromoteBuildRequired happens when cider promote isn't given exactly one of the --beta-group and
+// --build-id flags.
+var ErrPromoteBuildRequired = errors.New("exactly one of --beta-group or --build-id must be set")
+
+// ErrPromotedPlatform happens when the build to promote was uploaded for a platform the app isn't
+// configured to release on.
+type ErrPromotedPlatform struct {
+	App      string
+	Platform config.Platform
+}
+
+func (e ErrPromotedPlatform) Error() string {
+	return fmt.Sprintf("app %s isn't configured to release on %s, the platform of the build", e.App, e.Platform)
+}
+
+type promoteCmd struct {
+	cmd  *cobra.Command
+	opts promoteOpts
+}
+
+type promoteOpts struct {
+	config             string
+	app                string
+	betaGroup          string
+	buildID            string
+	maxProcesses       int
+	stateDirectory     string
+	skipUpdatePricing  bool
+	skipUpdateMetadata bool
+	skipSubmit         bool
+	timeout            time.Duration
+}
+
+func newPromoteCmd(debugFlagValue *bool) *promoteCmd {
+	var root = &promoteCmd{}
+
+	var cmd = &cobra.Command{
+		Use:   "promote",
+		Short: "Release a build that passed TestFlight to the App Store",
+		Long: `Release a build that was tested in TestFlight to the App Store.
+
+The build is either the most recently uploaded build of a beta group, or the build with the given
+App Store Connect ID. It must have been processed successfully and approved by Beta App Review.
+Cider then creates or updates the App Store version for that exact build, and continues as
+` + "`cider release --mode=appstore`" + ` would, using the version and build number of the build
+instead of Git.`,
+		Example:       "cider promote --app MyApp --beta-group QA",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (root.opts.betaGroup == "") == (root.opts.buildID == "") {
+				return ErrPromoteBuildRequired
+			}
+
+			if !cmd.Flags().Changed("state-dir") {
+				dir, err := defaultStateDirectory("")
+				if err != nil {
+					return err
+				}
+				root.opts.stateDirectory = dir
+			}
+
+			logger := newLogger(debugFlagValue)
+			start := time.Now()
+
+			logger.Info(color.New(color.Bold).Sprint("promoting..."))
+
+			if err := promoteBuild(root.opts, logger); err != nil {
+				return wrapError(err, color.New(color.Bold).Sprintf("promotion failed after %0.2fs", time.Since(start).Seconds()))
+			}
+
+			logger.Info(color.New(color.Bold).Sprintf("promotion succeeded after %0.2fs", time.Since(start).Seconds()))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&root.opts.config, "config", "f", "", "Load configuration from file")
+	cmd.Flags().StringVarP(
+		&root.opts.app,
+		"app",
+		"a",
+		"",
+		"Name of the app in the configuration to promote. Required if the configuration has more than one app",
+	)
+	cmd.Flags().StringVarP(
+		&root.opts.betaGroup,
+		"beta-group",
+		"g",
+		"",
+		"Name of the beta group whose most recently uploaded build is promoted",
+	)
+	cmd.Flags().StringVar(&root.opts.buildID, "build-id", "", "App Store Connect ID of the build to promote")
+	cmd.Flags().IntVarP(
+		&root.opts.maxProcesses,
+		"max-processes",
+		"p",
+		1,
+		`Run certain metadata syncing and asset uploading logic in parallel with
+the maximum allowable concurrency.`,
+	)
+	cmd.Flags().StringVar(
+		&root.opts.stateDirectory,
+		"state-dir",
+		"",
+		"Directory to save release checkpoints and metadata snapshots in, like with cider release. Defaults to a directory for the project in your user cache directory",
+	)
+	cmd.Flags().BoolVar(&root.opts.skipUpdatePricing, "skip-update-pricing", false, "Skips updating app pricing")
+	cmd.Flags().BoolVar(&root.opts.skipUpdateMetadata, "skip-update-metadata", false, "Skips updating metadata (app info, localizations, assets, review details, etc.)")
+	cmd.Flags().BoolVar(&root.opts.skipSubmit, "skip-submit", false, "Skips submitting for review")
+	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", defaultTimeout, "Timeout for the entire promotion.")
+
+	root.cmd = cmd
+
+	return root
+}
+
+func promoteBuild(options promoteOpts, logger log.Interface) error {
+	cfg, err := loadConfig(options.config, "")
+	if err != nil {
+		return err
+	}
+
+	name, _, err := singleApp(cfg, options.app)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.NewWithTimeout(cfg, options.timeout)
+	defer cancel()
+
+	ctx.Log = logger
+	ctx.AppsToRelease = []string{name}
+	ctx.PublishMode = context.PublishModeAppStore
+	ctx.AppErrorMode = context.AppErrorModeFailFast
+	ctx.MaxProcesses = options.maxProcesses
+	ctx.StateDirectory = options.stateDirectory
+	ctx.SkipGit = true
+	ctx.SkipUpdatePricing = options.skipUpdatePricing
+	ctx.SkipUpdateMetadata = options.skipUpdateMetadata
+	ctx.SkipSubmit = options.skipSubmit
+
+	return context.NewInterrupt().Run(ctx, func() error {
+		if err := (env.Pipe{}).Run(ctx); err != nil {
+			return err
+		}
+
+		if err := choosePromotedBuild(ctx, client.New(ctx), name, options); err != nil {
+			return err
+		}
+
+		return runPipeline(ctx)
+	})
+}
+
+// choosePromotedBuild finds the build to promote and releases the named app with it, on the build's platform only.
+func choosePromotedBuild(ctx *context.Context, c client.Client, name string, options promoteOpts) error {
+	app := ctx.Config[name]
+
+	ascApp, err := c.GetAppForBundleID(ctx, app.BundleID)
+	if err != nil {
+		return err
+	}
+
+	build, err := c.GetPromotableBuild(ctx, ascApp.ID, options.buildID, options.betaGroup)
+	if err != nil {
+		return err
+	}
+
+	versions, err := promotedVersions(name, app.Versions, build.Platform)
+	if err != nil {
+		return err
+	}
+
+	app.Versions = versions
+	ctx.Config[name] = app
+	ctx.Version = build.Version
+	ctx.BuildID = build.Build.ID
+
+	if build.Build.Attributes != nil && build.Build.Attributes.Version != nil {
+		ctx.Build = *build.Build.Attributes.Version
+	}
+
+	// The version and build of the app take precedence over any configured for it, so that the App Store
+	// version always matches the promoted build. App Store Connect only accepts versions without prereleases
+	// or build metadata, so the app's prereleaseStrategy leaves them as they are.
+	if ctx.AppVersions == nil {
+		ctx.AppVersions = make(map[string]context.AppVersion)
+	}
+
+	ctx.AppVersions[name] = context.AppVersion{Version: ctx.Version, Build: ctx.Build}
+
+	ctx.Log.WithFields(log.Fields{
+		"app":      app.BundleID,
+		"build":    fmt.Sprintf("%s (%s)", ctx.Version, ctx.Build),
+		"platform": build.Platform,
+	}).Info("promoting build")
+
+	return nil
+}
+
+// promotedVersions narrows the app's versions down to the one for the platform of the promoted build.
+func promotedVersions(name string, versions config.Version, platform config.Platform) (config.Version, error) {
+	if len(versions.Platforms) > 0 {
+		version, ok := versions.Platforms[platform]
+		if !ok {
+			return versions, ErrPromotedPlatform{App: name, Platform: platform}
+		}
+
+		versions.Platforms = config.PlatformVersions{platform: version}
+
+		return versions, nil
+	}
+
+	if versions.Platform != "" && versions.Platform != platform {
+		return versions, ErrPromotedPlatform{App: name, Platform: platform}
+	}
+
+	versions.Platform = platform
+
+	return versions, nil
+}
@@ -0,0 +1,114 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"testing"
+
+	"github.com/cidertool/cider/internal/client/clienttest"
+	"github.com/cidertool/cider/internal/pipe/semver"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoteCmd_BuildRequired(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	for _, args := range [][]string{
+		{},
+		{"--beta-group", "QA", "--build-id", "TEST"},
+	} {
+		var cmd = newPromoteCmd(&noDebug)
+
+		cmd.cmd.SetArgs(args)
+
+		err := cmd.cmd.Execute()
+		assert.Equal(t, ErrPromoteBuildRequired, err)
+	}
+}
+
+func TestChoosePromotedBuild(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Versions: config.Version{
+				Platforms: config.PlatformVersions{
+					config.PlatformiOS:   {},
+					config.PlatformMacOS: {},
+				},
+			},
+		},
+	})
+
+	err := choosePromotedBuild(ctx, &clienttest.Client{}, "TEST", promoteOpts{betaGroup: "QA"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0", ctx.Version)
+	assert.Equal(t, "TEST", ctx.BuildID)
+	assert.Equal(t, config.PlatformVersions{config.PlatformiOS: {}}, ctx.Config["TEST"].Versions.Platforms)
+	assert.Equal(t, config.PlatformVersions{config.PlatformiOS: {}}, ctx.RawConfig["TEST"].Versions.Platforms)
+}
+
+func TestChoosePromotedBuild_ConfiguredVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID:           "com.test.TEST",
+			Version:            "9.9.9-beta.1",
+			Build:              "999",
+			PrereleaseStrategy: config.PrereleaseStrategyBuild,
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+	ctx.PublishMode = context.PublishModeAppStore
+
+	err := choosePromotedBuild(ctx, &clienttest.Client{}, "TEST", promoteOpts{betaGroup: "QA"})
+	assert.NoError(t, err)
+
+	err = semver.Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+
+	version := ctx.VersionForApp("TEST")
+	assert.Equal(t, "1.0", version.Version)
+	assert.Equal(t, ctx.Build, version.Build)
+	assert.NotEqual(t, "999", version.Build)
+}
+
+func TestPromotedVersions(t *testing.T) {
+	t.Parallel()
+
+	versions, err := promotedVersions("TEST", config.Version{}, config.PlatformMacOS)
+	assert.NoError(t, err)
+	assert.Equal(t, config.PlatformMacOS, versions.Platform)
+
+	_, err = promotedVersions("TEST", config.Version{Platform: config.PlatformiOS}, config.PlatformMacOS)
+	assert.EqualError(t, err, ErrPromotedPlatform{App: "TEST", Platform: config.PlatformMacOS}.Error())
+
+	_, err = promotedVersions("TEST", config.Version{
+		Platforms: config.PlatformVersions{config.PlatformiOS: {}},
+	}, config.PlatformTvOS)
+	assert.EqualError(t, err, "app TEST isn't configured to release on tvOS, the platform of the build")
+}
@@ -21,9 +21,18 @@ along with Cider.  If not, see <http://www.gnu.org/licenses/>.
 package clicommand
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/cidertool/cider/internal/hooks"
 	"github.com/cidertool/cider/internal/log"
 	"github.com/cidertool/cider/internal/middleware"
 	"github.com/cidertool/cider/internal/pipeline"
@@ -39,6 +48,49 @@ const defaultTimeout = time.Minute * 30
 // the --set-version flag.
 var ErrSkipGitWithoutSetVersionFlag = errors.New("if --skip-git is set, --set-version must also be set")
 
+// ErrFromIPAWithSetVersionFlag indicates an error when the --from-ipa flag is set along with the --set-version
+// or --set-build flags.
+var ErrFromIPAWithSetVersionFlag = errors.New("--from-ipa can't be used with --set-version or --set-build")
+
+// ErrNextVersionWithSetVersionFlag indicates an error when the --next-version flag is set along with a flag
+// that provides the version some other way.
+var ErrNextVersionWithSetVersionFlag = errors.New("--next-version can't be used with --set-version, --from-ipa or --skip-git")
+
+// ErrTagWithoutNextVersionFlag indicates an error when the --tag or --push flags are set without also setting
+// the --next-version flag.
+var ErrTagWithoutNextVersionFlag = errors.New("--tag and --push can only be used with --next-version")
+
+// ErrWhatsNewWithWhatsNewFileFlag indicates an error when the --whats-new flag is set along with the
+// --whats-new-file flag.
+var ErrWhatsNewWithWhatsNewFileFlag = errors.New("--whats-new can't be used with --whats-new-file")
+
+// ErrMetadataOnlyWithSkipFlag indicates an error when the --metadata-only flag is set along with a flag that
+// contradicts it.
+var ErrMetadataOnlyWithSkipFlag = errors.New("--metadata-only can't be used with --mode=testflight or --skip-update-metadata")
+
+// ErrAppsFailed happens when the --keep-going flag is set and at least one app failed to release.
+type ErrAppsFailed struct {
+	Apps []string
+}
+
+func (e ErrAppsFailed) Error() string {
+	return fmt.Sprintf("failed to release %s", strings.Join(e.Apps, ", "))
+}
+
+// ErrInvalidAppOverride happens when a --set-version or --set-build flag given in the form app=value names
+// an app that isn't configured or isn't being released.
+type ErrInvalidAppOverride struct {
+	Name string
+}
+
+func (e ErrInvalidAppOverride) Error() string {
+	if e.Name == "" {
+		return "--set-version and --set-build must name an app in the form app=value"
+	}
+
+	return fmt.Sprintf("--set-version or --set-build given for %q, which isn't a configured app being released", e.Name)
+}
+
 type releaseCmd struct {
 	cmd  *cobra.Command
 	opts releaseOpts
@@ -49,16 +101,29 @@ type releaseOpts struct {
 	appsToRelease       []string
 	publishMode         context.PublishMode
 	maxProcesses        int
+	appConcurrency      int
+	appErrorMode        context.AppErrorMode
 	releaseAllApps      bool
+	keepGoing           bool
+	resume              bool
+	stateDirectory      string
 	skipGit             bool
 	skipUpdatePricing   bool
 	skipUpdateMetadata  bool
 	skipSubmit          bool
+	metadataOnly        bool
 	timeout             time.Duration
-	versionOverride     string
-	buildOverride       string
+	versionOverrides    []string
+	buildOverrides      []string
+	artifactPath        string
+	nextVersion         bool
+	tagNextVersion      bool
+	pushNextVersion     bool
 	betaGroupsOverride  []string
 	betaTestersOverride []string
+	previewBetaRemovals bool
+	whatsNew            string
+	whatsNewFile        string
 	currentDirectory    string
 }
 
@@ -99,16 +164,51 @@ More info: https://developer.apple.com/documentation/appstoreconnectapi/creating
 			if len(args) > 0 {
 				root.opts.currentDirectory = args[0]
 			}
-			if root.opts.skipGit && root.opts.versionOverride == "" {
+			if root.opts.nextVersion && (len(root.opts.versionOverrides) > 0 || root.opts.artifactPath != "" || root.opts.skipGit) {
+				return ErrNextVersionWithSetVersionFlag
+			}
+			if !root.opts.nextVersion && (root.opts.tagNextVersion || root.opts.pushNextVersion) {
+				return ErrTagWithoutNextVersionFlag
+			}
+			if root.opts.artifactPath != "" && (len(root.opts.versionOverrides) > 0 || len(root.opts.buildOverrides) > 0) {
+				return ErrFromIPAWithSetVersionFlag
+			}
+			if root.opts.skipGit && len(root.opts.versionOverrides) == 0 && root.opts.artifactPath == "" {
 				// Both of these flags are required, otherwise Cider has no safe way of determining which app version to query against.
 				return ErrSkipGitWithoutSetVersionFlag
 			}
+			if root.opts.metadataOnly && (root.opts.publishMode == context.PublishModeTestflight || root.opts.skipUpdateMetadata) {
+				return ErrMetadataOnlyWithSkipFlag
+			}
+			if !cmd.Flags().Changed("state-dir") {
+				dir, err := defaultStateDirectory(root.opts.currentDirectory)
+				if err != nil {
+					return err
+				}
+				root.opts.stateDirectory = dir
+			}
+			if root.opts.whatsNew != "" && root.opts.whatsNewFile != "" {
+				return ErrWhatsNewWithWhatsNewFileFlag
+			}
+			if root.opts.whatsNewFile != "" {
+				content, err := os.ReadFile(root.opts.whatsNewFile)
+				if err != nil {
+					return err
+				}
+				root.opts.whatsNew = string(content)
+			}
 
 			start := time.Now()
 
 			logger.Info(color.New(color.Bold).Sprint("releasing..."))
 
-			_, err := releaseProject(root.opts, logger)
+			ctx, err := releaseProject(root.opts, logger)
+			if ctx != nil && ctx.KeepGoing {
+				printSummary(ctx, logger)
+			}
+			if ctx != nil {
+				printReleasePublicLinks(ctx, logger)
+			}
 			if err != nil {
 				return wrapError(err, color.New(color.Bold).Sprintf("release failed after %0.2fs", time.Since(start).Seconds()))
 			}
@@ -160,6 +260,22 @@ option is "appstore" for submitting to the App Store.`,
 		1,
 		`Run certain metadata syncing and asset uploading logic in parallel with
 the maximum allowable concurrency.`,
+	)
+	cmd.Flags().IntVar(
+		&root.opts.appConcurrency,
+		"app-concurrency",
+		1,
+		`Process up to this many apps at the same time. Log messages are prefixed with
+the name of the app they belong to when more than one app is processed at a time.`,
+	)
+	cmd.Flags().Var(
+		&root.opts.appErrorMode,
+		"app-error-mode",
+		`Mode used to handle an app failing to release when releasing multiple apps.
+
+The default is "fail-fast", which stops releasing the remaining apps as soon as one fails.
+The other option is "aggregate", which finishes releasing the remaining apps and reports
+all errors together.`,
 	)
 	cmd.Flags().DurationVar(
 		&root.opts.timeout,
@@ -170,6 +286,40 @@ the maximum allowable concurrency.`,
 If the command takes longer than this amount of time to run, Cider will abort.`,
 	)
 
+	cmd.Flags().BoolVar(
+		&root.opts.keepGoing,
+		"keep-going",
+		false,
+		`Continue releasing the remaining apps when one of them fails.
+
+A summary of how each app's release turned out is printed at the end, and the command
+exits with an error if any app failed.`,
+	)
+
+	cmd.Flags().BoolVar(
+		&root.opts.resume,
+		"resume",
+		false,
+		`Resume a release that was interrupted, skipping the steps it already completed.
+
+Cider saves its progress to a checkpoint in the state directory as it runs. A checkpoint can
+only be resumed by a release of the same version and build, in the same mode, with the same
+configuration. Otherwise, the release starts from the beginning. When the latest build is released,
+the steps completed for an app are run again if a newer build was uploaded since.`,
+	)
+	cmd.Flags().StringVar(
+		&root.opts.stateDirectory,
+		"state-dir",
+		"",
+		`Directory to save release checkpoints and metadata snapshots in, relative to the project directory.
+The checkpoint is removed once the release succeeds. Snapshots of the metadata Cider is about to change
+are kept so they can be re-applied with `+"`cider restore`"+`. Set to an empty string to disable
+checkpoints and snapshots.
+
+Defaults to a directory for the project in your user cache directory, such as ~/.cache/cider on
+Linux or ~/Library/Caches/cider on macOS, so that state is never saved in the Git working tree.`,
+	)
+
 	// Skip options
 
 	cmd.Flags().BoolVar(
@@ -196,32 +346,89 @@ If the command takes longer than this amount of time to run, Cider will abort.`,
 		false,
 		"Skips submitting for review",
 	)
+	cmd.Flags().BoolVar(
+		&root.opts.metadataOnly,
+		"metadata-only",
+		false,
+		`Creates or updates the App Store version and syncs its metadata and assets, without
+attaching a build or submitting for review.
+
+This lets the metadata of a version be prepared before its build is uploaded. A later release
+of the same version attaches the build and submits it. Implies `+"`--mode=appstore`"+` and
+`+"`--skip-submit`"+`.`,
+	)
 
 	// Setting options
 
-	cmd.Flags().StringVarP(
-		&root.opts.versionOverride,
+	cmd.Flags().StringArrayVarP(
+		&root.opts.versionOverrides,
 		"set-version",
 		"V",
-		"",
+		[]string{},
 		`Version string override to use instead of parsing Git tags. Corresponds to the
 CFBundleShortVersionString of your build.
 
 Cider expects this string to follow the Major.Minor.Patch semantics outlined in Apple documentation
 and Semantic Versioning (semver). If this flag is omitted, Git will be leveraged to determine the
-latest tag. The tag will be used to calculate the version string under the same constraints.`,
+latest tag. The tag will be used to calculate the version string under the same constraints.
+Prereleases, such as "1.2.0-beta.3", are rejected unless the app's prereleaseStrategy maps
+them to a version App Store Connect accepts.
+
+Prefix the version with the name of an app, such as "myapp=1.2.3", to only
+apply it to that app, which must be one being released. Either form takes
+precedence over a version set for the app in the configuration. This flag can
+be provided multiple times.`,
 	)
-	cmd.Flags().StringVarP(
-		&root.opts.buildOverride,
+	cmd.Flags().StringArrayVarP(
+		&root.opts.buildOverrides,
 		"set-build",
 		"B",
-		"",
+		[]string{},
 		`Build override to use instead of "latest". Corresponds to the CFBundleVersion
 of your build.
 		
 The default behavior without this flag is to select the latest build. In both cases,
 if the selected build has an invalid processing state, Cider will abort with an error
-to ensure your release is handled safely.`,
+to ensure your release is handled safely.
+
+Prefix the build with the name of an app, such as "myapp=42", to only
+apply it to that app, which must be one being released. Either form takes
+precedence over a build set for the app in the configuration. This flag can
+be provided multiple times.`,
+	)
+	cmd.Flags().StringVar(
+		&root.opts.artifactPath,
+		"from-ipa",
+		"",
+		`Path to an .ipa, .pkg or .xcarchive to read the version and build from instead of parsing Git tags.
+
+The bundle ID of the app inside must match the app being released, and only one app can be released
+at a time. Git is not inspected when this flag is set.`,
+	)
+	cmd.Flags().BoolVar(
+		&root.opts.nextVersion,
+		"next-version",
+		false,
+		`Release the version following the latest Git tag instead of the version of the tag itself.
+
+The next version is computed from the Conventional Commits made since the tag: a "fix" commit
+increments the patch version, a "feat" commit the minor version, and a breaking change the major
+version. Apps with a tag prefix are computed from their own tags. The release fails if no commits
+warrant a new version.`,
+	)
+	cmd.Flags().BoolVar(
+		&root.opts.tagNextVersion,
+		"tag",
+		false,
+		`Create an annotated tag at the current commit for each version computed with --next-version,
+named from the tagTemplate of the project-wide settings. The tags are created once Git state is validated,
+before anything is published, so an interrupted release can be resumed without --next-version.`,
+	)
+	cmd.Flags().BoolVar(
+		&root.opts.pushNextVersion,
+		"push",
+		false,
+		"Push the tags created for --next-version to the origin remote. Implies --tag",
 	)
 	cmd.Flags().StringArrayVar(
 		&root.opts.betaGroupsOverride,
@@ -238,6 +445,27 @@ the configuration file.`,
 using the configuration file.`,
 	)
 
+	cmd.Flags().BoolVar(
+		&root.opts.previewBetaRemovals,
+		"preview-beta-removals",
+		false,
+		`Log the beta testers and beta groups that would be removed from App Store Connect by beta groups
+synced authoritatively or by deleting unmanaged beta groups, without removing them.`,
+	)
+	cmd.Flags().StringVar(
+		&root.opts.whatsNew,
+		"whats-new",
+		"",
+		`Provide the "What to Test" text of the beta build in every configured Testflight locale,
+instead of the whatsNew text from the configuration file. Templated.`,
+	)
+	cmd.Flags().StringVar(
+		&root.opts.whatsNewFile,
+		"whats-new-file",
+		"",
+		`Provide a path to a file containing the "What to Test" text of the beta build, used like --whats-new.`,
+	)
+
 	root.cmd = cmd
 
 	return root
@@ -246,7 +474,7 @@ using the configuration file.`,
 func releaseProject(options releaseOpts, logger log.Interface) (*context.Context, error) {
 	var forceAllSkips bool
 
-	cfg, err := loadConfig(options.config, options.currentDirectory)
+	cfg, settings, err := loadConfigWithSettings(options.config, options.currentDirectory)
 	if err != nil {
 		if errors.Is(err, ErrConfigNotFound) {
 			logger.Warn(err.Error())
@@ -260,10 +488,45 @@ func releaseProject(options releaseOpts, logger log.Interface) (*context.Context
 
 	ctx, cancel := context.NewWithTimeout(cfg, options.timeout)
 	defer cancel()
+	ctx.Settings = settings
 	setupReleaseContext(ctx, options, forceAllSkips, logger)
 
+	if err := validateAppOverrides(ctx); err != nil {
+		return ctx, err
+	}
+
 	return ctx, context.NewInterrupt().Run(ctx, func() error {
-		for _, pipe := range pipeline.Pipeline {
+		return runPipeline(ctx)
+	})
+}
+
+// defaultStateDirectory returns the directory release state is saved in when --state-dir isn't set, which is
+// named after the project directory inside the user cache directory so it stays out of the Git working tree.
+func defaultStateDirectory(currentDirectory string) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	project, err := filepath.Abs(currentDirectory)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(project))
+
+	return filepath.Join(cache, "cider", fmt.Sprintf("%s-%x", filepath.Base(project), sum[:6])), nil
+}
+
+// runPipeline runs the release pipeline, along with the project's plugins and hooks.
+func runPipeline(ctx *context.Context) error {
+	pipes, err := pipeline.WithPlugins(pipeline.Pipeline, ctx.Settings.Plugins)
+	if err != nil {
+		return err
+	}
+
+	return hooks.Runner{}.Around(ctx, ctx.Settings.Hooks, nil, func() error {
+		for _, pipe := range pipes {
 			if err := middleware.Logging(
 				pipe.String(),
 				middleware.ErrHandler(pipe.Run),
@@ -273,26 +536,48 @@ func releaseProject(options releaseOpts, logger log.Interface) (*context.Context
 			}
 		}
 
-		return nil
+		if err := failedApps(ctx); err != nil {
+			return err
+		}
+
+		return ctx.Checkpoint.Remove()
 	})
 }
 
 func setupReleaseContext(ctx *context.Context, options releaseOpts, forceAllSkips bool, logger log.Interface) *context.Context {
 	ctx.AppsToRelease = ctx.Config.AppsMatching(options.appsToRelease, options.releaseAllApps)
-	if options.publishMode == "" {
+	if options.metadataOnly {
+		ctx.PublishMode = context.PublishModeAppStore
+	} else if options.publishMode == "" {
 		ctx.PublishMode = context.PublishModeTestflight
 	} else {
 		ctx.PublishMode = options.publishMode
 	}
 
+	if options.appErrorMode == "" {
+		ctx.AppErrorMode = context.AppErrorModeFailFast
+	} else {
+		ctx.AppErrorMode = options.appErrorMode
+	}
+
 	ctx.Log = logger
 	ctx.MaxProcesses = options.maxProcesses
-	ctx.SkipGit = options.skipGit || forceAllSkips
+	ctx.AppConcurrency = options.appConcurrency
+	ctx.KeepGoing = options.keepGoing
+	ctx.Resume = options.resume
+	ctx.StateDirectory = options.stateDirectory
+	ctx.SkipGit = options.skipGit || options.artifactPath != "" || forceAllSkips
 	ctx.SkipUpdatePricing = options.skipUpdatePricing || forceAllSkips
 	ctx.SkipUpdateMetadata = options.skipUpdateMetadata || forceAllSkips
-	ctx.SkipSubmit = options.skipSubmit || forceAllSkips
-	ctx.Version = options.versionOverride
-	ctx.Build = options.buildOverride
+	ctx.SkipSubmit = options.skipSubmit || options.metadataOnly || forceAllSkips
+	ctx.MetadataOnly = options.metadataOnly
+	ctx.Version, ctx.Build, ctx.AppVersions = versionOverrides(options)
+	ctx.ArtifactPath = options.artifactPath
+	ctx.NextVersion = options.nextVersion
+	ctx.TagNextVersion = options.tagNextVersion
+	ctx.PushNextVersion = options.pushNextVersion
+	ctx.PreviewBetaRemovals = options.previewBetaRemovals
+	ctx.WhatsNew = strings.TrimSpace(options.whatsNew)
 
 	if !forceAllSkips && len(options.betaGroupsOverride) > 0 || len(options.betaTestersOverride) > 0 {
 		var betaGroups = make([]config.BetaGroup, len(options.betaGroupsOverride))
@@ -311,7 +596,7 @@ func setupReleaseContext(ctx *context.Context, options releaseOpts, forceAllSkip
 
 		for appName, app := range ctx.Config {
 			if len(options.betaGroupsOverride) > 0 {
-				app.Testflight.BetaGroups = betaGroups
+				app.Testflight.BetaGroups = overrideBetaGroups(app.Testflight.BetaGroups, betaGroups)
 			}
 
 			if len(betaTesters) > 0 {
@@ -326,3 +611,150 @@ func setupReleaseContext(ctx *context.Context, options releaseOpts, forceAllSkip
 
 	return ctx
 }
+
+// overrideBetaGroups returns the groups given on the command line, keeping whether the configured
+// groups of the same names are internal or silent.
+func overrideBetaGroups(configured []config.BetaGroup, overrides []config.BetaGroup) []config.BetaGroup {
+	var groups = make([]config.BetaGroup, len(overrides))
+
+	for i, group := range overrides {
+		for _, configuredGroup := range configured {
+			if configuredGroup.Name == group.Name {
+				group.Internal = configuredGroup.Internal
+				group.HasAccessToAllBuilds = configuredGroup.HasAccessToAllBuilds
+				group.Silent = configuredGroup.Silent
+
+				break
+			}
+		}
+
+		groups[i] = group
+	}
+
+	return groups
+}
+
+// versionOverrides splits the --set-version and --set-build flags into the version and build
+// shared by every app, and those given for specific apps in the form app=value.
+func versionOverrides(options releaseOpts) (version, build string, apps map[string]context.AppVersion) {
+	apps = make(map[string]context.AppVersion)
+
+	for _, value := range options.versionOverrides {
+		if name, override, ok := splitAppOverride(value); ok {
+			app := apps[name]
+			app.Version = override
+			apps[name] = app
+		} else {
+			version = value
+		}
+	}
+
+	for _, value := range options.buildOverrides {
+		if name, override, ok := splitAppOverride(value); ok {
+			app := apps[name]
+			app.Build = override
+			apps[name] = app
+		} else {
+			build = value
+		}
+	}
+
+	return version, build, apps
+}
+
+// validateAppOverrides makes sure every app given a version or build in the form app=value is one being
+// released, so a misspelled name isn't silently ignored.
+func validateAppOverrides(ctx *context.Context) error {
+	var releasing = make(map[string]bool, len(ctx.AppsToRelease))
+
+	for _, name := range ctx.AppsToRelease {
+		releasing[name] = true
+	}
+
+	var names = make([]string, 0, len(ctx.AppVersions))
+
+	for name := range ctx.AppVersions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !releasing[name] {
+			return ErrInvalidAppOverride{Name: name}
+		}
+	}
+
+	return nil
+}
+
+func splitAppOverride(value string) (name, override string, ok bool) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+func failedApps(ctx *context.Context) error {
+	var failed []string
+
+	for _, name := range ctx.AppsToRelease {
+		if ctx.Summary.Failed(name) {
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return ErrAppsFailed{Apps: failed}
+	}
+
+	return nil
+}
+
+func printSummary(ctx *context.Context, logger log.Interface) {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tSTATUS\tSKIPPED\tERROR")
+
+	for _, name := range ctx.AppsToRelease {
+		outcome, ok := ctx.Summary.Outcome(name)
+		if !ok {
+			fmt.Fprintf(w, "%s\tnot processed\t-\t-\n", name)
+
+			continue
+		}
+
+		skipped := "-"
+		if len(outcome.Skipped) > 0 {
+			skipped = strings.Join(outcome.Skipped, ", ")
+		}
+
+		errString := "-"
+		if outcome.Err != nil {
+			errString = outcome.Err.Error()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, outcome.Status, skipped, errString)
+	}
+
+	_ = w.Flush()
+
+	logger.Info(color.New(color.Bold).Sprint("summary:"))
+
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		logger.Info(line)
+	}
+}
+
+// printReleasePublicLinks logs the TestFlight public links recorded during the release, whether or not
+// it succeeded.
+func printReleasePublicLinks(ctx *context.Context, logger log.Interface) {
+	for _, link := range ctx.PublicLinks.List() {
+		logger.
+			WithFields(log.Fields{"app": link.App, "group": link.Group}).
+			Infof("public link: %s", link.URL)
+	}
+}
@@ -0,0 +1,216 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	alog "github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionOverrides(t *testing.T) {
+	t.Parallel()
+
+	version, build, apps := versionOverrides(releaseOpts{
+		versionOverrides: []string{"1.0", "watch=2.1.0", "tv=3.0"},
+		buildOverrides:   []string{"watch=42", "5"},
+	})
+	assert.Equal(t, "1.0", version)
+	assert.Equal(t, "5", build)
+	assert.Equal(t, map[string]context.AppVersion{
+		"watch": {Version: "2.1.0", Build: "42"},
+		"tv":    {Version: "3.0"},
+	}, apps)
+}
+
+func TestValidateAppOverrides(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"watch": {},
+		"tv":    {},
+	})
+	ctx.AppsToRelease = []string{"watch"}
+
+	ctx.AppVersions = map[string]context.AppVersion{"watch": {Version: "2.0"}}
+	assert.NoError(t, validateAppOverrides(ctx))
+
+	ctx.AppVersions = map[string]context.AppVersion{"wacth": {Version: "2.0"}}
+	assert.Equal(t, ErrInvalidAppOverride{Name: "wacth"}, validateAppOverrides(ctx))
+
+	ctx.AppVersions = map[string]context.AppVersion{"tv": {Build: "42"}}
+	assert.Equal(t, ErrInvalidAppOverride{Name: "tv"}, validateAppOverrides(ctx))
+
+	ctx.AppVersions = map[string]context.AppVersion{"": {Version: "2.0"}}
+	assert.EqualError(t, validateAppOverrides(ctx), "--set-version and --set-build must name an app in the form app=value")
+}
+
+func TestOverrideBetaGroups(t *testing.T) {
+	t.Parallel()
+
+	groups := overrideBetaGroups([]config.BetaGroup{
+		{Name: "Team", Internal: true, HasAccessToAllBuilds: true, Silent: true, FeedbackEnabled: true},
+	}, []config.BetaGroup{
+		{Name: "Team"},
+		{Name: "Public"},
+	})
+	assert.Equal(t, []config.BetaGroup{
+		{Name: "Team", Internal: true, HasAccessToAllBuilds: true, Silent: true},
+		{Name: "Public"},
+	}, groups)
+}
+
+func TestReleaseCmd_WhatsNewWithWhatsNewFile(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newReleaseCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"--whats-new", "TEST", "--whats-new-file", "TEST.txt"})
+
+	err := cmd.cmd.Execute()
+	assert.Equal(t, ErrWhatsNewWithWhatsNewFileFlag, err)
+}
+
+func TestReleaseCmd_MetadataOnlyWithTestflightMode(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newReleaseCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"--metadata-only", "--mode", "testflight"})
+
+	err := cmd.cmd.Execute()
+	assert.Equal(t, ErrMetadataOnlyWithSkipFlag, err)
+}
+
+func TestReleaseCmd_TagWithoutNextVersion(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newReleaseCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"--push"})
+
+	err := cmd.cmd.Execute()
+	assert.Equal(t, ErrTagWithoutNextVersionFlag, err)
+}
+
+func TestSetupReleaseContext_MetadataOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+
+	setupReleaseContext(ctx, releaseOpts{metadataOnly: true}, false, nil)
+	assert.True(t, ctx.MetadataOnly)
+	assert.True(t, ctx.SkipSubmit)
+	assert.Equal(t, context.PublishModeAppStore, ctx.PublishMode)
+}
+
+func TestDefaultStateDirectory(t *testing.T) {
+	t.Parallel()
+
+	project := t.TempDir()
+
+	dir, err := defaultStateDirectory(project)
+	assert.NoError(t, err)
+	assert.True(t, filepath.IsAbs(dir))
+	assert.False(t, strings.HasPrefix(dir, project))
+
+	other, err := defaultStateDirectory(t.TempDir())
+	assert.NoError(t, err)
+	assert.NotEqual(t, dir, other)
+}
+
+func TestFailedApps(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.AppsToRelease = []string{"a", "b", "c"}
+	assert.NoError(t, failedApps(ctx))
+
+	ctx.Summary.Record(context.AppOutcome{App: "a", Status: context.AppStatusFailed})
+	ctx.Summary.Record(context.AppOutcome{App: "b", Status: context.AppStatusSucceeded})
+	ctx.Summary.Record(context.AppOutcome{App: "c", Status: context.AppStatusFailed})
+
+	err := failedApps(ctx)
+	assert.Equal(t, ErrAppsFailed{Apps: []string{"a", "c"}}, err)
+	assert.EqualError(t, err, "failed to release a, c")
+}
+
+func TestPrintSummary(t *testing.T) {
+	t.Parallel()
+
+	handler := memory.New()
+	logger := &log.Log{Logger: alog.Logger{Handler: handler, Level: alog.InfoLevel}}
+
+	ctx := context.New(config.Project{})
+	ctx.AppsToRelease = []string{"a", "b", "c"}
+	ctx.Summary.Record(context.AppOutcome{
+		App:     "a",
+		Status:  context.AppStatusFailed,
+		Skipped: []string{"metadata"},
+		Err:     errors.New("build is still processing"),
+	})
+	ctx.Summary.Record(context.AppOutcome{App: "b", Status: context.AppStatusSucceeded})
+
+	printSummary(ctx, logger)
+
+	var lines []string
+	for _, entry := range handler.Entries[1:] {
+		lines = append(lines, entry.Message)
+	}
+
+	assert.Equal(t, []string{
+		"APP  STATUS         SKIPPED   ERROR",
+		"a    failed         metadata  build is still processing",
+		"b    succeeded      -         -",
+		"c    not processed  -         -",
+	}, lines)
+}
+
+func TestPrintReleasePublicLinks(t *testing.T) {
+	t.Parallel()
+
+	handler := memory.New()
+	logger := &log.Log{Logger: alog.Logger{Handler: handler, Level: alog.InfoLevel}}
+
+	ctx := context.New(config.Project{})
+	ctx.PublicLinks.Add("b", "Public", "https://testflight.apple.com/join/TEST")
+
+	printReleasePublicLinks(ctx, logger)
+
+	assert.Len(t, handler.Entries, 1)
+	assert.Equal(t, "public link: https://testflight.apple.com/join/TEST", handler.Entries[0].Message)
+	assert.Equal(t, "b", handler.Entries[0].Fields["app"])
+	assert.Equal(t, "Public", handler.Entries[0].Fields["group"])
+}
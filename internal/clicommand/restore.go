@@ -0,0 +1,138 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/pipe/env"
+	"github.com/cidertool/cider/internal/snapshot"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+type restoreCmd struct {
+	cmd            *cobra.Command
+	debugFlagValue *bool
+	maxProcesses   int
+	timeout        time.Duration
+}
+
+func newRestoreCmd(debugFlagValue *bool) *restoreCmd {
+	var root = &restoreCmd{debugFlagValue: debugFlagValue}
+
+	var cmd = &cobra.Command{
+		Use:   "restore <snapshot>...",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "Restore App Store Connect metadata from a snapshot",
+		Long: `Restore App Store Connect metadata from a snapshot taken during a previous release.
+
+Before Cider updates an app's metadata during a release, it saves the current values of every
+field it is about to change to a timestamped file in the ` + "`snapshots`" + ` folder of the state
+directory (see the ` + "`--state-dir`" + ` flag of ` + "`cider release`" + `). Restoring a snapshot
+re-applies those values to the same app, version and build they were read from.
+
+Snapshots cover localizations and review details, the app's categories, primary locale, content
+rights declaration, availability and price tier, and the version's copyright, release type and
+earliest release date. They don't include the start dates of scheduled price changes, or previews,
+screenshots and review attachments, so restoring a snapshot doesn't undo changes to those.
+Localizations that were added after the snapshot was taken are left untouched. The password of the demo account isn't saved in
+snapshots, so restoring keeps the password that is currently set.
+
+Cider requires the same environment variables as ` + "`cider release`" + ` to authenticate.`,
+		Example:       "cider restore ~/.cache/cider/MyApp-0123456789ab/snapshots/20200601T123000Z-com.example.App-appstore.yaml",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          root.Run,
+	}
+
+	cmd.Flags().IntVarP(
+		&root.maxProcesses,
+		"max-processes",
+		"p",
+		1,
+		"Restore localizations in parallel with the maximum allowable concurrency.",
+	)
+	cmd.Flags().DurationVar(
+		&root.timeout,
+		"timeout",
+		defaultTimeout,
+		"Timeout for the entire restore process.",
+	)
+
+	root.cmd = cmd
+
+	return root
+}
+
+func (cmd *restoreCmd) Run(c *cobra.Command, args []string) error {
+	logger := newLogger(cmd.debugFlagValue)
+
+	snapshots := make([]*snapshot.Snapshot, len(args))
+
+	for i, path := range args {
+		snap, err := snapshot.Load(path)
+		if err != nil {
+			return err
+		}
+
+		snapshots[i] = snap
+	}
+
+	ctx, cancel := context.NewWithTimeout(config.Project{}, cmd.timeout)
+	defer cancel()
+
+	ctx.Log = logger
+	ctx.MaxProcesses = cmd.maxProcesses
+
+	if err := context.NewInterrupt().Run(ctx, func() error {
+		if err := (env.Pipe{}).Run(ctx); err != nil {
+			return err
+		}
+
+		return restoreSnapshots(ctx, client.New(ctx), snapshots)
+	}); err != nil {
+		return wrapError(err, color.New(color.Bold).Sprintf("restore failed"))
+	}
+
+	logger.Info(color.New(color.Bold).Sprintf("restore succeeded"))
+
+	return nil
+}
+
+func restoreSnapshots(ctx *context.Context, c client.Client, snapshots []*snapshot.Snapshot) error {
+	for _, snap := range snapshots {
+		ctx.Log.
+			WithField("app", snap.BundleID).
+			WithField("platform", snap.Platform).
+			WithField("date", snap.Date.Format(time.RFC3339)).
+			Info(color.New(color.Bold).Sprint("restoring snapshot"))
+
+		if err := snap.Restore(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
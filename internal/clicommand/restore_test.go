@@ -0,0 +1,63 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cidertool/cider/internal/client/clienttest"
+	"github.com/cidertool/cider/internal/snapshot"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreCmd_MissingSnapshot(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newRestoreCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{filepath.Join(t.TempDir(), "missing.yaml")})
+
+	err := cmd.cmd.Execute()
+	assert.Error(t, err)
+}
+
+func TestRestoreSnapshots(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+
+	snap, err := snapshot.TakeAppStore(ctx, &clienttest.Client{}, "com.test.TEST", config.PlatformiOS, "TEST", "TEST")
+	assert.NoError(t, err)
+
+	path, err := snap.Save(t.TempDir())
+	assert.NoError(t, err)
+
+	loaded, err := snapshot.Load(path)
+	assert.NoError(t, err)
+
+	err = restoreSnapshots(ctx, &clienttest.Client{}, []*snapshot.Snapshot{loaded})
+	assert.NoError(t, err)
+}
@@ -66,6 +66,12 @@ func NewRoot(version string, exit func(int)) *Root {
 		newInitCmd(&debug).cmd,
 		newCheckCmd(&debug).cmd,
 		newReleaseCmd(&debug).cmd,
+		newPromoteCmd(&debug).cmd,
+		newRestoreCmd(&debug).cmd,
+		newVersionCmd(&debug).cmd,
+		newTestersCmd(&debug).cmd,
+		newTestflightCmd(&debug).cmd,
+		newMetadataCmd(&debug).cmd,
 		newCompletionsCmd().cmd,
 	)
 
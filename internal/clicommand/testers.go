@@ -0,0 +1,729 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/closer"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/pipe/env"
+	"github.com/cidertool/cider/internal/testers"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+// ErrAppRequired happens when a command that works on a single app is given a configuration with several
+// apps and no --app flag.
+var ErrAppRequired = errors.New("the configuration has more than one app, so one must be chosen with --app")
+
+// ErrNoPruneFilter happens when testers prune is given none of the filters that select testers.
+var ErrNoPruneFilter = errors.New("no beta testers to prune. pass --inactive-days or --not-accepted")
+
+// ErrPruneWithoutStateDirectory happens when testers prune is asked to only select testers of a minimum age
+// without a state directory to record when testers were first seen in.
+var ErrPruneWithoutStateDirectory = errors.New("--min-age requires a state directory to record when testers were first seen in. set --state-dir, or omit --min-age")
+
+// ErrPruneAborted happens when the removal of the pruned testers isn't confirmed.
+var ErrPruneAborted = errors.New("pruning beta testers aborted")
+
+// ErrNoTestersToRemove happens when testers remove is given neither emails nor a file.
+var ErrNoTestersToRemove = errors.New("no beta testers to remove. pass their emails as arguments or with --file")
+
+type testersCmd struct {
+	cmd  *cobra.Command
+	opts testersOpts
+}
+
+type testersOpts struct {
+	debugFlagValue *bool
+	config         string
+	app            string
+	maxProcesses   int
+	timeout        time.Duration
+}
+
+func newTestersCmd(debugFlagValue *bool) *testersCmd {
+	var root = &testersCmd{opts: testersOpts{debugFlagValue: debugFlagValue}}
+
+	var cmd = &cobra.Command{
+		Use:   "testers",
+		Short: "Manage the beta testers of an app",
+		Long: `Manage the beta testers of an app in bulk, using CSV files.
+
+Tester lists have the columns email, first name, last name and groups, where groups is a semicolon-separated
+list of the names of the app's beta groups. A header row is optional. Only the email is required.
+
+Cider requires the same environment variables as ` + "`cider release`" + ` to authenticate.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.PersistentFlags().StringVarP(&root.opts.config, "config", "f", "", "Load configuration from file")
+	cmd.PersistentFlags().StringVarP(
+		&root.opts.app,
+		"app",
+		"a",
+		"",
+		"Name of the app in the configuration whose testers to manage. Required if there is more than one",
+	)
+	cmd.PersistentFlags().IntVarP(
+		&root.opts.maxProcesses,
+		"max-processes",
+		"p",
+		1,
+		"Run requests in parallel with the maximum allowable concurrency.",
+	)
+	cmd.PersistentFlags().DurationVar(&root.opts.timeout, "timeout", defaultTimeout, "Timeout for the entire command.")
+
+	cmd.AddCommand(
+		newTestersListCmd(&root.opts).cmd,
+		newTestersExportCmd(&root.opts).cmd,
+		newTestersImportCmd(&root.opts).cmd,
+		newTestersRemoveCmd(&root.opts).cmd,
+		newTestersPruneCmd(&root.opts).cmd,
+	)
+
+	root.cmd = cmd
+
+	return root
+}
+
+type testersListCmd struct {
+	cmd *cobra.Command
+}
+
+func newTestersListCmd(opts *testersOpts) *testersListCmd {
+	var root = &testersListCmd{}
+
+	root.cmd = &cobra.Command{
+		Use:           "list",
+		Short:         "List the beta testers of an app",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTesters(opts, func(ctx *context.Context, c client.Client, app config.App, appID string) error {
+				remote, err := c.ListBetaTesters(ctx, appID)
+				if err != nil {
+					return err
+				}
+
+				return printTesters(cmd.OutOrStdout(), remote)
+			})
+		},
+	}
+
+	return root
+}
+
+type testersExportCmd struct {
+	cmd *cobra.Command
+}
+
+func newTestersExportCmd(opts *testersOpts) *testersExportCmd {
+	var root = &testersExportCmd{}
+
+	root.cmd = &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export the beta testers of an app to CSV",
+		Long: `Export the beta testers of an app to a CSV file that can be edited and imported again.
+
+If no file is given, the CSV is written to standard output.`,
+		Example:       "cider testers export testers.csv",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTesters(opts, func(ctx *context.Context, c client.Client, app config.App, appID string) error {
+				remote, err := c.ListBetaTesters(ctx, appID)
+				if err != nil {
+					return err
+				}
+
+				if len(args) == 0 {
+					return testers.Write(cmd.OutOrStdout(), remote)
+				}
+
+				var b strings.Builder
+				if err := testers.Write(&b, remote); err != nil {
+					return err
+				}
+
+				if err := os.WriteFile(args[0], []byte(b.String()), 0600); err != nil {
+					return err
+				}
+
+				ctx.Log.WithFields(log.Fields{
+					"path":  args[0],
+					"count": len(remote),
+				}).Info("exported beta testers")
+
+				return nil
+			})
+		},
+	}
+
+	return root
+}
+
+type testersImportCmd struct {
+	cmd    *cobra.Command
+	dryRun bool
+}
+
+func newTestersImportCmd(opts *testersOpts) *testersImportCmd {
+	var root = &testersImportCmd{}
+
+	var cmd = &cobra.Command{
+		Use:   "import <file>",
+		Short: "Add the beta testers listed in a CSV file to an app",
+		Long: `Add the beta testers listed in a CSV file to an app and its beta groups.
+
+The list is compared with the app's current testers. Testers that don't exist yet are created in their
+groups, and existing testers are added to the groups they are missing from. Testers are never removed
+from the app or from groups they aren't listed in. Use ` + "`cider testers remove`" + ` for that.
+
+New testers must be listed in at least one group, since App Store Connect can't create testers otherwise.`,
+		Example:       "cider testers import testers.csv --dry-run",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wanted, err := readTesters(args[0])
+			if err != nil {
+				return err
+			}
+
+			return runTesters(opts, func(ctx *context.Context, c client.Client, app config.App, appID string) error {
+				return importTesters(ctx, c, appID, wanted, root.dryRun)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&root.dryRun, "dry-run", false, "Print the changes without making them")
+
+	root.cmd = cmd
+
+	return root
+}
+
+type testersRemoveCmd struct {
+	cmd    *cobra.Command
+	file   string
+	groups []string
+	dryRun bool
+}
+
+func newTestersRemoveCmd(opts *testersOpts) *testersRemoveCmd {
+	var root = &testersRemoveCmd{}
+
+	var cmd = &cobra.Command{
+		Use:   "remove [email]...",
+		Short: "Remove beta testers from an app or its beta groups",
+		Long: `Remove beta testers from an app, or only from some of its beta groups with ` + "`--group`" + `.
+
+Testers can be given by email as arguments, or listed in a CSV file with ` + "`--file`" + `.`,
+		Example:       "cider testers remove someone@example.com --group Friends",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			emails := args
+
+			if root.file != "" {
+				listed, err := readTesters(root.file)
+				if err != nil {
+					return err
+				}
+
+				for _, tester := range listed {
+					emails = append(emails, tester.Email)
+				}
+			}
+
+			if len(emails) == 0 {
+				return ErrNoTestersToRemove
+			}
+
+			return runTesters(opts, func(ctx *context.Context, c client.Client, app config.App, appID string) error {
+				return removeTesters(ctx, c, appID, emails, root.groups, root.dryRun)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&root.file, "file", "", "Remove the beta testers listed in a CSV file")
+	cmd.Flags().StringSliceVar(
+		&root.groups,
+		"group",
+		[]string{},
+		"Only remove the testers from the given beta group. Can be repeated",
+	)
+	cmd.Flags().BoolVar(&root.dryRun, "dry-run", false, "Print the changes without making them")
+
+	root.cmd = cmd
+
+	return root
+}
+
+type testersPruneCmd struct {
+	cmd  *cobra.Command
+	opts testersPruneOpts
+}
+
+type testersPruneOpts struct {
+	inactiveDays   int
+	notAccepted    bool
+	minAge         int
+	stateDirectory string
+	report         string
+	yes            bool
+	dryRun         bool
+}
+
+func newTestersPruneCmd(opts *testersOpts) *testersPruneCmd {
+	var root = &testersPruneCmd{}
+
+	var cmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove inactive beta testers from an app",
+		Long: `Remove the beta testers of an app who are inactive, to free up slots in its beta groups.
+
+Testers are selected if they match any of the given filters. ` + "`--inactive-days`" + ` selects testers who had no
+sessions over the last number of days, as reported by App Store Connect's beta tester usage metrics. Usage is
+reported over 7, 30, 90 or 365 days, so the shortest of those periods that covers the given number of days
+is used. ` + "`--not-accepted`" + ` selects testers who never accepted their invitation.
+
+` + "`--min-age`" + ` doesn't select testers on its own, but keeps recently invited testers, who haven't had the
+chance to accept or use the app yet, from being selected by the other filters. App Store Connect doesn't report
+when testers were invited, so Cider records when it first sees each tester in the state directory, and with
+` + "`--min-age`" + ` only selects testers it first saw at least that many days ago. Testers are recorded every time
+the command runs, so nothing is selected until it has been run with the same state directory for at least that
+many days. ` + "`--min-age`" + ` requires a state directory, and is off by default.
+
+Testers listed in the configuration of the app, individually or in one of its beta groups, are never
+selected. The selected testers are removed from the app, and with it from all of its beta groups, after
+they are printed along with the groups they belonged to and the removal is confirmed.`,
+		Example:       "cider testers prune --inactive-days 90 --not-accepted --report pruned.csv",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if root.opts.inactiveDays <= 0 && !root.opts.notAccepted {
+				return ErrNoPruneFilter
+			}
+			if !cmd.Flags().Changed("state-dir") {
+				dir, err := defaultStateDirectory("")
+				if err != nil {
+					return err
+				}
+				root.opts.stateDirectory = dir
+			}
+			if root.opts.minAge > 0 && root.opts.stateDirectory == "" {
+				return ErrPruneWithoutStateDirectory
+			}
+
+			return runTesters(opts, func(ctx *context.Context, c client.Client, app config.App, appID string) error {
+				return pruneTesters(ctx, c, app, appID, root.opts, cmd.OutOrStdout(), confirmPrune)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&root.opts.inactiveDays, "inactive-days", 0, "Select testers who had no sessions in this many days")
+	cmd.Flags().BoolVar(&root.opts.notAccepted, "not-accepted", false, "Select testers who never accepted their invitation")
+	cmd.Flags().IntVar(
+		&root.opts.minAge,
+		"min-age",
+		0,
+		"Only select testers Cider first saw at least this many days ago, as recorded in the state directory",
+	)
+	cmd.Flags().StringVar(
+		&root.opts.stateDirectory,
+		"state-dir",
+		"",
+		"Directory to record when testers were first seen in, like with cider release. Defaults to a directory for the project in your user cache directory",
+	)
+	cmd.Flags().StringVar(&root.opts.report, "report", "", "Write the pruned testers and the groups they were removed from to a CSV file")
+	cmd.Flags().BoolVarP(&root.opts.yes, "yes", "y", false, "Remove the testers without asking for confirmation")
+	cmd.Flags().BoolVar(&root.opts.dryRun, "dry-run", false, "Print the testers that would be removed without removing them")
+
+	root.cmd = cmd
+
+	return root
+}
+
+func pruneTesters(ctx *context.Context, c client.Client, app config.App, appID string, opts testersPruneOpts, out io.Writer, confirm func(count int) error) error {
+	remote, err := c.ListBetaTesters(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	activity, err := c.ListBetaTesterActivity(ctx, appID, opts.inactiveDays)
+	if err != nil {
+		return err
+	}
+
+	filter := testers.PruneFilter{
+		InactiveDays: opts.inactiveDays,
+		NotAccepted:  opts.notAccepted,
+		Now:          ctx.Date,
+		Keep:         configuredTesters(app),
+	}
+
+	if opts.stateDirectory != "" {
+		path := filepath.Join(opts.stateDirectory, fmt.Sprintf("testers-%s.json", app.BundleID))
+
+		seen, err := testers.ReadSeen(path)
+		if err != nil {
+			return err
+		}
+
+		seen.Update(remote, ctx.Date)
+
+		if err := seen.Write(path); err != nil {
+			return err
+		}
+
+		filter.Seen = seen
+	}
+
+	selected := testers.Prune(remote, activity, filter)
+
+	if opts.minAge > 0 {
+		filter.MinAge = time.Duration(opts.minAge) * 24 * time.Hour
+
+		aged := testers.Prune(remote, activity, filter)
+		if young := len(selected) - len(aged); young > 0 {
+			ctx.Log.
+				WithField("count", young).
+				Infof("skipping beta testers first seen less than %d days ago", opts.minAge)
+		}
+
+		selected = aged
+	}
+
+	if len(selected) == 0 {
+		ctx.Log.Info("no beta testers to prune")
+
+		return nil
+	}
+
+	if err := printTesters(out, selected); err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		ctx.Log.WithField("count", len(selected)).Info("dry run, not removing beta testers")
+
+		return writeReport(ctx, opts.report, selected)
+	}
+
+	if !opts.yes {
+		if err := confirm(len(selected)); err != nil {
+			return err
+		}
+	}
+
+	var removals = make([]client.Tester, len(selected))
+
+	for i, tester := range selected {
+		removals[i] = client.Tester{ID: tester.ID, Email: tester.Email}
+	}
+
+	if err := c.RemoveBetaTesters(ctx, appID, removals); err != nil {
+		return err
+	}
+
+	ctx.Log.WithField("count", len(selected)).Info("pruned beta testers")
+
+	return writeReport(ctx, opts.report, selected)
+}
+
+// configuredTesters returns the emails of the testers listed in the configuration of an app.
+func configuredTesters(app config.App) []string {
+	var emails []string
+
+	for _, tester := range app.Testflight.BetaTesters {
+		emails = append(emails, tester.Email)
+	}
+
+	for _, group := range app.Testflight.BetaGroups {
+		for _, tester := range group.Testers {
+			emails = append(emails, tester.Email)
+		}
+	}
+
+	return emails
+}
+
+func writeReport(ctx *context.Context, path string, list []client.Tester) error {
+	if path == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	if err := testers.Write(&b, list); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return err
+	}
+
+	ctx.Log.WithField("path", path).Info("wrote prune report")
+
+	return nil
+}
+
+func confirmPrune(count int) error {
+	prompt := promptui.Prompt{
+		Label:     fmt.Sprintf("Remove these %d beta testers from the app", count),
+		IsConfirm: true,
+	}
+
+	if _, err := prompt.Run(); err != nil {
+		if errors.Is(err, promptui.ErrAbort) {
+			return ErrPruneAborted
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// runTesters loads the configuration, authenticates and looks up the chosen app before running fn.
+func runTesters(opts *testersOpts, fn func(ctx *context.Context, c client.Client, app config.App, appID string) error) error {
+	logger := newLogger(opts.debugFlagValue)
+
+	cfg, err := loadConfig(opts.config, "")
+	if err != nil {
+		return err
+	}
+
+	_, app, err := singleApp(cfg, opts.app)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.NewWithTimeout(cfg, opts.timeout)
+	defer cancel()
+
+	ctx.Log = logger
+	ctx.MaxProcesses = opts.maxProcesses
+
+	if err := withClient(ctx, func(c client.Client) error {
+		ascApp, err := c.GetAppForBundleID(ctx, app.BundleID)
+		if err != nil {
+			return err
+		}
+
+		return fn(ctx, c, app, ascApp.ID)
+	}); err != nil {
+		return wrapError(err, color.New(color.Bold).Sprintf("managing beta testers failed"))
+	}
+
+	return nil
+}
+
+// withClient authenticates with App Store Connect using the environment and runs fn with a client,
+// stopping early if the command is interrupted.
+func withClient(ctx *context.Context, fn func(c client.Client) error) error {
+	return context.NewInterrupt().Run(ctx, func() error {
+		if err := (env.Pipe{}).Run(ctx); err != nil {
+			return err
+		}
+
+		return fn(client.New(ctx))
+	})
+}
+
+// singleApp returns the name and app of the configuration with the given name, or its only app if no name is given.
+func singleApp(cfg config.Project, name string) (string, config.App, error) {
+	if name == "" {
+		if len(cfg) != 1 {
+			return "", config.App{}, ErrAppRequired
+		}
+
+		for name, app := range cfg {
+			return name, app, nil
+		}
+	}
+
+	app, ok := cfg[name]
+	if !ok {
+		return "", config.App{}, pipe.ErrMissingApp{Name: name}
+	}
+
+	return name, app, nil
+}
+
+func readTesters(path string) ([]client.Tester, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close(f)
+
+	return testers.Read(f)
+}
+
+func printTesters(w io.Writer, list []client.Tester) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "EMAIL\tNAME\tGROUPS")
+
+	for _, tester := range testers.Sorted(list) {
+		name := strings.TrimSpace(tester.FirstName + " " + tester.LastName)
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", tester.Email, name, strings.Join(tester.Groups, ", "))
+	}
+
+	return tw.Flush()
+}
+
+func importTesters(ctx *context.Context, c client.Client, appID string, wanted []client.Tester, dryRun bool) error {
+	remote, err := c.ListBetaTesters(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	var additions []client.Tester
+
+	for _, tester := range testers.Additions(remote, wanted) {
+		fields := log.Fields{"email": tester.Email, "groups": strings.Join(tester.Groups, ", ")}
+
+		switch {
+		case tester.ID != "":
+			ctx.Log.WithFields(fields).Info("adding beta tester to groups")
+		case len(tester.Groups) == 0:
+			ctx.Log.WithField("email", tester.Email).Warn("skipping new beta tester without beta groups")
+
+			continue
+		default:
+			ctx.Log.WithFields(fields).Info("creating beta tester")
+		}
+
+		additions = append(additions, tester)
+	}
+
+	if len(additions) == 0 {
+		ctx.Log.Info("beta testers are up to date")
+
+		return nil
+	}
+
+	if dryRun {
+		ctx.Log.WithField("count", len(additions)).Info("dry run, not changing beta testers")
+
+		return nil
+	}
+
+	if err := c.AddBetaTesters(ctx, appID, additions); err != nil {
+		return err
+	}
+
+	ctx.Log.WithField("count", len(additions)).Info("imported beta testers")
+
+	return nil
+}
+
+func removeTesters(ctx *context.Context, c client.Client, appID string, emails []string, groups []string, dryRun bool) error {
+	remote, err := c.ListBetaTesters(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	var existing = testers.Index(remote)
+
+	var removals []client.Tester
+
+	for _, email := range emails {
+		tester, ok := existing[strings.ToLower(email)]
+		if !ok {
+			ctx.Log.WithField("email", email).Warn("beta tester not found")
+
+			continue
+		}
+
+		if len(groups) == 0 {
+			ctx.Log.WithField("email", tester.Email).Info("removing beta tester from app")
+			removals = append(removals, client.Tester{ID: tester.ID, Email: tester.Email})
+
+			continue
+		}
+
+		var inGroups []string
+
+		for _, group := range groups {
+			for _, current := range tester.Groups {
+				if current == group {
+					inGroups = append(inGroups, group)
+
+					break
+				}
+			}
+		}
+
+		if len(inGroups) == 0 {
+			ctx.Log.WithField("email", tester.Email).Debug("beta tester isn't in the groups")
+
+			continue
+		}
+
+		ctx.Log.
+			WithFields(log.Fields{"email": tester.Email, "groups": strings.Join(inGroups, ", ")}).
+			Info("removing beta tester from groups")
+
+		removals = append(removals, client.Tester{ID: tester.ID, Email: tester.Email, Groups: inGroups})
+	}
+
+	if len(removals) == 0 {
+		ctx.Log.Info("no beta testers to remove")
+
+		return nil
+	}
+
+	if dryRun {
+		ctx.Log.WithField("count", len(removals)).Info("dry run, not changing beta testers")
+
+		return nil
+	}
+
+	if err := c.RemoveBetaTesters(ctx, appID, removals); err != nil {
+		return err
+	}
+
+	ctx.Log.WithField("count", len(removals)).Info("removed beta testers")
+
+	return nil
+}
@@ -0,0 +1,272 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/client/clienttest"
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/testers"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+type testersClient struct {
+	clienttest.Client
+	added   []client.Tester
+	removed []client.Tester
+}
+
+func (c *testersClient) AddBetaTesters(ctx *context.Context, appID string, testers []client.Tester) error {
+	c.added = append(c.added, testers...)
+
+	return nil
+}
+
+func (c *testersClient) RemoveBetaTesters(ctx *context.Context, appID string, testers []client.Tester) error {
+	c.removed = append(c.removed, testers...)
+
+	return nil
+}
+
+func TestTestersCmd_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newTestersCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"import", filepath.Join(t.TempDir(), "missing.csv")})
+
+	err := cmd.cmd.Execute()
+	assert.Error(t, err)
+}
+
+func TestTestersCmd_NothingToRemove(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newTestersCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"remove"})
+
+	err := cmd.cmd.Execute()
+	assert.EqualError(t, err, ErrNoTestersToRemove.Error())
+}
+
+func TestTestersCmd_NoPruneFilter(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newTestersCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"prune", "--yes"})
+
+	err := cmd.cmd.Execute()
+	assert.EqualError(t, err, ErrNoPruneFilter.Error())
+}
+
+func TestSingleApp(t *testing.T) {
+	t.Parallel()
+
+	one := config.Project{"a": {BundleID: "com.test.A"}}
+	two := config.Project{"a": {BundleID: "com.test.A"}, "b": {BundleID: "com.test.B"}}
+
+	name, app, err := singleApp(one, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", name)
+	assert.Equal(t, "com.test.A", app.BundleID)
+
+	name, app, err = singleApp(two, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", name)
+	assert.Equal(t, "com.test.B", app.BundleID)
+
+	_, _, err = singleApp(two, "")
+	assert.EqualError(t, err, ErrAppRequired.Error())
+
+	_, _, err = singleApp(two, "c")
+	assert.EqualError(t, err, pipe.ErrMissingApp{Name: "c"}.Error())
+}
+
+func TestPrintTesters(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+
+	err := printTesters(&b, []client.Tester{
+		{Email: "b@example.com"},
+		{Email: "a@example.com", FirstName: "Ada", LastName: "Lovelace", Groups: []string{"QA", "Friends"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `EMAIL          NAME          GROUPS
+a@example.com  Ada Lovelace  QA, Friends
+b@example.com                
+`, b.String())
+}
+
+func TestImportTesters(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	wanted := []client.Tester{
+		{Email: "TEST@example.com", Groups: []string{"TEST", "QA"}},
+		{Email: "new@example.com", Groups: []string{"QA"}},
+		{Email: "nogroups@example.com"},
+	}
+
+	c := &testersClient{}
+	err := importTesters(ctx, c, "TEST", wanted, true)
+	assert.NoError(t, err)
+	assert.Empty(t, c.added)
+
+	err = importTesters(ctx, c, "TEST", wanted, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Tester{
+		{ID: "TEST", Email: "test@example.com", FirstName: "Person", LastName: "Personson", Groups: []string{"QA"}},
+		{Email: "new@example.com", Groups: []string{"QA"}},
+	}, c.added)
+}
+
+func TestRemoveTesters(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	emails := []string{"TEST@example.com", "missing@example.com"}
+
+	c := &testersClient{}
+	err := removeTesters(ctx, c, "TEST", emails, nil, true)
+	assert.NoError(t, err)
+	assert.Empty(t, c.removed)
+
+	err = removeTesters(ctx, c, "TEST", emails, []string{"QA"}, false)
+	assert.NoError(t, err)
+	assert.Empty(t, c.removed)
+
+	err = removeTesters(ctx, c, "TEST", emails, []string{"QA", "TEST"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Tester{
+		{ID: "TEST", Email: "test@example.com", Groups: []string{"TEST"}},
+	}, c.removed)
+
+	c.removed = nil
+	err = removeTesters(ctx, c, "TEST", emails, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Tester{
+		{ID: "TEST", Email: "test@example.com"},
+	}, c.removed)
+}
+
+func TestPruneTesters(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	app := config.App{BundleID: "com.test.TEST"}
+	report := filepath.Join(t.TempDir(), "pruned.csv")
+	confirmed := func(count int) error { return nil }
+	aborted := func(count int) error { return ErrPruneAborted }
+
+	c := &testersClient{}
+
+	var out strings.Builder
+
+	err := pruneTesters(ctx, c, app, "TEST", testersPruneOpts{notAccepted: true, dryRun: true, report: report}, &out, aborted)
+	assert.NoError(t, err)
+	assert.Empty(t, c.removed)
+	assert.Contains(t, out.String(), "test@example.com")
+
+	contents, err := os.ReadFile(report)
+	assert.NoError(t, err)
+	assert.Equal(t, "email,first name,last name,groups\ntest@example.com,Person,Personson,TEST\n", string(contents))
+
+	err = pruneTesters(ctx, c, app, "TEST", testersPruneOpts{notAccepted: true}, &out, aborted)
+	assert.EqualError(t, err, ErrPruneAborted.Error())
+	assert.Empty(t, c.removed)
+
+	err = pruneTesters(ctx, c, app, "TEST", testersPruneOpts{notAccepted: true}, &out, confirmed)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Tester{{ID: "TEST", Email: "test@example.com"}}, c.removed)
+
+	c.removed = nil
+	app.Testflight.BetaGroups = []config.BetaGroup{
+		{Name: "TEST", Testers: []config.BetaTester{{Email: "test@example.com"}}},
+	}
+	err = pruneTesters(ctx, c, app, "TEST", testersPruneOpts{notAccepted: true, yes: true}, &out, aborted)
+	assert.NoError(t, err)
+	assert.Empty(t, c.removed)
+}
+
+func TestPruneTesters_MinAge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	app := config.App{BundleID: "com.test.TEST"}
+	opts := testersPruneOpts{notAccepted: true, minAge: 30, stateDirectory: t.TempDir(), yes: true}
+	path := filepath.Join(opts.stateDirectory, "testers-com.test.TEST.json")
+
+	c := &testersClient{}
+
+	var out strings.Builder
+
+	// Testers seen for the first time are too recent to prune
+	err := pruneTesters(ctx, c, app, "TEST", opts, &out, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, c.removed)
+
+	seen, err := testers.ReadSeen(path)
+	assert.NoError(t, err)
+	assert.Equal(t, testers.Seen{"TEST": ctx.Date.UTC()}, seen)
+
+	ctx.Date = ctx.Date.AddDate(0, 0, 31)
+
+	err = pruneTesters(ctx, c, app, "TEST", opts, &out, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Tester{{ID: "TEST", Email: "test@example.com"}}, c.removed)
+}
+
+func TestTestersCmd_PruneWithoutStateDirectory(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newTestersCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"prune", "--not-accepted", "--min-age", "30", "--state-dir", ""})
+
+	err := cmd.cmd.Execute()
+	assert.EqualError(t, err, ErrPruneWithoutStateDirectory.Error())
+}
+
+func TestTestersCmd_PruneMinAgeOffByDefault(t *testing.T) {
+	t.Parallel()
+
+	var cmd = newTestersPruneCmd(&testersOpts{})
+
+	assert.Equal(t, "0", cmd.cmd.Flags().Lookup("min-age").DefValue)
+}
@@ -0,0 +1,318 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// ErrInvalidOutputFormat happens when a command is asked to print its output in an unsupported format.
+type ErrInvalidOutputFormat struct {
+	Format string
+}
+
+func (e ErrInvalidOutputFormat) Error() string {
+	return fmt.Sprintf("invalid output format %s. use %s or %s", e.Format, outputFormatText, outputFormatJSON)
+}
+
+type testflightCmd struct {
+	cmd  *cobra.Command
+	opts testflightOpts
+}
+
+type testflightOpts struct {
+	debugFlagValue *bool
+	config         string
+	apps           []string
+	timeout        time.Duration
+}
+
+func newTestflightCmd(debugFlagValue *bool) *testflightCmd {
+	var root = &testflightCmd{opts: testflightOpts{debugFlagValue: debugFlagValue}}
+
+	var cmd = &cobra.Command{
+		Use:   "testflight",
+		Short: "Inspect and manage the TestFlight distribution of apps",
+		Long: `Inspect and manage the TestFlight distribution of the apps in the configuration.
+
+Cider requires the same environment variables as ` + "`cider release`" + ` to authenticate.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.PersistentFlags().StringVarP(&root.opts.config, "config", "f", "", "Load configuration from file")
+	cmd.PersistentFlags().StringArrayVarP(
+		&root.opts.apps,
+		"app",
+		"a",
+		[]string{},
+		"Name of an app in the configuration to process. Can be repeated. Defaults to every app",
+	)
+	cmd.PersistentFlags().DurationVar(&root.opts.timeout, "timeout", defaultTimeout, "Timeout for the entire command.")
+
+	cmd.AddCommand(
+		newTestflightLinksCmd(&root.opts).cmd,
+		newTestflightNotifyCmd(&root.opts).cmd,
+		newTestflightFeedbackCmd(&root.opts),
+	)
+
+	root.cmd = cmd
+
+	return root
+}
+
+type testflightLinksCmd struct {
+	cmd    *cobra.Command
+	output string
+}
+
+func newTestflightLinksCmd(opts *testflightOpts) *testflightLinksCmd {
+	var root = &testflightLinksCmd{}
+
+	var cmd = &cobra.Command{
+		Use:   "links",
+		Short: "Print the TestFlight public links of the beta groups of apps",
+		Long: `Print the current TestFlight public links of the beta groups of apps, for the groups whose public
+link is enabled in App Store Connect.`,
+		Example:       "cider testflight links --output json",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if root.output != outputFormatText && root.output != outputFormatJSON {
+				return ErrInvalidOutputFormat{Format: root.output}
+			}
+
+			return runTestflight(opts, func(ctx *context.Context, c client.Client, apps []string) error {
+				links, err := publicLinks(ctx, c, apps)
+				if err != nil {
+					return err
+				}
+
+				return printPublicLinks(cmd.OutOrStdout(), links, root.output)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&root.output, "output", "o", outputFormatText, "Output format, either text or json")
+
+	root.cmd = cmd
+
+	return root
+}
+
+type testflightNotifyCmd struct {
+	cmd     *cobra.Command
+	version string
+	build   string
+}
+
+func newTestflightNotifyCmd(opts *testflightOpts) *testflightNotifyCmd {
+	var root = &testflightNotifyCmd{}
+
+	var cmd = &cobra.Command{
+		Use:   "notify",
+		Short: "Notify the beta testers of a build that it is available",
+		Long: `Notify the beta testers who have access to a build of apps that it is available to test.
+
+This is meant for builds released without notifying testers automatically, either because
+enableAutoNotify is off or because the build was given to silent beta groups. App Store Connect
+notifies testers per build, so every tester with access to the build is notified, whichever
+group they belong to. The build is chosen the same way as by ` + "`cider release`" + `.`,
+		Example:       "cider testflight notify --app MyApp --set-version 1.2.0 --set-build 42",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTestflight(opts, func(ctx *context.Context, c client.Client, apps []string) error {
+				ctx.Version = root.version
+				ctx.Build = root.build
+
+				return notifyBetaTesters(ctx, c, apps)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&root.version, "set-version", "", "Version of the build to notify testers of")
+	cmd.Flags().StringVar(
+		&root.build,
+		"set-build",
+		"",
+		"Build number of the build to notify testers of. Defaults to the build chosen by the buildSelection of the app",
+	)
+
+	_ = cmd.MarkFlagRequired("set-version")
+
+	root.cmd = cmd
+
+	return root
+}
+
+// runTestflight loads the configuration and authenticates before running fn with the names of the
+// chosen apps, sorted.
+func runTestflight(opts *testflightOpts, fn func(ctx *context.Context, c client.Client, apps []string) error) error {
+	logger := newLogger(opts.debugFlagValue)
+
+	cfg, err := loadConfig(opts.config, "")
+	if err != nil {
+		return err
+	}
+
+	apps, err := chosenApps(cfg, opts.apps)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.NewWithTimeout(cfg, opts.timeout)
+	defer cancel()
+
+	ctx.Log = logger
+
+	if err := withClient(ctx, func(c client.Client) error {
+		return fn(ctx, c, apps)
+	}); err != nil {
+		return wrapError(err, color.New(color.Bold).Sprintf("testflight command failed"))
+	}
+
+	return nil
+}
+
+// chosenApps returns the sorted names of the given apps, or of every app in the configuration if none
+// are given.
+func chosenApps(cfg config.Project, names []string) ([]string, error) {
+	for _, name := range names {
+		if _, ok := cfg[name]; !ok {
+			return nil, pipe.ErrMissingApp{Name: name}
+		}
+	}
+
+	apps := cfg.AppsMatching(names, len(names) == 0)
+	sort.Strings(apps)
+
+	return apps, nil
+}
+
+func publicLinks(ctx *context.Context, c client.Client, apps []string) ([]context.PublicLink, error) {
+	var links []context.PublicLink
+
+	for _, name := range apps {
+		bundleID := ctx.Config[name].BundleID
+
+		app, err := c.GetAppForBundleID(ctx, bundleID)
+		if err != nil {
+			return nil, err
+		}
+
+		groupLinks, err := c.GetBetaGroupPublicLinks(ctx, app.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		groups := make([]string, 0, len(groupLinks))
+		for group := range groupLinks {
+			groups = append(groups, group)
+		}
+
+		sort.Strings(groups)
+
+		for _, group := range groups {
+			links = append(links, context.PublicLink{App: name, Group: group, URL: groupLinks[group]})
+		}
+	}
+
+	return links, nil
+}
+
+func notifyBetaTesters(ctx *context.Context, c client.Client, apps []string) error {
+	for _, name := range apps {
+		appConfig := ctx.Config[name]
+
+		app, err := c.GetAppForBundleID(ctx, appConfig.BundleID)
+		if err != nil {
+			return err
+		}
+
+		if err := pipe.ForEachPlatform(ctx, appConfig, func(ctx *context.Context, version config.Version) error {
+			var platform config.Platform
+			if pipe.IsMultiPlatform(appConfig) {
+				platform = version.Platform
+			}
+
+			build, err := c.GetBuild(ctx, app, platform, appConfig.BuildSelection)
+			if err != nil {
+				return err
+			}
+
+			ctx.Log.WithFields(log.Fields{
+				"app":   appConfig.BundleID,
+				"build": fmt.Sprintf("%s (%s)", ctx.Version, *build.Attributes.Version),
+			}).Info("notifying beta testers")
+
+			return c.NotifyBetaTesters(ctx, build.ID)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printPublicLinks(w io.Writer, links []context.PublicLink, format string) error {
+	if format == outputFormatJSON {
+		if links == nil {
+			links = []context.PublicLink{}
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(links)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "APP\tGROUP\tURL")
+
+	for _, link := range links {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", link.App, link.Group, link.URL)
+	}
+
+	return tw.Flush()
+}
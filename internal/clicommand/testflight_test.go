@@ -0,0 +1,152 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cidertool/cider/internal/client/clienttest"
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestflightCmd_InvalidOutput(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newTestflightCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"links", "--output", "yaml"})
+
+	err := cmd.cmd.Execute()
+	assert.EqualError(t, err, ErrInvalidOutputFormat{Format: "yaml"}.Error())
+}
+
+func TestChosenApps(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Project{"b": {}, "a": {}, "c": {}}
+
+	apps, err := chosenApps(cfg, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, apps)
+
+	apps, err = chosenApps(cfg, []string{"c", "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, apps)
+
+	_, err = chosenApps(cfg, []string{"d"})
+	assert.EqualError(t, err, pipe.ErrMissingApp{Name: "d"}.Error())
+}
+
+func TestPublicLinks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {BundleID: "com.test.TEST"},
+	})
+
+	links, err := publicLinks(ctx, &clienttest.Client{}, []string{"TEST"})
+	assert.NoError(t, err)
+	assert.Equal(t, []context.PublicLink{
+		{App: "TEST", Group: "TEST", URL: "https://testflight.apple.com/join/TEST"},
+	}, links)
+}
+
+type notifyRecordingClient struct {
+	clienttest.Client
+	notified []string
+}
+
+func (c *notifyRecordingClient) NotifyBetaTesters(ctx *context.Context, buildID string) error {
+	c.notified = append(c.notified, buildID)
+
+	return nil
+}
+
+func TestTestflightCmd_NotifyWithoutVersion(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newTestflightCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"notify"})
+
+	err := cmd.cmd.Execute()
+	assert.Error(t, err)
+}
+
+func TestNotifyBetaTesters(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {BundleID: "com.test.TEST"},
+		"Multi": {
+			BundleID: "com.test.Multi",
+			Versions: config.Version{
+				Platforms: config.PlatformVersions{
+					config.PlatformiOS:   {},
+					config.PlatformMacOS: {},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0"
+
+	client := &notifyRecordingClient{}
+
+	err := notifyBetaTesters(ctx, client, []string{"Multi", "TEST"})
+	assert.NoError(t, err)
+	assert.Len(t, client.notified, 3)
+}
+
+func TestPrintPublicLinks(t *testing.T) {
+	t.Parallel()
+
+	links := []context.PublicLink{
+		{App: "TEST", Group: "Public", URL: "https://testflight.apple.com/join/TEST"},
+	}
+
+	var text strings.Builder
+
+	err := printPublicLinks(&text, links, outputFormatText)
+	assert.NoError(t, err)
+	assert.Equal(t, `APP   GROUP   URL
+TEST  Public  https://testflight.apple.com/join/TEST
+`, text.String())
+
+	var json strings.Builder
+
+	err = printPublicLinks(&json, links, outputFormatJSON)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"app":"TEST","group":"Public","url":"https://testflight.apple.com/join/TEST"}]`, json.String())
+
+	json.Reset()
+
+	err = printPublicLinks(&json, nil, outputFormatJSON)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", json.String())
+}
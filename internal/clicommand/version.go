@@ -0,0 +1,223 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cidertool/cider/internal/bump"
+	"github.com/cidertool/cider/internal/git"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+type versionCmd struct {
+	cmd *cobra.Command
+}
+
+type versionNextCmd struct {
+	cmd            *cobra.Command
+	debugFlagValue *bool
+	opts           versionNextOpts
+}
+
+type versionNextOpts struct {
+	config           string
+	app              string
+	tag              bool
+	push             bool
+	remote           string
+	dryRun           bool
+	currentDirectory string
+}
+
+func newVersionCmd(debugFlagValue *bool) *versionCmd {
+	var root = &versionCmd{}
+
+	var cmd = &cobra.Command{
+		Use:           "version",
+		Short:         "Compute and tag versions of the current project",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.AddCommand(newVersionNextCmd(debugFlagValue).cmd)
+
+	root.cmd = cmd
+
+	return root
+}
+
+func newVersionNextCmd(debugFlagValue *bool) *versionNextCmd {
+	var root = &versionNextCmd{debugFlagValue: debugFlagValue}
+
+	var cmd = &cobra.Command{
+		Use:   "next [path]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Compute the next version from the commits since the latest tag",
+		Long: `Compute the next version of the project from the commits made since its latest Git tag, and print it.
+
+Commit messages are read as Conventional Commits (https://www.conventionalcommits.org). A "fix" commit
+increments the patch version, a "feat" commit increments the minor version, and a breaking change, noted
+by a "!" after the commit type or a ` + "`BREAKING CHANGE`" + ` footer, increments the major version. If no
+commits warrant a new version, nothing is printed. If the repository has no tags yet, the next version
+follows 0.0.0.
+
+With the ` + "`--tag`" + ` flag, an annotated tag is created for the next version at the current commit.
+Its name is rendered from the ` + "`tagTemplate`" + ` of the project-wide settings, and prefixed with the tag prefix
+of the app given with ` + "`--app`" + `, if any.`,
+		Example:       "cider version next --tag --push",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          root.Run,
+	}
+
+	cmd.Flags().StringVarP(&root.opts.config, "config", "f", "", "Load configuration from file")
+	cmd.Flags().StringVarP(
+		&root.opts.app,
+		"app",
+		"a",
+		"",
+		"Compute the next version of the given app from the tags matching its tag prefix",
+	)
+	cmd.Flags().BoolVar(&root.opts.tag, "tag", false, "Create an annotated tag for the next version")
+	cmd.Flags().BoolVar(&root.opts.push, "push", false, "Push the tag to the remote. Implies --tag")
+	cmd.Flags().StringVar(&root.opts.remote, "remote", "origin", "Remote to push the tag to")
+	cmd.Flags().BoolVar(
+		&root.opts.dryRun,
+		"dry-run",
+		false,
+		"Print the tag that would be created and pushed without changing the repository",
+	)
+
+	root.cmd = cmd
+
+	return root
+}
+
+func (cmd *versionNextCmd) Run(c *cobra.Command, args []string) error {
+	logger := newLogger(cmd.debugFlagValue)
+
+	if len(args) > 0 {
+		cmd.opts.currentDirectory = args[0]
+	}
+
+	cfg, settings, err := loadConfigWithSettings(cmd.opts.config, cmd.opts.currentDirectory)
+	if err != nil && !errors.Is(err, ErrConfigNotFound) {
+		return err
+	}
+
+	ctx := context.New(cfg)
+	ctx.Settings = settings
+	ctx.Log = logger
+	ctx.CurrentDirectory = cmd.opts.currentDirectory
+
+	client := git.New(ctx)
+
+	if ok := client.Exists("git"); !ok {
+		return git.ErrNoGit
+	}
+
+	if !client.IsRepo() {
+		return git.ErrNotRepository{Dir: client.CurrentDirectory()}
+	}
+
+	next, err := nextVersion(ctx, client, cmd.opts)
+	if err != nil {
+		return err
+	}
+
+	if next != "" {
+		fmt.Fprintln(c.OutOrStdout(), next)
+	}
+
+	return nil
+}
+
+// nextVersion computes the next version and tags it as requested by the options. It returns an empty string
+// if no commits warrant a new version.
+func nextVersion(ctx *context.Context, client *git.Git, opts versionNextOpts) (string, error) {
+	var prefix string
+
+	if opts.app != "" {
+		app, ok := ctx.Config[opts.app]
+		if !ok {
+			return "", pipe.ErrMissingApp{Name: opts.app}
+		}
+
+		prefix = app.TagPrefix
+	}
+
+	result, err := bump.Compute(client, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	if result.Increment == bump.None {
+		ctx.Log.Warn(bump.ErrNoChanges{Tag: result.PreviousTag}.Error())
+
+		return "", nil
+	}
+
+	ctx.Log.WithFields(log.Fields{
+		"previous":  result.Previous,
+		"next":      result.Next,
+		"increment": result.Increment.String(),
+	}).Info("computed next version")
+
+	if !opts.tag && !opts.push {
+		return result.Next, nil
+	}
+
+	tag, err := bump.TagName(ctx, prefix, result.Next)
+	if err != nil {
+		return "", err
+	}
+
+	fields := log.Fields{"tag": tag}
+	if opts.push {
+		fields["remote"] = opts.remote
+	}
+
+	if opts.dryRun {
+		ctx.Log.WithFields(fields).Info("dry run, not creating tag")
+
+		return result.Next, nil
+	}
+
+	if err := client.CreateTag(tag, fmt.Sprintf("Release %s", result.Next)); err != nil {
+		return "", err
+	}
+
+	if opts.push {
+		if err := client.PushTag(opts.remote, tag); err != nil {
+			return "", err
+		}
+	}
+
+	ctx.Log.WithFields(fields).Info("tagged next version")
+
+	return result.Next, nil
+}
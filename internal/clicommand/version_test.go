@@ -0,0 +1,178 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package clicommand
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/cidertool/cider/internal/git"
+	"github.com/cidertool/cider/internal/shell/shelltest"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionNextCmd_NotRepository(t *testing.T) {
+	t.Parallel()
+
+	var noDebug bool
+
+	var cmd = newVersionCmd(&noDebug)
+
+	cmd.cmd.SetArgs([]string{"next", t.TempDir()})
+
+	err := cmd.cmd.Execute()
+	assert.Error(t, err)
+}
+
+func TestVersionNextCmd_Output(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.name=Cider", "-c", "user.email=cider@example.com", "commit", "--quiet", "--allow-empty", "-m", "initial"},
+		{"tag", "v1.2.3"},
+		{"-c", "user.name=Cider", "-c", "user.email=cider@example.com", "commit", "--quiet", "--allow-empty", "-m", "fix: crash on launch"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	var noDebug bool
+
+	var cmd = newVersionCmd(&noDebug)
+
+	var out bytes.Buffer
+
+	cmd.cmd.SetOut(&out)
+	cmd.cmd.SetArgs([]string{"next", dir})
+
+	err := cmd.cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.4\n", out.String())
+}
+
+func TestNextVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	client := newMockGit(ctx,
+		shelltest.Command{Stdout: "v1.2.3"},
+		shelltest.Command{Stdout: "fix: crash on launch\n\x00\n"},
+	)
+
+	next, err := nextVersion(ctx, client, versionNextOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.4", next)
+}
+
+func TestNextVersion_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	client := newMockGit(ctx,
+		shelltest.Command{Stdout: "v1.2.3"},
+		shelltest.Command{Stdout: "docs: update readme\n\x00\n"},
+	)
+
+	next, err := nextVersion(ctx, client, versionNextOpts{tag: true})
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+}
+
+func TestNextVersion_TagAndPush(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", TagPrefix: "ios-app/"},
+	})
+	ctx.Settings.TagTemplate = "release-{{ .version }}"
+	client := newMockGit(ctx,
+		shelltest.Command{Stdout: "ios-app/release-1.2.3"},
+		shelltest.Command{Stdout: "feat: add widget\n\x00\n"},
+		shelltest.Command{},
+		shelltest.Command{},
+	)
+
+	next, err := nextVersion(ctx, client, versionNextOpts{app: "ios", push: true, remote: "origin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.0", next)
+}
+
+func TestNextVersion_DryRun(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	client := newMockGit(ctx,
+		shelltest.Command{Stdout: "v1.2.3"},
+		shelltest.Command{Stdout: "feat!: drop iOS 12\n\x00\n"},
+	)
+
+	next, err := nextVersion(ctx, client, versionNextOpts{push: true, dryRun: true, remote: "origin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0", next)
+}
+
+func TestNextVersion_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+
+	_, err := nextVersion(ctx, newMockGit(ctx), versionNextOpts{app: "ios"})
+	assert.EqualError(t, err, "no app defined in configuration matching the name ios")
+
+	client := newMockGit(ctx,
+		shelltest.Command{Stdout: "v1.2.3"},
+		shelltest.Command{Stdout: "fix: crash on launch\n\x00\n"},
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: tag 'v1.2.4' already exists\n"},
+	)
+
+	_, err = nextVersion(ctx, client, versionNextOpts{tag: true})
+	assert.EqualError(t, err, "fatal: tag 'v1.2.4' already exists")
+
+	client = newMockGit(ctx,
+		shelltest.Command{Stdout: "v1.2.3"},
+		shelltest.Command{Stdout: "fix: crash on launch\n\x00\n"},
+		shelltest.Command{},
+		shelltest.Command{ReturnCode: 1, Stderr: "error: failed to push some refs\n"},
+	)
+
+	_, err = nextVersion(ctx, client, versionNextOpts{push: true, remote: "origin"})
+	assert.EqualError(t, err, "error: failed to push some refs")
+}
+
+func newMockGit(ctx *context.Context, commands ...shelltest.Command) *git.Git {
+	return &git.Git{
+		Shell: &shelltest.Shell{
+			Context:  ctx,
+			Commands: commands,
+		},
+	}
+}
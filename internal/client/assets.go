@@ -103,7 +103,7 @@ func (c *ascClient) UploadRoutingCoverage(ctx *context.Context, versionID string
 		return err
 	}
 
-	return c.uploadFile(ctx, config.Path, prepare, create, commit)
+	return c.uploadFile(ctx, versionID, config.Path, prepare, create, commit)
 }
 
 //nolint:dupl // This is a false positive identified by dupl against UpdateScreenshotSets
@@ -211,7 +211,7 @@ func (c *ascClient) UploadPreviews(ctx *context.Context, g parallel.Group, previ
 		}
 
 		g.Go(func() error {
-			return c.uploadFile(ctx, previewConfig.Path, prepare, create, commit)
+			return c.uploadFile(ctx, previewSet.ID, previewConfig.Path, prepare, create, commit)
 		})
 	}
 
@@ -324,7 +324,7 @@ func (c *ascClient) UploadScreenshots(ctx *context.Context, g parallel.Group, sc
 		screenshotConfig := config[i]
 
 		g.Go(func() error {
-			return c.uploadFile(ctx, screenshotConfig.Path, prepare, create, commit)
+			return c.uploadFile(ctx, screenshotSet.ID, screenshotConfig.Path, prepare, create, commit)
 		})
 	}
 
@@ -409,7 +409,7 @@ func (c *ascClient) UploadReviewAttachments(ctx *context.Context, reviewDetailID
 		attachmentConfig := config[i]
 
 		g.Go(func() error {
-			return c.uploadFile(ctx, attachmentConfig.Path, prepare, create, commit)
+			return c.uploadFile(ctx, reviewDetailID, attachmentConfig.Path, prepare, create, commit)
 		})
 	}
 
@@ -420,7 +420,8 @@ type prepareFunc func(name string, checksum string) (shouldContinue bool, err er
 type createFunc func(name string, size int64) (id string, ops []asc.UploadOperation, err error)
 type commitFunc func(id string, checksum string) error
 
-func (c *ascClient) uploadFile(ctx *context.Context, path string, prepare prepareFunc, create createFunc, commit commitFunc) (err error) {
+// uploadFile uploads the file at path as a child of the resource with the given parent ID.
+func (c *ascClient) uploadFile(ctx *context.Context, parentID string, path string, prepare prepareFunc, create createFunc, commit commitFunc) (err error) {
 	f, err := os.Open(filepath.Clean(path))
 	if err != nil {
 		return err
@@ -433,6 +434,13 @@ func (c *ascClient) uploadFile(ctx *context.Context, path string, prepare prepar
 		return err
 	}
 
+	checkpointKey := parentID + ":" + path
+	if ctx.Checkpoint.Uploaded(checkpointKey, fstat) {
+		ctx.Log.WithField("path", path).Debug("skip file uploaded by a previous run")
+
+		return nil
+	}
+
 	checksum, err := md5Checksum(f)
 	if err != nil {
 		return err
@@ -454,7 +462,11 @@ func (c *ascClient) uploadFile(ctx *context.Context, path string, prepare prepar
 		return err
 	}
 
-	return commit(id, checksum)
+	if err = commit(id, checksum); err != nil {
+		return err
+	}
+
+	return ctx.Checkpoint.CompleteUpload(checkpointKey, fstat)
 }
 
 func md5Checksum(f io.Reader) (string, error) {
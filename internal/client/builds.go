@@ -0,0 +1,544 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cidertool/asc-go/asc"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+var errNoBetaGroupForStrategy = errors.New("the betaGroup build strategy requires a beta group name")
+
+var errNoCommitForStrategy = errors.New("the commit build strategy requires a commit, but none was found in git")
+
+type errInvalidBuildStrategy struct {
+	Strategy string
+}
+
+func (e errInvalidBuildStrategy) Error() string {
+	return fmt.Sprintf("invalid build strategy %s", e.Strategy)
+}
+
+type errBetaGroupNotFound struct {
+	Name string
+}
+
+func (e errBetaGroupNotFound) Error() string {
+	return fmt.Sprintf("beta group not found matching %s", e.Name)
+}
+
+type errExportComplianceMismatch struct {
+	BuildID                 string
+	UsesNonExemptEncryption bool
+}
+
+func (e errExportComplianceMismatch) Error() string {
+	return fmt.Sprintf("build %s already declares usesNonExemptEncryption as %t, which doesn't match the configuration", e.BuildID, e.UsesNonExemptEncryption)
+}
+
+type errEncryptionDeclarationNotFound struct {
+	Declaration string
+}
+
+func (e errEncryptionDeclarationNotFound) Error() string {
+	return fmt.Sprintf("app encryption declaration not found matching %s", e.Declaration)
+}
+
+type errEncryptionDeclarationNotApproved struct {
+	Declaration string
+	State       asc.AppEncryptionDeclarationState
+}
+
+func (e errEncryptionDeclarationNotApproved) Error() string {
+	return fmt.Sprintf("app encryption declaration %s has a state of %s and can't be assigned to a build", e.Declaration, e.State)
+}
+
+type errBuildOfOtherApp struct {
+	BuildID string
+	AppID   string
+}
+
+func (e errBuildOfOtherApp) Error() string {
+	return fmt.Sprintf("build %s doesn't belong to app %s", e.BuildID, e.AppID)
+}
+
+type errBuildNotApprovedForBeta struct {
+	Build string
+	State string
+}
+
+func (e errBuildNotApprovedForBeta) Error() string {
+	if e.State == "" {
+		return fmt.Sprintf("build %s was never submitted to Beta App Review", e.Build)
+	}
+
+	return fmt.Sprintf("build %s has a Beta App Review state of %s, not %s", e.Build, e.State, asc.BetaReviewStateApproved)
+}
+
+type errBuildNoPreReleaseVersion struct {
+	Build string
+}
+
+func (e errBuildNoPreReleaseVersion) Error() string {
+	return fmt.Sprintf("build %s has no version", e.Build)
+}
+
+// PromotableBuild is a build that passed Beta App Review, along with the version and platform it was uploaded for.
+type PromotableBuild struct {
+	Build    *asc.Build
+	Version  string
+	Platform config.Platform
+}
+
+// applyBuildStrategy narrows down the query for builds according to the selection's strategy.
+func (c *ascClient) applyBuildStrategy(ctx *context.Context, appID string, sel config.BuildSelection, query *asc.ListBuildsQuery, notFound *errBuildNotFound) error {
+	switch sel.Strategy {
+	case config.BuildStrategyLatest:
+	case config.BuildStrategyHighestBuild:
+		query.Limit = maxPageSize
+	case config.BuildStrategyNewestUpload:
+		query.Sort = []string{"-uploadedDate"}
+	case config.BuildStrategyCommit:
+		commits := buildCommits(ctx, sel)
+		if len(commits) == 0 {
+			return errNoCommitForStrategy
+		}
+
+		query.Limit = maxPageSize
+		notFound.Commit = commits[0]
+	case config.BuildStrategyBetaGroup:
+		groupID, err := c.betaGroupID(ctx, appID, sel.BetaGroup)
+		if err != nil {
+			return err
+		}
+
+		query.FilterBetaGroups = []string{groupID}
+		notFound.BetaGroup = sel.BetaGroup
+	default:
+		return errInvalidBuildStrategy{Strategy: string(sel.Strategy)}
+	}
+
+	if sel.MinimumOSVersion != "" {
+		query.Limit = maxPageSize
+	}
+
+	return nil
+}
+
+// listBuildCandidates lists the builds matching the query. Strategies that compare builds with
+// each other, and filtering by minimum OS version, need every matching build, so all pages are
+// listed for them. Otherwise only the first page is, as the first build is the one picked.
+func (c *ascClient) listBuildCandidates(ctx *context.Context, query asc.ListBuildsQuery, sel config.BuildSelection) ([]asc.Build, error) {
+	allPages := sel.Strategy == config.BuildStrategyHighestBuild ||
+		sel.Strategy == config.BuildStrategyCommit ||
+		sel.MinimumOSVersion != ""
+
+	var builds []asc.Build
+
+	for {
+		resp, _, err := c.client.Builds.ListBuilds(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+
+		builds = append(builds, resp.Data...)
+
+		if !allPages || resp.Links.Next == nil || resp.Links.Next.Cursor() == "" {
+			break
+		}
+
+		query.Cursor = resp.Links.Next.Cursor()
+	}
+
+	return builds, nil
+}
+
+// selectBuild picks a build among the candidates according to the selection's strategy, and
+// describes why it was picked. Returns a nil build if none of the candidates match.
+func (c *ascClient) selectBuild(ctx *context.Context, builds []asc.Build, sel config.BuildSelection) (*asc.Build, string, error) {
+	if len(builds) == 0 {
+		return nil, "", nil
+	}
+
+	switch sel.Strategy {
+	case config.BuildStrategyHighestBuild:
+		highest := 0
+
+		for i := range builds {
+			if compareVersions(buildNumber(builds[i]), buildNumber(builds[highest])) > 0 {
+				highest = i
+			}
+		}
+
+		return &builds[highest], fmt.Sprintf("selected the build with the highest build number of %d", len(builds)), nil
+	case config.BuildStrategyNewestUpload:
+		return &builds[0], "selected the most recently uploaded build", nil
+	case config.BuildStrategyCommit:
+		return c.selectBuildForCommit(ctx, builds, buildCommits(ctx, sel))
+	case config.BuildStrategyBetaGroup:
+		return &builds[0], fmt.Sprintf("selected the build in beta group %s", sel.BetaGroup), nil
+	}
+
+	if ctx.Build != "" {
+		return &builds[0], "selected the requested build", nil
+	}
+
+	return &builds[0], "selected the latest build", nil
+}
+
+// selectBuildForCommit returns the first build whose build number or "What to Test" notes
+// mention one of the commits.
+func (c *ascClient) selectBuildForCommit(ctx *context.Context, builds []asc.Build, commits []string) (*asc.Build, string, error) {
+	for i := range builds {
+		if containsAny(buildNumber(builds[i]), commits) {
+			return &builds[i], fmt.Sprintf("selected the build whose build number mentions commit %s", commits[0]), nil
+		}
+	}
+
+	for i := range builds {
+		resp, _, err := c.client.TestFlight.ListBetaBuildLocalizationsForBuild(ctx, builds[i].ID, nil)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, loc := range resp.Data {
+			if loc.Attributes != nil && containsAny(stringValue(loc.Attributes.WhatsNew), commits) {
+				return &builds[i], fmt.Sprintf("selected the build whose notes mention commit %s", commits[0]), nil
+			}
+		}
+	}
+
+	return nil, "", nil
+}
+
+func (c *ascClient) betaGroupID(ctx *context.Context, appID string, name string) (string, error) {
+	if name == "" {
+		return "", errNoBetaGroupForStrategy
+	}
+
+	resp, _, err := c.client.TestFlight.ListBetaGroups(ctx, &asc.ListBetaGroupsQuery{
+		FilterApp:  []string{appID},
+		FilterName: []string{name},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, group := range resp.Data {
+		if group.Attributes != nil && stringValue(group.Attributes.Name) == name {
+			return group.ID, nil
+		}
+	}
+
+	return "", errBetaGroupNotFound{Name: name}
+}
+
+// buildCommits returns the commits the commit strategy looks for, preferring the one configured.
+func buildCommits(ctx *context.Context, sel config.BuildSelection) []string {
+	if sel.Commit != "" {
+		return []string{sel.Commit}
+	}
+
+	var commits []string
+
+	for _, commit := range []string{ctx.Git.ShortCommit, ctx.Git.FullCommit} {
+		if commit != "" && commit != "none" {
+			commits = append(commits, commit)
+		}
+	}
+
+	return commits
+}
+
+// filterMinimumOSVersion returns the builds whose minimum OS version is at least the given one.
+func filterMinimumOSVersion(builds []asc.Build, minimum string) []asc.Build {
+	if minimum == "" {
+		return builds
+	}
+
+	var filtered []asc.Build
+
+	for _, build := range builds {
+		if build.Attributes == nil || build.Attributes.MinOsVersion == nil {
+			continue
+		}
+
+		if compareVersions(*build.Attributes.MinOsVersion, minimum) >= 0 {
+			filtered = append(filtered, build)
+		}
+	}
+
+	return filtered
+}
+
+func buildNumber(build asc.Build) string {
+	if build.Attributes == nil {
+		return ""
+	}
+
+	return stringValue(build.Attributes.Version)
+}
+
+// compareVersions compares dot-separated version strings such as build numbers component by
+// component, numerically where possible. Missing components count as zero.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+
+		if i < len(as) {
+			x = as[i]
+		}
+
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		if c := compareComponents(x, y); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func compareComponents(a, b string) int {
+	if a == "" {
+		a = "0"
+	}
+
+	if b == "" {
+		b = "0"
+	}
+
+	x, xerr := strconv.ParseUint(a, 10, 64)
+	y, yerr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case xerr == nil && yerr == nil && x < y:
+		return -1
+	case xerr == nil && yerr == nil && x > y:
+		return 1
+	case xerr == nil && yerr == nil:
+		return 0
+	}
+
+	return strings.Compare(a, b)
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *ascClient) UpdateBuildExportCompliance(ctx *context.Context, appID string, build *asc.Build, config config.ExportCompliance) error {
+	usesNonExemptEncryption := &config.UsesNonExemptEncryption
+
+	if build.Attributes != nil && build.Attributes.UsesNonExemptEncryption != nil {
+		if *build.Attributes.UsesNonExemptEncryption != config.UsesNonExemptEncryption {
+			return errExportComplianceMismatch{
+				BuildID:                 build.ID,
+				UsesNonExemptEncryption: *build.Attributes.UsesNonExemptEncryption,
+			}
+		}
+
+		// The build already declares its use of encryption, and App Store Connect doesn't allow changing it.
+		usesNonExemptEncryption = nil
+	}
+
+	var declarationID *string
+
+	if config.UsesNonExemptEncryption && config.EncryptionDeclaration != "" {
+		id, err := c.encryptionDeclarationID(ctx, appID, config.EncryptionDeclaration)
+		if err != nil {
+			return err
+		}
+
+		declarationID = &id
+	}
+
+	if usesNonExemptEncryption == nil && declarationID == nil {
+		return nil
+	}
+
+	_, _, err := c.client.Builds.UpdateBuild(ctx, build.ID, nil, usesNonExemptEncryption, declarationID)
+
+	return err
+}
+
+// encryptionDeclarationID returns the ID of the app's approved encryption declaration matching
+// the given ID or code.
+func (c *ascClient) encryptionDeclarationID(ctx *context.Context, appID string, declaration string) (string, error) {
+	resp, _, err := c.client.Builds.ListAppEncryptionDeclarations(ctx, &asc.ListAppEncryptionDeclarationsQuery{
+		FilterApp: []string{appID},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, decl := range resp.Data {
+		if decl.ID != declaration && (decl.Attributes == nil || decl.Attributes.CodeValue == nil || *decl.Attributes.CodeValue != declaration) {
+			continue
+		}
+
+		if decl.Attributes != nil && decl.Attributes.AppEncryptionDeclarationState != nil &&
+			*decl.Attributes.AppEncryptionDeclarationState != asc.AppEncryptionDeclarationStateApproved {
+			return "", errEncryptionDeclarationNotApproved{
+				Declaration: declaration,
+				State:       *decl.Attributes.AppEncryptionDeclarationState,
+			}
+		}
+
+		return decl.ID, nil
+	}
+
+	return "", errEncryptionDeclarationNotFound{Declaration: declaration}
+}
+
+func (c *ascClient) GetPromotableBuild(ctx *context.Context, appID string, buildID string, betaGroup string) (*PromotableBuild, error) {
+	if buildID == "" {
+		groupID, err := c.betaGroupID(ctx, appID, betaGroup)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, _, err := c.client.Builds.ListBuilds(ctx, &asc.ListBuildsQuery{
+			FilterApp:        []string{appID},
+			FilterBetaGroups: []string{groupID},
+			Sort:             []string{"-uploadedDate"},
+			Limit:            1,
+		})
+		if err != nil {
+			return nil, errBuildNotFound{AppID: appID, BetaGroup: betaGroup, InnerErr: err}
+		} else if len(resp.Data) == 0 {
+			return nil, errBuildNotFound{AppID: appID, BetaGroup: betaGroup}
+		}
+
+		buildID = resp.Data[0].ID
+	}
+
+	resp, _, err := c.client.Builds.GetBuild(ctx, buildID, &asc.GetBuildQuery{
+		Include: []string{"app", "preReleaseVersion", "betaAppReviewSubmission"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	build := resp.Data
+	if err := checkBuildProcessed(build); err != nil {
+		return nil, err
+	}
+
+	if build.Relationships != nil && build.Relationships.App != nil && build.Relationships.App.Data != nil &&
+		build.Relationships.App.Data.ID != appID {
+		return nil, errBuildOfOtherApp{BuildID: build.ID, AppID: appID}
+	}
+
+	var promotable = PromotableBuild{Build: &build}
+
+	var reviewState string
+
+	for i := range resp.Included {
+		included := resp.Included[i]
+
+		if version := included.PrereleaseVersion(); version != nil && version.Attributes != nil {
+			promotable.Version = stringValue(version.Attributes.Version)
+
+			if version.Attributes.Platform != nil {
+				promotable.Platform = platformForAPIValue(*version.Attributes.Platform)
+			}
+		}
+
+		if submission := included.BetaAppReviewSubmission(); submission != nil && submission.Attributes != nil &&
+			submission.Attributes.BetaReviewState != nil {
+			reviewState = string(*submission.Attributes.BetaReviewState)
+		}
+	}
+
+	if promotable.Version == "" {
+		return nil, errBuildNoPreReleaseVersion{Build: stringValue(build.Attributes.Version)}
+	}
+
+	if reviewState != string(asc.BetaReviewStateApproved) {
+		return nil, errBuildNotApprovedForBeta{Build: stringValue(build.Attributes.Version), State: reviewState}
+	}
+
+	return &promotable, nil
+}
+
+// getBuildByID returns the build with the given ID, after checking that it finished processing.
+func (c *ascClient) getBuildByID(ctx *context.Context, buildID string) (*asc.Build, error) {
+	resp, _, err := c.client.Builds.GetBuild(ctx, buildID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkBuildProcessed(resp.Data); err != nil {
+		return nil, err
+	}
+
+	ctx.Log.WithField("build", stringValue(resp.Data.Attributes.Version)).Info("selected the build chosen for the release")
+
+	return &resp.Data, nil
+}
+
+// checkBuildProcessed returns an error unless the build finished processing successfully.
+func checkBuildProcessed(build asc.Build) error {
+	if build.Attributes == nil {
+		return errBuildNoAttributes{build.ID}
+	}
+
+	if build.Attributes.ProcessingState == nil {
+		return errBuildNoProcessingState{build.ID}
+	}
+
+	if *build.Attributes.ProcessingState != validProcessingState {
+		return errBuildInvalidProcessingState{build.ID, build.Attributes.ProcessingState}
+	}
+
+	return nil
+}
+
+// platformForAPIValue returns the platform of the configuration corresponding to the API value.
+func platformForAPIValue(value asc.Platform) config.Platform {
+	switch value {
+	case asc.PlatformIOS:
+		return config.PlatformiOS
+	case asc.PlatformMACOS:
+		return config.PlatformMacOS
+	case asc.PlatformTVOS:
+		return config.PlatformTvOS
+	default:
+		return config.Platform(value)
+	}
+}
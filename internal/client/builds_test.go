@@ -0,0 +1,672 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cidertool/asc-go/asc"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBuild(id, version, minOSVersion string) asc.Build {
+	return asc.Build{
+		ID: id,
+		Attributes: &asc.BuildAttributes{
+			Version:         asc.String(version),
+			MinOsVersion:    asc.String(minOSVersion),
+			ProcessingState: asc.String(validProcessingState),
+		},
+	}
+}
+
+func newTestBuildApp() *asc.App {
+	return &asc.App{
+		ID: "TEST",
+		Attributes: &asc.AppAttributes{
+			BundleID: asc.String("com.app.bundleid"),
+		},
+	}
+}
+
+// Test GetBuild strategies
+
+func TestGetBuild_HighestBuild(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+				newTestBuild("2", "10", "14.0"),
+				newTestBuild("3", "10.1", "13.0"),
+				newTestBuild("4", "2", "14.0"),
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: config.BuildStrategyHighestBuild,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", build.ID)
+}
+
+func TestGetBuild_HighestBuildMinimumOSVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+				newTestBuild("2", "10", "14.0"),
+				newTestBuild("3", "10.1", "13.0"),
+				{ID: "4"},
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy:         config.BuildStrategyHighestBuild,
+		MinimumOSVersion: "14",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", build.ID)
+}
+
+func TestGetBuild_HighestBuildAcrossPages(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.BuildsResponse{
+				Data: []asc.Build{
+					newTestBuild("1", "9", "14.0"),
+					newTestBuild("2", "10", "13.0"),
+				},
+				Links: asc.PagedDocumentLinks{
+					Next: &asc.Reference{URL: url.URL{Path: "/v1/builds", RawQuery: "cursor=next"}},
+				},
+			},
+		},
+		response{
+			Response: asc.BuildsResponse{
+				Data: []asc.Build{
+					newTestBuild("3", "11", "14.0"),
+					newTestBuild("4", "12", "13.0"),
+				},
+			},
+		},
+	)
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy:         config.BuildStrategyHighestBuild,
+		MinimumOSVersion: "14",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", build.ID)
+}
+
+func TestGetBuild_LatestOnlyListsFirstPage(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+			},
+			Links: asc.PagedDocumentLinks{
+				Next: &asc.Reference{URL: url.URL{Path: "/v1/builds", RawQuery: "cursor=next"}},
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", build.ID)
+}
+
+func TestGetBuild_ErrMinimumOSVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "13.0"),
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		MinimumOSVersion: "14.0",
+	})
+	assert.EqualError(t, err, "build not found matching app=com.app.bundleid, version=1.0, minimumOSVersion=14.0")
+	assert.Nil(t, build)
+}
+
+func TestGetBuild_NewestUpload(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+				newTestBuild("2", "10", "14.0"),
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: config.BuildStrategyNewestUpload,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", build.ID)
+}
+
+func TestGetBuild_CommitInBuildNumber(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+				newTestBuild("2", "8.abcdef1", "14.0"),
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	ctx.Context.Git.ShortCommit = "abcdef1"
+	ctx.Context.Git.FullCommit = "abcdef1234567890"
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: config.BuildStrategyCommit,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", build.ID)
+}
+
+func TestGetBuild_CommitInNotes(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+				newTestBuild("2", "8", "14.0"),
+			},
+		},
+	}, response{
+		Response: asc.BetaBuildLocalizationsResponse{
+			Data: []asc.BetaBuildLocalization{
+				{Attributes: &asc.BetaBuildLocalizationAttributes{WhatsNew: asc.String("Built from 0123456")}},
+			},
+		},
+	}, response{
+		Response: asc.BetaBuildLocalizationsResponse{
+			Data: []asc.BetaBuildLocalization{
+				{},
+				{Attributes: &asc.BetaBuildLocalizationAttributes{WhatsNew: asc.String("Built from fedcba9")}},
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	ctx.Context.Git.ShortCommit = "abcdef1"
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: config.BuildStrategyCommit,
+		Commit:   "fedcba9",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", build.ID)
+}
+
+func TestGetBuild_ErrCommitNotFound(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+			},
+		},
+	}, response{
+		Response: asc.BetaBuildLocalizationsResponse{},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	ctx.Context.Git.ShortCommit = "abcdef1"
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: config.BuildStrategyCommit,
+	})
+	assert.EqualError(t, err, "build not found matching app=com.app.bundleid, version=1.0, commit=abcdef1")
+	assert.Nil(t, build)
+}
+
+func TestGetBuild_ErrCommitNotesFailed(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+			},
+		},
+	}, response{
+		StatusCode:  404,
+		RawResponse: `{}`,
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	ctx.Context.Git.ShortCommit = "abcdef1"
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: config.BuildStrategyCommit,
+	})
+	assert.Error(t, err)
+	assert.Nil(t, build)
+}
+
+func TestGetBuild_ErrNoCommit(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	ctx.Context.Git.ShortCommit = "none"
+	ctx.Context.Git.FullCommit = "none"
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: config.BuildStrategyCommit,
+	})
+	assert.Equal(t, errNoCommitForStrategy, err)
+	assert.Nil(t, build)
+}
+
+func TestGetBuild_BetaGroup(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BetaGroupsResponse{
+			Data: []asc.BetaGroup{
+				{ID: "2", Attributes: &asc.BetaGroupAttributes{Name: asc.String("QA Team")}},
+				{ID: "1", Attributes: &asc.BetaGroupAttributes{Name: asc.String("QA")}},
+			},
+		},
+	}, response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy:  config.BuildStrategyBetaGroup,
+		BetaGroup: "QA",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", build.ID)
+}
+
+func TestGetBuild_ErrBetaGroup(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BetaGroupsResponse{
+			Data: []asc.BetaGroup{
+				{ID: "2", Attributes: &asc.BetaGroupAttributes{Name: asc.String("QA Team")}},
+			},
+		},
+	}, response{
+		StatusCode:  404,
+		RawResponse: `{}`,
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: config.BuildStrategyBetaGroup,
+	})
+	assert.Equal(t, errNoBetaGroupForStrategy, err)
+	assert.Nil(t, build)
+
+	build, err = client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy:  config.BuildStrategyBetaGroup,
+		BetaGroup: "QA",
+	})
+	assert.EqualError(t, err, "beta group not found matching QA")
+	assert.Nil(t, build)
+
+	build, err = client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy:  config.BuildStrategyBetaGroup,
+		BetaGroup: "QA",
+	})
+	assert.Error(t, err)
+	assert.Nil(t, build)
+}
+
+func TestGetBuild_RequestedBuildIgnoresStrategy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildsResponse{
+			Data: []asc.Build{
+				newTestBuild("1", "9", "14.0"),
+			},
+		},
+	})
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	ctx.Context.Build = "9"
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy:  config.BuildStrategyBetaGroup,
+		BetaGroup: "QA",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", build.ID)
+}
+
+func TestGetBuild_ErrInvalidStrategy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, newTestBuildApp(), "", &config.BuildSelection{
+		Strategy: "oldest",
+	})
+	assert.EqualError(t, err, "invalid build strategy oldest")
+	assert.Nil(t, build)
+}
+
+// Test compareVersions
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, compareVersions("1", "1.0"))
+	assert.Equal(t, 0, compareVersions("", "0"))
+	assert.Equal(t, -1, compareVersions("9", "10"))
+	assert.Equal(t, 1, compareVersions("10.1", "10"))
+	assert.Equal(t, -1, compareVersions("1.2.3", "1.10"))
+	assert.Equal(t, 1, compareVersions("1.b", "1.a"))
+	assert.Equal(t, -1, compareVersions("13.0", "14"))
+}
+
+// Test UpdateBuildExportCompliance
+
+func TestUpdateBuildExportCompliance(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BuildResponse{},
+	})
+	defer ctx.Close()
+
+	build := newTestBuild("1", "10", "14.0")
+	err := client.UpdateBuildExportCompliance(ctx.Context, "TEST", &build, config.ExportCompliance{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ctx.CurrentResponseIndex)
+}
+
+func TestUpdateBuildExportCompliance_EncryptionDeclaration(t *testing.T) {
+	t.Parallel()
+
+	approved := asc.AppEncryptionDeclarationStateApproved
+
+	ctx, client := newTestContext(response{
+		Response: asc.AppEncryptionDeclarationsResponse{
+			Data: []asc.AppEncryptionDeclaration{
+				{ID: "2", Attributes: &asc.AppEncryptionDeclarationAttributes{CodeValue: asc.String("OTHER")}},
+				{ID: "3", Attributes: &asc.AppEncryptionDeclarationAttributes{
+					CodeValue:                     asc.String("7ZQ3BDHT4L"),
+					AppEncryptionDeclarationState: &approved,
+				}},
+			},
+		},
+	}, response{
+		Response: asc.BuildResponse{},
+	})
+	defer ctx.Close()
+
+	build := newTestBuild("1", "10", "14.0")
+	err := client.UpdateBuildExportCompliance(ctx.Context, "TEST", &build, config.ExportCompliance{
+		UsesNonExemptEncryption: true,
+		EncryptionDeclaration:   "7ZQ3BDHT4L",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ctx.CurrentResponseIndex)
+}
+
+func TestUpdateBuildExportCompliance_AlreadyDeclared(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+	defer ctx.Close()
+
+	build := newTestBuild("1", "10", "14.0")
+	build.Attributes.UsesNonExemptEncryption = asc.Bool(false)
+
+	err := client.UpdateBuildExportCompliance(ctx.Context, "TEST", &build, config.ExportCompliance{})
+	assert.NoError(t, err)
+
+	err = client.UpdateBuildExportCompliance(ctx.Context, "TEST", &build, config.ExportCompliance{
+		UsesNonExemptEncryption: true,
+	})
+	assert.EqualError(t, err, "build 1 already declares usesNonExemptEncryption as false, which doesn't match the configuration")
+}
+
+func TestUpdateBuildExportCompliance_ErrEncryptionDeclaration(t *testing.T) {
+	t.Parallel()
+
+	inReview := asc.AppEncryptionDeclarationStateInReview
+
+	ctx, client := newTestContext(response{
+		Response: asc.AppEncryptionDeclarationsResponse{
+			Data: []asc.AppEncryptionDeclaration{
+				{ID: "2", Attributes: &asc.AppEncryptionDeclarationAttributes{AppEncryptionDeclarationState: &inReview}},
+			},
+		},
+	}, response{
+		Response: asc.AppEncryptionDeclarationsResponse{},
+	}, response{
+		StatusCode:  404,
+		RawResponse: `{}`,
+	})
+	defer ctx.Close()
+
+	build := newTestBuild("1", "10", "14.0")
+	cfg := config.ExportCompliance{
+		UsesNonExemptEncryption: true,
+		EncryptionDeclaration:   "2",
+	}
+
+	err := client.UpdateBuildExportCompliance(ctx.Context, "TEST", &build, cfg)
+	assert.EqualError(t, err, "app encryption declaration 2 has a state of IN_REVIEW and can't be assigned to a build")
+
+	err = client.UpdateBuildExportCompliance(ctx.Context, "TEST", &build, cfg)
+	assert.EqualError(t, err, "app encryption declaration not found matching 2")
+
+	err = client.UpdateBuildExportCompliance(ctx.Context, "TEST", &build, cfg)
+	assert.Error(t, err)
+}
+
+// Test GetBuild with a build ID
+
+func TestGetBuild_BuildID(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":{"id":"b1","attributes":{"version":"42","processingState":"VALID"}}}`,
+		},
+	)
+	defer ctx.Close()
+
+	ctx.Context.BuildID = "b1"
+	build, err := client.GetBuild(ctx.Context, &asc.App{ID: testID}, config.PlatformiOS, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "b1", build.ID)
+
+	ctx.SetResponses(response{
+		RawResponse: `{"data":{"id":"b1","attributes":{"version":"42","processingState":"PROCESSING"}}}`,
+	})
+
+	_, err = client.GetBuild(ctx.Context, &asc.App{ID: testID}, config.PlatformiOS, nil)
+	assert.Error(t, err)
+}
+
+// Test GetPromotableBuild
+
+func newTestPromotableBuildResponse(appID, reviewState string) response {
+	return response{
+		RawResponse: fmt.Sprintf(`{"data":{"id":"b1","attributes":{"version":"42","processingState":"VALID"},"relationships":{"app":{"data":{"type":"apps","id":"%s"}}}},"included":[{"type":"preReleaseVersions","id":"p1","attributes":{"version":"1.2.0","platform":"MAC_OS"}},{"type":"betaAppReviewSubmissions","id":"r1","attributes":{"betaReviewState":"%s"}}]}`, appID, reviewState),
+	}
+}
+
+func TestGetPromotableBuild_BuildID(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(newTestPromotableBuildResponse(testID, "APPROVED"))
+	defer ctx.Close()
+
+	build, err := client.GetPromotableBuild(ctx.Context, testID, "b1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b1", build.Build.ID)
+	assert.Equal(t, "1.2.0", build.Version)
+	assert.Equal(t, config.PlatformMacOS, build.Platform)
+}
+
+func TestGetPromotableBuild_BetaGroup(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"g1","attributes":{"name":"QA"}}]}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"b1"}]}`,
+		},
+		newTestPromotableBuildResponse(testID, "APPROVED"),
+	)
+	defer ctx.Close()
+
+	build, err := client.GetPromotableBuild(ctx.Context, testID, "", "QA")
+	assert.NoError(t, err)
+	assert.Equal(t, "b1", build.Build.ID)
+	assert.Equal(t, 3, ctx.CurrentResponseIndex)
+}
+
+func TestGetPromotableBuild_ErrBetaGroupEmpty(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"g1","attributes":{"name":"QA"}}]}`,
+		},
+		response{
+			RawResponse: `{"data":[]}`,
+		},
+	)
+	defer ctx.Close()
+
+	_, err := client.GetPromotableBuild(ctx.Context, testID, "", "QA")
+	assert.EqualError(t, err, errBuildNotFound{AppID: testID, BetaGroup: "QA"}.Error())
+
+	ctx.SetResponses(response{
+		RawResponse: `{"data":[]}`,
+	})
+
+	_, err = client.GetPromotableBuild(ctx.Context, testID, "", "QA")
+	assert.EqualError(t, err, errBetaGroupNotFound{Name: "QA"}.Error())
+}
+
+func TestGetPromotableBuild_ErrNotApproved(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(newTestPromotableBuildResponse(testID, "IN_REVIEW"))
+	defer ctx.Close()
+
+	_, err := client.GetPromotableBuild(ctx.Context, testID, "b1", "")
+	assert.EqualError(t, err, "build 42 has a Beta App Review state of IN_REVIEW, not APPROVED")
+
+	ctx.SetResponses(response{
+		RawResponse: `{"data":{"id":"b1","attributes":{"version":"42","processingState":"VALID"}},"included":[{"type":"preReleaseVersions","id":"p1","attributes":{"version":"1.2.0","platform":"IOS"}}]}`,
+	})
+
+	_, err = client.GetPromotableBuild(ctx.Context, testID, "b1", "")
+	assert.EqualError(t, err, "build 42 was never submitted to Beta App Review")
+}
+
+func TestGetPromotableBuild_ErrOtherApp(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(newTestPromotableBuildResponse("OTHER", "APPROVED"))
+	defer ctx.Close()
+
+	_, err := client.GetPromotableBuild(ctx.Context, testID, "b1", "")
+	assert.EqualError(t, err, errBuildOfOtherApp{BuildID: "b1", AppID: testID}.Error())
+}
+
+func TestGetPromotableBuild_ErrInvalid(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":{"id":"b1","attributes":{"version":"42","processingState":"INVALID"}}}`,
+		},
+	)
+	defer ctx.Close()
+
+	_, err := client.GetPromotableBuild(ctx.Context, testID, "b1", "")
+	assert.Error(t, err)
+
+	ctx.SetResponses(response{
+		StatusCode:  http.StatusNotFound,
+		RawResponse: `{}`,
+	})
+
+	_, err = client.GetPromotableBuild(ctx.Context, testID, "b1", "")
+	assert.Error(t, err)
+}
@@ -25,8 +25,10 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cidertool/asc-go/asc"
+	"github.com/cidertool/cider/internal/log"
 	"github.com/cidertool/cider/pkg/config"
 	"github.com/cidertool/cider/pkg/context"
 )
@@ -54,48 +56,53 @@ func (e errNoAppInfoFound) Error() string {
 }
 
 type errBuildNotFound struct {
-	AppID         string
-	BuildVersion  string
-	VersionString string
-	InnerErr      error
+	AppID            string
+	BuildVersion     string
+	VersionString    string
+	Platform         config.Platform
+	BetaGroup        string
+	Commit           string
+	MinimumOSVersion string
+	InnerErr         error
 }
 
 func (e errBuildNotFound) Error() string {
-	str := strings.Builder{}
-	str.WriteString("build not found")
-
-	listingFields := false
+	var fields []string
 
 	if e.AppID != "" {
-		if !listingFields {
-			str.WriteString(" matching ")
-		}
+		fields = append(fields, fmt.Sprintf("app=%s", e.AppID))
+	}
 
-		str.WriteString(fmt.Sprintf("app=%s", e.AppID))
+	if e.VersionString != "" {
+		fields = append(fields, fmt.Sprintf("version=%s", e.VersionString))
+	}
 
-		listingFields = true
+	if e.BuildVersion != "" {
+		fields = append(fields, fmt.Sprintf("build=%s", e.BuildVersion))
 	}
 
-	if e.VersionString != "" {
-		if listingFields {
-			str.WriteString(", ")
-		} else {
-			str.WriteString(" matching ")
-		}
+	if e.Platform != "" {
+		fields = append(fields, fmt.Sprintf("platform=%s", e.Platform))
+	}
 
-		str.WriteString(fmt.Sprintf("version=%s", e.VersionString))
+	if e.BetaGroup != "" {
+		fields = append(fields, fmt.Sprintf("betaGroup=%s", e.BetaGroup))
+	}
 
-		listingFields = true
+	if e.Commit != "" {
+		fields = append(fields, fmt.Sprintf("commit=%s", e.Commit))
 	}
 
-	if e.BuildVersion != "" {
-		if listingFields {
-			str.WriteString(", ")
-		} else {
-			str.WriteString(" matching ")
-		}
+	if e.MinimumOSVersion != "" {
+		fields = append(fields, fmt.Sprintf("minimumOSVersion=%s", e.MinimumOSVersion))
+	}
 
-		str.WriteString(fmt.Sprintf("build=%s", e.BuildVersion))
+	str := strings.Builder{}
+	str.WriteString("build not found")
+
+	if len(fields) > 0 {
+		str.WriteString(" matching ")
+		str.WriteString(strings.Join(fields, ", "))
 	}
 
 	if e.InnerErr != nil {
@@ -135,42 +142,105 @@ type Client interface {
 	// GetAppForBundleID returns the App resource matching the given bundle ID
 	GetAppForBundleID(ctx *context.Context, bundleID string) (*asc.App, error)
 	GetAppInfo(ctx *context.Context, appID string) (*asc.AppInfo, error)
-	// GetBuild returns the Build resource for the given app, depending on the value set for
-	// ctx.Build. Returns an error if the selected build is still processing.
-	GetBuild(ctx *context.Context, app *asc.App) (*asc.Build, error)
-	// ReleaseForAppIsInitial returns true if the App resource has never released before,
-	// i.e. has one or less associated App Store Version relationships.
-	ReleaseForAppIsInitial(ctx *context.Context, appID string) (bool, error)
+	// GetBuild returns the Build resource for the given app and platform, depending on the value set for
+	// ctx.Build and the given build selection, which may be nil. Builds for every platform are considered
+	// if platform is empty. Returns an error if the selected build is still processing.
+	GetBuild(ctx *context.Context, app *asc.App, platform config.Platform, selection *config.BuildSelection) (*asc.Build, error)
+	// ReleaseForAppIsInitial returns true if the App resource has never released before on the given platform,
+	// i.e. has one or less associated App Store Version relationships. Every platform is considered if platform
+	// is empty.
+	ReleaseForAppIsInitial(ctx *context.Context, appID string, platform config.Platform) (bool, error)
+	// UpdateBuildExportCompliance declares whether the build uses non-exempt encryption, and assigns it the app's
+	// encryption declaration matching the configuration if there is one. A build that already declares its use of
+	// encryption is left as is, unless the declaration doesn't match the configuration.
+	UpdateBuildExportCompliance(ctx *context.Context, appID string, build *asc.Build, config config.ExportCompliance) error
 
 	// Testflight
 
 	// UpdateBetaAppLocalizations updates an App's beta app localizations, and creates any new ones that do not exist.
 	// It will not delete or update any locales that are associated with the app but are not configured in cider.
 	UpdateBetaAppLocalizations(ctx *context.Context, appID string, config config.TestflightLocalizations) error
+	// GetBetaAppLocalizations returns an App's current beta app localizations, keyed by locale.
+	GetBetaAppLocalizations(ctx *context.Context, appID string) (config.TestflightLocalizations, error)
 	// UpdateBetaBuildDetails updates an App's beta build details, or creates new ones if they do not yet exist.
 	UpdateBetaBuildDetails(ctx *context.Context, buildID string, config config.Testflight) error
 	// UpdateBetaBuildLocalizations updates an App's beta build localizations, and creates any new ones that do not exist.
 	// It will not delete or update any locales that are associated with the app but are not configured in cider.
 	UpdateBetaBuildLocalizations(ctx *context.Context, buildID string, config config.TestflightLocalizations) error
+	// GetBetaBuildLocalizations returns a build's current beta build localizations, keyed by locale.
+	// Only the WhatsNew field is populated.
+	GetBetaBuildLocalizations(ctx *context.Context, buildID string) (config.TestflightLocalizations, error)
 	// UpdateBetaLicenseAgreement updates an App's beta license agreement, or creates a new one if one does not yet exist.
 	UpdateBetaLicenseAgreement(ctx *context.Context, appID string, config config.Testflight) error
 	AssignBetaGroups(ctx *context.Context, appID string, buildID string, groups []config.BetaGroup) error
 	AssignBetaTesters(ctx *context.Context, appID string, buildID string, testers []config.BetaTester) error
+	// SyncBetaGroups removes the testers that aren't listed in the configuration from the app's authoritatively
+	// synced beta groups, and deletes the app's beta groups that aren't in the configuration if configured to.
+	// Every removal is logged before any happens. Nothing is removed if there are more removals than the
+	// configuration allows, or if ctx.PreviewBetaRemovals is set.
+	SyncBetaGroups(ctx *context.Context, appID string, config config.Testflight) error
+	// GetBetaGroupPublicLinks returns a map of the names of an App's beta groups to their TestFlight public links,
+	// for the groups whose public link is enabled.
+	GetBetaGroupPublicLinks(ctx *context.Context, appID string) (map[string]string, error)
+	// ListBetaTesters returns every beta tester of an App, with the names of the App's beta groups they belong to.
+	ListBetaTesters(ctx *context.Context, appID string) ([]Tester, error)
+	// ListBetaTesterActivity returns the state of every beta tester of an App by tester ID, along with how many
+	// sessions they had over the shortest period reported by App Store Connect that covers the given number
+	// of days. Sessions aren't counted if days is 0.
+	ListBetaTesterActivity(ctx *context.Context, appID string, days int) (map[string]TesterActivity, error)
+	// AddBetaTesters creates the given testers that have no ID in the given beta groups of an App, and adds the
+	// testers that have one to the given beta groups.
+	AddBetaTesters(ctx *context.Context, appID string, testers []Tester) error
+	// RemoveBetaTesters removes the given testers from the given beta groups of an App, or from the App
+	// entirely if no groups are given.
+	RemoveBetaTesters(ctx *context.Context, appID string, testers []Tester) error
+	// ListBetaFeedback returns the screenshot and crash feedback beta testers of an App submitted after the
+	// given time, oldest first. Every submission is returned if the time is zero.
+	ListBetaFeedback(ctx *context.Context, appID string, since time.Time) ([]BetaFeedback, error)
 	// UpdateBetaReviewDetails updates an App's beta review details, or creates new ones if they do not yet exist.
 	UpdateBetaReviewDetails(ctx *context.Context, appID string, config config.ReviewDetails) error
+	// GetBetaReviewDetails returns an App's current beta review details.
+	GetBetaReviewDetails(ctx *context.Context, appID string) (*config.ReviewDetails, error)
 	// SubmitBetaApp submits the given beta build for review
 	SubmitBetaApp(ctx *context.Context, buildID string) error
+	// GetPromotableBuild returns the build of an App with the given ID, or the most recently uploaded build of the
+	// named beta group if no ID is given, after checking that it is valid and passed Beta App Review.
+	GetPromotableBuild(ctx *context.Context, appID string, buildID string, betaGroup string) (*PromotableBuild, error)
+	// NotifyBetaTesters notifies the testers who have access to the given beta build that it is available.
+	NotifyBetaTesters(ctx *context.Context, buildID string) error
 
 	// App Store
 
 	UpdateApp(ctx *context.Context, appID string, appInfoID string, versionID string, config config.App) error
 	UpdateAppLocalizations(ctx *context.Context, appID string, config config.AppLocalizations) error
+	// GetAppLocalizations returns the localizations of an App's app info that is being prepared for submission,
+	// keyed by locale.
+	GetAppLocalizations(ctx *context.Context, appID string) (config.AppLocalizations, error)
+	// GetAppDetails returns an App's current primary locale, content rights declaration, availability, price tiers
+	// and the categories of the given app info, in the form UpdateApp takes them. The start dates of the price
+	// tiers are not included.
+	GetAppDetails(ctx *context.Context, appID string, appInfoID string) (*config.App, error)
+	// CreateVersionIfNeeded creates the App Store version for the current version if it doesn't exist, or updates it
+	// otherwise, and attaches the given build to it. The build is left as it is if buildID is empty.
 	CreateVersionIfNeeded(ctx *context.Context, appID string, buildID string, config config.Version) (*asc.AppStoreVersion, error)
+	// UpdateLivePromotionalText updates the promotional text of an App's version that is ready for sale on the given
+	// platform, for each locale in the map. Locales can't be added to a version that is ready for sale.
+	UpdateLivePromotionalText(ctx *context.Context, appID string, platform config.Platform, texts map[string]string) error
 	UpdateVersionLocalizations(ctx *context.Context, versionID string, config config.VersionLocalizations) error
+	// GetVersionLocalizations returns a version's current localizations, keyed by locale. Previews and
+	// screenshots are not included.
+	GetVersionLocalizations(ctx *context.Context, versionID string) (config.VersionLocalizations, error)
+	// GetVersionDetails returns a version's current copyright, release type and earliest release date.
+	GetVersionDetails(ctx *context.Context, versionID string) (*config.Version, error)
+	// UpdateVersionDetails updates a version's copyright, release type and earliest release date.
+	UpdateVersionDetails(ctx *context.Context, versionID string, config config.Version) error
 	UpdateIDFADeclaration(ctx *context.Context, versionID string, config config.IDFADeclaration) error
 	UploadRoutingCoverage(ctx *context.Context, versionID string, config config.File) error
 	// UpdateReviewDetails updates an App's review details, or creates new ones if they do not yet exist.
 	UpdateReviewDetails(ctx *context.Context, versionID string, config config.ReviewDetails) error
+	// GetReviewDetails returns a version's current review details, or nil if none have been created yet.
+	// Attachments are not included.
+	GetReviewDetails(ctx *context.Context, versionID string) (*config.ReviewDetails, error)
 	EnablePhasedRelease(ctx *context.Context, versionID string) error
 	// SubmitApp submits the given app store version for review
 	SubmitApp(ctx *context.Context, versionID string) error
@@ -224,11 +294,24 @@ func (c *ascClient) GetAppInfo(ctx *context.Context, appID string) (*asc.AppInfo
 	return nil, errNoAppInfoFound{AppID: appID}
 }
 
-func (c *ascClient) GetBuild(ctx *context.Context, app *asc.App) (*asc.Build, error) {
+func (c *ascClient) GetBuild(ctx *context.Context, app *asc.App, platform config.Platform, selection *config.BuildSelection) (*asc.Build, error) {
+	if ctx.BuildID != "" {
+		return c.getBuildByID(ctx, ctx.BuildID)
+	}
+
 	if ctx.Version == "" {
 		return nil, errNoVersionProvided
 	}
 
+	var sel config.BuildSelection
+	if selection != nil {
+		sel = *selection
+	}
+
+	if sel.Strategy == "" || ctx.Build != "" {
+		sel.Strategy = config.BuildStrategyLatest
+	}
+
 	query := asc.ListBuildsQuery{
 		FilterApp:                      []string{app.ID},
 		FilterPreReleaseVersionVersion: []string{ctx.Version},
@@ -238,38 +321,113 @@ func (c *ascClient) GetBuild(ctx *context.Context, app *asc.App) (*asc.Build, er
 		query.FilterVersion = []string{ctx.Build}
 	}
 
-	resp, _, err := c.client.Builds.ListBuilds(ctx, &query)
-	if err != nil || len(resp.Data) == 0 {
-		return nil, errBuildNotFound{
-			AppID:         *app.Attributes.BundleID,
-			VersionString: ctx.Version,
-			BuildVersion:  ctx.Build,
-			InnerErr:      err,
+	if platform != "" {
+		value := platform.APIValue()
+		if value == nil {
+			return nil, errPlatformNotFound{Platform: platform}
 		}
+
+		query.FilterPreReleaseVersionPlatform = []string{string(*value)}
+	}
+
+	notFound := errBuildNotFound{
+		AppID:            *app.Attributes.BundleID,
+		VersionString:    ctx.Version,
+		BuildVersion:     ctx.Build,
+		Platform:         platform,
+		MinimumOSVersion: sel.MinimumOSVersion,
+	}
+
+	if err := c.applyBuildStrategy(ctx, app.ID, sel, &query, &notFound); err != nil {
+		return nil, err
 	}
 
-	build := resp.Data[0]
+	builds, err := c.listBuildCandidates(ctx, query, sel)
+	if err != nil {
+		notFound.InnerErr = err
 
-	if build.Attributes == nil {
-		return nil, errBuildNoAttributes{build.ID}
+		return nil, notFound
 	}
 
-	if build.Attributes.ProcessingState == nil {
-		return nil, errBuildNoProcessingState{build.ID}
+	build, reason, err := c.selectBuild(ctx, filterMinimumOSVersion(builds, sel.MinimumOSVersion), sel)
+	if err != nil {
+		return nil, err
+	} else if build == nil {
+		return nil, notFound
 	}
 
-	if *build.Attributes.ProcessingState != validProcessingState {
-		return nil, errBuildInvalidProcessingState{build.ID, build.Attributes.ProcessingState}
+	if err := checkBuildProcessed(*build); err != nil {
+		return nil, err
 	}
 
-	return &build, nil
+	ctx.Log.WithFields(log.Fields{
+		"build":    stringValue(build.Attributes.Version),
+		"strategy": sel.Strategy,
+	}).Info(reason)
+
+	return build, nil
 }
 
-func (c *ascClient) ReleaseForAppIsInitial(ctx *context.Context, appID string) (bool, error) {
-	resp, _, err := c.client.Apps.ListAppStoreVersionsForApp(ctx, appID, nil)
+func (c *ascClient) ReleaseForAppIsInitial(ctx *context.Context, appID string, platform config.Platform) (bool, error) {
+	var query *asc.ListAppStoreVersionsQuery
+
+	if platform != "" {
+		value := platform.APIValue()
+		if value == nil {
+			return false, errPlatformNotFound{Platform: platform}
+		}
+
+		query = &asc.ListAppStoreVersionsQuery{FilterPlatform: []string{string(*value)}}
+	}
+
+	resp, _, err := c.client.Apps.ListAppStoreVersionsForApp(ctx, appID, query)
 	if err != nil {
 		return false, err
 	}
 
 	return len(resp.Data) <= 1, nil
 }
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func boolValue(b *bool) bool {
+	if b == nil {
+		return false
+	}
+
+	return *b
+}
+
+func reviewDetails(email, firstName, lastName, phone, demoName, demoPassword *string, demoRequired *bool, notes *string) *config.ReviewDetails {
+	details := config.ReviewDetails{
+		Notes: stringValue(notes),
+	}
+
+	if email != nil || firstName != nil || lastName != nil || phone != nil {
+		details.Contact = &config.ContactPerson{
+			Email:     stringValue(email),
+			FirstName: stringValue(firstName),
+			LastName:  stringValue(lastName),
+			Phone:     stringValue(phone),
+		}
+	}
+
+	if demoName != nil || demoPassword != nil || demoRequired != nil {
+		details.DemoAccount = &config.DemoAccount{
+			Name:     stringValue(demoName),
+			Password: stringValue(demoPassword),
+		}
+
+		if demoRequired != nil {
+			details.DemoAccount.Required = *demoRequired
+		}
+	}
+
+	return &details
+}
@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/cidertool/asc-go/asc"
+	"github.com/cidertool/cider/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -174,7 +175,7 @@ func TestGetBuild_Happy(t *testing.T) {
 	defer ctx.Close()
 
 	ctx.Context.Version = testGetBuildVersion
-	build, err := client.GetBuild(ctx.Context, &app)
+	build, err := client.GetBuild(ctx.Context, &app, "", nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 	assert.Equal(t, expectedProcessingState, *build.Attributes.ProcessingState)
@@ -205,7 +206,7 @@ func TestGetBuild_HappyOverrideBuild(t *testing.T) {
 
 	ctx.Context.Version = testGetBuildVersion
 	ctx.Context.Build = "3"
-	build, err := client.GetBuild(ctx.Context, &app)
+	build, err := client.GetBuild(ctx.Context, &app, config.PlatformMacOS, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 	assert.Equal(t, expectedProcessingState, *build.Attributes.ProcessingState)
@@ -225,7 +226,7 @@ func TestGetBuild_ErrNoVersion(t *testing.T) {
 	})
 	defer ctx.Close()
 
-	build, err := client.GetBuild(ctx.Context, &app)
+	build, err := client.GetBuild(ctx.Context, &app, "", nil)
 	assert.Error(t, err)
 	assert.Equal(t, "no version provided to lookup build with", err.Error())
 	assert.Nil(t, build)
@@ -247,7 +248,7 @@ func TestGetBuild_Err(t *testing.T) {
 	defer ctx.Close()
 
 	ctx.Context.Version = testGetBuildVersion
-	build, err := client.GetBuild(ctx.Context, &app)
+	build, err := client.GetBuild(ctx.Context, &app, "", nil)
 	assert.Error(t, err)
 	assert.Equal(t, fmt.Sprintf("build not found matching app=com.app.bundleid, version=1.0: GET %s/v1/builds: 404\n", ctx.server.URL), err.Error())
 	assert.Nil(t, build)
@@ -268,9 +269,21 @@ func TestGetBuild_ErrNoBuilds(t *testing.T) {
 	defer ctx.Close()
 
 	ctx.Context.Version = testGetBuildVersion
-	build, err := client.GetBuild(ctx.Context, &app)
+	build, err := client.GetBuild(ctx.Context, &app, config.PlatformTvOS, nil)
+	assert.Error(t, err)
+	assert.Equal(t, "build not found matching app=com.app.bundleid, version=1.0, platform=tvOS", err.Error())
+	assert.Nil(t, build)
+}
+
+func TestGetBuild_ErrInvalidPlatform(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+	defer ctx.Close()
+
+	ctx.Context.Version = testGetBuildVersion
+	build, err := client.GetBuild(ctx.Context, &asc.App{}, "watchOS", nil)
 	assert.Error(t, err)
-	assert.Equal(t, "build not found matching app=com.app.bundleid, version=1.0", err.Error())
 	assert.Nil(t, build)
 }
 
@@ -289,7 +302,7 @@ func TestGetBuild_ErrNoAttributes(t *testing.T) {
 	defer ctx.Close()
 
 	ctx.Context.Version = testGetBuildVersion
-	build, err := client.GetBuild(ctx.Context, &app)
+	build, err := client.GetBuild(ctx.Context, &app, "", nil)
 	assert.Error(t, err)
 	assert.Equal(t, "build  has no attributes", err.Error())
 	assert.Nil(t, build)
@@ -310,7 +323,7 @@ func TestGetBuild_ErrNoProcessingState(t *testing.T) {
 	defer ctx.Close()
 
 	ctx.Context.Version = testGetBuildVersion
-	build, err := client.GetBuild(ctx.Context, &app)
+	build, err := client.GetBuild(ctx.Context, &app, "", nil)
 	assert.Error(t, err)
 	assert.Equal(t, "build  has no processing state", err.Error())
 	assert.Nil(t, build)
@@ -331,7 +344,7 @@ func TestGetBuild_ErrInvalidProcessingState(t *testing.T) {
 	defer ctx.Close()
 
 	ctx.Context.Version = testGetBuildVersion
-	build, err := client.GetBuild(ctx.Context, &app)
+	build, err := client.GetBuild(ctx.Context, &app, "", nil)
 	assert.Error(t, err)
 	assert.Equal(t, "latest build  has a processing state of PROCESSING. it would be dangerous to proceed", err.Error())
 	assert.Nil(t, build)
@@ -353,7 +366,7 @@ func TestReleaseForAppIsInitial_HappyInitial(t *testing.T) {
 
 	defer ctx.Close()
 
-	initial, err := client.ReleaseForAppIsInitial(ctx.Context, app.ID)
+	initial, err := client.ReleaseForAppIsInitial(ctx.Context, app.ID, "")
 	assert.NoError(t, err)
 	assert.True(t, initial)
 }
@@ -369,7 +382,7 @@ func TestReleaseForAppIsInitial_Err(t *testing.T) {
 
 	defer ctx.Close()
 
-	initial, err := client.ReleaseForAppIsInitial(ctx.Context, app.ID)
+	initial, err := client.ReleaseForAppIsInitial(ctx.Context, app.ID, "")
 	assert.Error(t, err)
 	assert.False(t, initial)
 }
@@ -390,7 +403,19 @@ func TestReleaseForAppIsInitial_HappyNotInitial(t *testing.T) {
 
 	defer ctx.Close()
 
-	initial, err := client.ReleaseForAppIsInitial(ctx.Context, app.ID)
+	initial, err := client.ReleaseForAppIsInitial(ctx.Context, app.ID, config.PlatformiOS)
 	assert.NoError(t, err)
 	assert.False(t, initial)
 }
+
+func TestReleaseForAppIsInitial_ErrInvalidPlatform(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+
+	defer ctx.Close()
+
+	initial, err := client.ReleaseForAppIsInitial(ctx.Context, "TEST", "watchOS")
+	assert.Error(t, err)
+	assert.False(t, initial)
+}
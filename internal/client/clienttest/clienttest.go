@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/cidertool/asc-go/asc"
+	"github.com/cidertool/cider/internal/client"
 	"github.com/cidertool/cider/pkg/config"
 	"github.com/cidertool/cider/pkg/context"
 )
@@ -73,7 +74,7 @@ func (c *Client) GetAppInfo(ctx *context.Context, appID string) (*asc.AppInfo, e
 }
 
 // GetBuild mocks returning the latest valid build corresponding to an app.
-func (c *Client) GetBuild(ctx *context.Context, app *asc.App) (*asc.Build, error) {
+func (c *Client) GetBuild(ctx *context.Context, app *asc.App, platform config.Platform, selection *config.BuildSelection) (*asc.Build, error) {
 	var testImageSize = 140
 
 	return &asc.Build{
@@ -96,15 +97,30 @@ func (c *Client) GetBuild(ctx *context.Context, app *asc.App) (*asc.Build, error
 }
 
 // ReleaseForAppIsInitial mocks returning whether or not an app has released on the App Store before.
-func (c *Client) ReleaseForAppIsInitial(ctx *context.Context, appID string) (bool, error) {
+func (c *Client) ReleaseForAppIsInitial(ctx *context.Context, appID string, platform config.Platform) (bool, error) {
 	return false, nil
 }
 
+// UpdateBuildExportCompliance mocks declaring a build's use of encryption.
+func (c *Client) UpdateBuildExportCompliance(ctx *context.Context, appID string, build *asc.Build, config config.ExportCompliance) error {
+	return nil
+}
+
 // UpdateBetaAppLocalizations mocks updating localized properties for a beta app.
 func (c *Client) UpdateBetaAppLocalizations(ctx *context.Context, appID string, config config.TestflightLocalizations) error {
 	return nil
 }
 
+// GetBetaAppLocalizations mocks returning the localized properties of a beta app.
+func (c *Client) GetBetaAppLocalizations(ctx *context.Context, appID string) (config.TestflightLocalizations, error) {
+	return config.TestflightLocalizations{
+		"en-US": {
+			Description:   "TEST",
+			FeedbackEmail: "test@example.com",
+		},
+	}, nil
+}
+
 // UpdateBetaBuildDetails mocks updating beta build details for a beta build.
 func (c *Client) UpdateBetaBuildDetails(ctx *context.Context, buildID string, config config.Testflight) error {
 	return nil
@@ -115,6 +131,15 @@ func (c *Client) UpdateBetaBuildLocalizations(ctx *context.Context, buildID stri
 	return nil
 }
 
+// GetBetaBuildLocalizations mocks returning the localized properties of a beta build.
+func (c *Client) GetBetaBuildLocalizations(ctx *context.Context, buildID string) (config.TestflightLocalizations, error) {
+	return config.TestflightLocalizations{
+		"en-US": {
+			WhatsNew: "TEST",
+		},
+	}, nil
+}
+
 // UpdateBetaLicenseAgreement mocks updating the beta license agreement for an app.
 func (c *Client) UpdateBetaLicenseAgreement(ctx *context.Context, appID string, config config.Testflight) error {
 	return nil
@@ -130,16 +155,100 @@ func (c *Client) AssignBetaTesters(ctx *context.Context, appID string, buildID s
 	return nil
 }
 
+// SyncBetaGroups mocks removing unlisted testers and unmanaged groups.
+func (c *Client) SyncBetaGroups(ctx *context.Context, appID string, config config.Testflight) error {
+	return nil
+}
+
+// GetBetaGroupPublicLinks mocks returning the public links of an app's beta groups.
+func (c *Client) GetBetaGroupPublicLinks(ctx *context.Context, appID string) (map[string]string, error) {
+	return map[string]string{
+		"TEST": "https://testflight.apple.com/join/TEST",
+	}, nil
+}
+
+// ListBetaTesters mocks listing the beta testers of an app.
+func (c *Client) ListBetaTesters(ctx *context.Context, appID string) ([]client.Tester, error) {
+	return []client.Tester{
+		{
+			ID:        "TEST",
+			Email:     "test@example.com",
+			FirstName: "Person",
+			LastName:  "Personson",
+			Groups:    []string{"TEST"},
+		},
+	}, nil
+}
+
+// ListBetaTesterActivity mocks listing the activity of the beta testers of an app.
+func (c *Client) ListBetaTesterActivity(ctx *context.Context, appID string, days int) (map[string]client.TesterActivity, error) {
+	return map[string]client.TesterActivity{
+		"TEST": {State: client.BetaTesterStateInvited},
+	}, nil
+}
+
+// AddBetaTesters mocks adding beta testers to an app's beta groups.
+func (c *Client) AddBetaTesters(ctx *context.Context, appID string, testers []client.Tester) error {
+	return nil
+}
+
+// RemoveBetaTesters mocks removing beta testers from an app or its beta groups.
+func (c *Client) RemoveBetaTesters(ctx *context.Context, appID string, testers []client.Tester) error {
+	return nil
+}
+
+// ListBetaFeedback mocks listing the feedback submitted by beta testers.
+func (c *Client) ListBetaFeedback(ctx *context.Context, appID string, since time.Time) ([]client.BetaFeedback, error) {
+	return []client.BetaFeedback{
+		{
+			ID:          "TEST",
+			Kind:        client.BetaFeedbackCrash,
+			CreatedDate: time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Build:       "1",
+			Tester:      "test@example.com",
+			DeviceModel: "iPhone13,2",
+			OSVersion:   "14.4",
+			Comment:     "TEST",
+		},
+	}, nil
+}
+
 // UpdateBetaReviewDetails mocks updating review details for a beta app.
 func (c *Client) UpdateBetaReviewDetails(ctx *context.Context, appID string, config config.ReviewDetails) error {
 	return nil
 }
 
+// GetBetaReviewDetails mocks returning review details for a beta app.
+func (c *Client) GetBetaReviewDetails(ctx *context.Context, appID string) (*config.ReviewDetails, error) {
+	return &config.ReviewDetails{
+		Notes: "TEST",
+	}, nil
+}
+
 // SubmitBetaApp mocks submitting an app to Testflight.
 func (c *Client) SubmitBetaApp(ctx *context.Context, buildID string) error {
 	return nil
 }
 
+// GetPromotableBuild mocks getting a build that passed beta review.
+func (c *Client) GetPromotableBuild(ctx *context.Context, appID string, buildID string, betaGroup string) (*client.PromotableBuild, error) {
+	build, err := c.GetBuild(ctx, nil, config.PlatformiOS, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client.PromotableBuild{
+		Build:    build,
+		Version:  "1.0",
+		Platform: config.PlatformiOS,
+	}, nil
+}
+
+// NotifyBetaTesters mocks notifying beta testers of a build.
+func (c *Client) NotifyBetaTesters(ctx *context.Context, buildID string) error {
+	return nil
+}
+
 // UpdateApp mocks updating properties for an app.
 func (c *Client) UpdateApp(ctx *context.Context, appID string, appInfoID string, versionID string, config config.App) error {
 	return nil
@@ -150,6 +259,32 @@ func (c *Client) UpdateAppLocalizations(ctx *context.Context, appID string, conf
 	return nil
 }
 
+// GetAppLocalizations mocks returning the localized properties of an app info.
+func (c *Client) GetAppLocalizations(ctx *context.Context, appID string) (config.AppLocalizations, error) {
+	return config.AppLocalizations{
+		"en-US": {
+			Name:     "TEST",
+			Subtitle: "TEST",
+		},
+	}, nil
+}
+
+// GetAppDetails mocks returning the app-wide details of an app.
+func (c *Client) GetAppDetails(ctx *context.Context, appID string, appInfoID string) (*config.App, error) {
+	return &config.App{
+		PrimaryLocale:         "en-US",
+		UsesThirdPartyContent: asc.Bool(false),
+		Availability: &config.Availability{
+			AvailableInNewTerritories: asc.Bool(true),
+			Pricing:                   []config.PriceSchedule{{Tier: "0"}},
+			Territories:               []string{"USA"},
+		},
+		Categories: &config.Categories{
+			Primary: "SOCIAL_NETWORKING",
+		},
+	}, nil
+}
+
 // CreateVersionIfNeeded mocks creating a version, if one does not exist for the tag, and returning its model.
 func (c *Client) CreateVersionIfNeeded(ctx *context.Context, appID string, buildID string, config config.Version) (*asc.AppStoreVersion, error) {
 	appStoreState := asc.AppStoreVersionStatePrepareForSubmission
@@ -176,6 +311,29 @@ func (c *Client) UpdateVersionLocalizations(ctx *context.Context, versionID stri
 	return nil
 }
 
+// GetVersionLocalizations mocks returning the localized properties of a version.
+func (c *Client) GetVersionLocalizations(ctx *context.Context, versionID string) (config.VersionLocalizations, error) {
+	return config.VersionLocalizations{
+		"en-US": {
+			Description:  "TEST",
+			WhatsNewText: "TEST",
+		},
+	}, nil
+}
+
+// GetVersionDetails mocks returning the copyright, release type and earliest release date of a version.
+func (c *Client) GetVersionDetails(ctx *context.Context, versionID string) (*config.Version, error) {
+	return &config.Version{
+		Copyright:   "2020",
+		ReleaseType: config.ReleaseTypeAfterApproval,
+	}, nil
+}
+
+// UpdateVersionDetails mocks updating the copyright, release type and earliest release date of a version.
+func (c *Client) UpdateVersionDetails(ctx *context.Context, versionID string, config config.Version) error {
+	return nil
+}
+
 // UpdateIDFADeclaration mocks updating the IDFA declaration for a version.
 func (c *Client) UpdateIDFADeclaration(ctx *context.Context, versionID string, config config.IDFADeclaration) error {
 	return nil
@@ -191,6 +349,21 @@ func (c *Client) UpdateReviewDetails(ctx *context.Context, versionID string, con
 	return nil
 }
 
+// GetReviewDetails mocks returning review details for a version.
+func (c *Client) GetReviewDetails(ctx *context.Context, versionID string) (*config.ReviewDetails, error) {
+	return &config.ReviewDetails{
+		Contact: &config.ContactPerson{
+			Email: "test@example.com",
+		},
+		Notes: "TEST",
+	}, nil
+}
+
+// UpdateLivePromotionalText mocks updating the promotional text of the live version of an app.
+func (c *Client) UpdateLivePromotionalText(ctx *context.Context, appID string, platform config.Platform, texts map[string]string) error {
+	return nil
+}
+
 // EnablePhasedRelease mocks enabling phased release for a version.
 func (c *Client) EnablePhasedRelease(ctx *context.Context, versionID string) error {
 	return nil
@@ -22,6 +22,7 @@ package clienttest_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cidertool/cider/internal/client/clienttest"
 	"github.com/cidertool/cider/pkg/config"
@@ -44,23 +45,34 @@ func TestClient(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, info)
 
-	build, err := c.GetBuild(ctx, nil)
+	build, err := c.GetBuild(ctx, nil, config.PlatformiOS, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 
-	initial, err := c.ReleaseForAppIsInitial(ctx, "TEST")
+	initial, err := c.ReleaseForAppIsInitial(ctx, "TEST", config.PlatformiOS)
 	assert.NoError(t, err)
 	assert.False(t, initial)
 
+	err = c.UpdateBuildExportCompliance(ctx, "TEST", build, config.ExportCompliance{})
+	assert.NoError(t, err)
+
 	err = c.UpdateBetaAppLocalizations(ctx, "TEST", config.TestflightLocalizations{})
 	assert.NoError(t, err)
 
+	betaAppLocs, err := c.GetBetaAppLocalizations(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, betaAppLocs)
+
 	err = c.UpdateBetaBuildDetails(ctx, "TEST", config.Testflight{})
 	assert.NoError(t, err)
 
 	err = c.UpdateBetaBuildLocalizations(ctx, "TEST", config.TestflightLocalizations{})
 	assert.NoError(t, err)
 
+	betaBuildLocs, err := c.GetBetaBuildLocalizations(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, betaBuildLocs)
+
 	err = c.UpdateBetaLicenseAgreement(ctx, "TEST", config.Testflight{})
 	assert.NoError(t, err)
 
@@ -70,18 +82,62 @@ func TestClient(t *testing.T) {
 	err = c.AssignBetaTesters(ctx, "TEST", "TEST", []config.BetaTester{})
 	assert.NoError(t, err)
 
+	err = c.SyncBetaGroups(ctx, "TEST", config.Testflight{})
+	assert.NoError(t, err)
+
+	links, err := c.GetBetaGroupPublicLinks(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, links)
+
+	testers, err := c.ListBetaTesters(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, testers)
+
+	activity, err := c.ListBetaTesterActivity(ctx, "TEST", 30)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, activity)
+
+	err = c.AddBetaTesters(ctx, "TEST", testers)
+	assert.NoError(t, err)
+
+	err = c.RemoveBetaTesters(ctx, "TEST", testers)
+	assert.NoError(t, err)
+
+	feedback, err := c.ListBetaFeedback(ctx, "TEST", time.Time{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, feedback)
+
 	err = c.UpdateBetaReviewDetails(ctx, "TEST", config.ReviewDetails{})
 	assert.NoError(t, err)
 
+	betaDetails, err := c.GetBetaReviewDetails(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotNil(t, betaDetails)
+
 	err = c.SubmitBetaApp(ctx, "TEST")
 	assert.NoError(t, err)
 
+	err = c.NotifyBetaTesters(ctx, "TEST")
+	assert.NoError(t, err)
+
+	promotable, err := c.GetPromotableBuild(ctx, "TEST", "TEST", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, promotable)
+
 	err = c.UpdateApp(ctx, "TEST", "TEST", "TEST", config.App{})
 	assert.NoError(t, err)
 
 	err = c.UpdateAppLocalizations(ctx, "TEST", config.AppLocalizations{})
 	assert.NoError(t, err)
 
+	appLocs, err := c.GetAppLocalizations(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, appLocs)
+
+	appDetails, err := c.GetAppDetails(ctx, "TEST", "TEST")
+	assert.NoError(t, err)
+	assert.NotNil(t, appDetails)
+
 	version, err := c.CreateVersionIfNeeded(ctx, "TEST", "TEST", config.Version{})
 	assert.NoError(t, err)
 	assert.NotNil(t, version)
@@ -89,6 +145,17 @@ func TestClient(t *testing.T) {
 	err = c.UpdateVersionLocalizations(ctx, "TEST", config.VersionLocalizations{})
 	assert.NoError(t, err)
 
+	versionLocs, err := c.GetVersionLocalizations(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, versionLocs)
+
+	versionDetails, err := c.GetVersionDetails(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotNil(t, versionDetails)
+
+	err = c.UpdateVersionDetails(ctx, "TEST", config.Version{})
+	assert.NoError(t, err)
+
 	err = c.UpdateIDFADeclaration(ctx, "TEST", config.IDFADeclaration{})
 	assert.NoError(t, err)
 
@@ -98,6 +165,13 @@ func TestClient(t *testing.T) {
 	err = c.UpdateReviewDetails(ctx, "TEST", config.ReviewDetails{})
 	assert.NoError(t, err)
 
+	details, err := c.GetReviewDetails(ctx, "TEST")
+	assert.NoError(t, err)
+	assert.NotNil(t, details)
+
+	err = c.UpdateLivePromotionalText(ctx, "TEST", config.PlatformiOS, map[string]string{})
+	assert.NoError(t, err)
+
 	err = c.EnablePhasedRelease(ctx, "TEST")
 	assert.NoError(t, err)
 
@@ -0,0 +1,178 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cidertool/asc-go/asc"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+const (
+	// BetaFeedbackScreenshot is the kind of beta feedback testers submit with screenshots.
+	BetaFeedbackScreenshot = "screenshot"
+	// BetaFeedbackCrash is the kind of beta feedback testers submit after a crash.
+	BetaFeedbackCrash = "crash"
+)
+
+// BetaFeedback is a feedback submission sent by a beta tester of an app through TestFlight.
+type BetaFeedback struct {
+	ID string
+	// Kind of the submission, either BetaFeedbackScreenshot or BetaFeedbackCrash.
+	Kind        string
+	CreatedDate time.Time
+	// Version of the build the tester was using.
+	Build string
+	// Email of the tester, if they shared it.
+	Tester      string
+	DeviceModel string
+	OSVersion   string
+	Comment     string
+	// URLs of the screenshots attached to the submission. They expire shortly after being listed.
+	Screenshots []string
+}
+
+// betaFeedbackEndpoints maps the kinds of beta feedback to the relationships of an app that list them,
+// which the version of the API client in use doesn't support.
+var betaFeedbackEndpoints = []struct {
+	Kind string
+	Path string
+}{
+	{Kind: BetaFeedbackScreenshot, Path: "betaFeedbackScreenshotSubmissions"},
+	{Kind: BetaFeedbackCrash, Path: "betaFeedbackCrashSubmissions"},
+}
+
+// betaFeedbackResponse is a page of beta feedback submissions, with their builds and testers included.
+type betaFeedbackResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			CreatedDate *asc.DateTime `json:"createdDate,omitempty"`
+			Comment     *string       `json:"comment,omitempty"`
+			Email       *string       `json:"email,omitempty"`
+			DeviceModel *string       `json:"deviceModel,omitempty"`
+			OSVersion   *string       `json:"osVersion,omitempty"`
+			Screenshots []struct {
+				URL string `json:"url"`
+			} `json:"screenshots,omitempty"`
+		} `json:"attributes"`
+		Relationships struct {
+			Build struct {
+				Data *asc.RelationshipData `json:"data,omitempty"`
+			} `json:"build"`
+		} `json:"relationships"`
+	} `json:"data"`
+	Included []struct {
+		Type       string `json:"type"`
+		ID         string `json:"id"`
+		Attributes struct {
+			Version *string `json:"version,omitempty"`
+		} `json:"attributes"`
+	} `json:"included,omitempty"`
+	Links asc.PagedDocumentLinks `json:"links"`
+}
+
+func (c *ascClient) ListBetaFeedback(ctx *context.Context, appID string, since time.Time) ([]BetaFeedback, error) {
+	var feedback []BetaFeedback
+
+	for _, endpoint := range betaFeedbackEndpoints {
+		entries, err := c.listBetaFeedback(ctx, appID, endpoint.Kind, endpoint.Path, since)
+		if err != nil {
+			return nil, err
+		}
+
+		feedback = append(feedback, entries...)
+	}
+
+	sort.SliceStable(feedback, func(i, j int) bool {
+		return feedback[i].CreatedDate.Before(feedback[j].CreatedDate)
+	})
+
+	ctx.Log.WithField("count", len(feedback)).Debug("listed beta feedback")
+
+	return feedback, nil
+}
+
+// listBetaFeedback lists the submissions of one kind made at or after since, newest first, so paging can
+// stop at the first older submission.
+func (c *ascClient) listBetaFeedback(ctx *context.Context, appID, kind, path string, since time.Time) ([]BetaFeedback, error) {
+	var feedback []BetaFeedback
+
+	query := url.Values{}
+	query.Set("sort", "-createdDate")
+	query.Set("include", "build")
+	query.Set("fields[builds]", "version")
+	query.Set("limit", strconv.Itoa(maxPageSize))
+
+	for ref := (&asc.Reference{URL: url.URL{Path: fmt.Sprintf("apps/%s/%s", appID, path), RawQuery: query.Encode()}}); ref != nil; {
+		var resp betaFeedbackResponse
+		if _, err := c.client.FollowReference(ctx, ref, &resp); err != nil {
+			return nil, err
+		}
+
+		// Map of build IDs -> build versions
+		var builds = make(map[string]string)
+
+		for _, included := range resp.Included {
+			if included.Type == "builds" {
+				builds[included.ID] = stringValue(included.Attributes.Version)
+			}
+		}
+
+		for _, submission := range resp.Data {
+			entry := BetaFeedback{
+				ID:          submission.ID,
+				Kind:        kind,
+				Tester:      stringValue(submission.Attributes.Email),
+				DeviceModel: stringValue(submission.Attributes.DeviceModel),
+				OSVersion:   stringValue(submission.Attributes.OSVersion),
+				Comment:     stringValue(submission.Attributes.Comment),
+			}
+
+			if submission.Attributes.CreatedDate != nil {
+				entry.CreatedDate = submission.Attributes.CreatedDate.Time
+			}
+
+			if entry.CreatedDate.Before(since) {
+				return feedback, nil
+			}
+
+			if build := submission.Relationships.Build.Data; build != nil {
+				entry.Build = builds[build.ID]
+			}
+
+			for _, screenshot := range submission.Attributes.Screenshots {
+				entry.Screenshots = append(entry.Screenshots, screenshot.URL)
+			}
+
+			feedback = append(feedback, entry)
+		}
+
+		ref = resp.Links.Next
+	}
+
+	return feedback, nil
+}
@@ -0,0 +1,144 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test ListBetaFeedback
+
+func TestListBetaFeedback_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"s2","attributes":{"createdDate":"2021-03-02T10:00:00Z","comment":"Button is cut off","email":"test@example.com","deviceModel":"iPhone13,2","osVersion":"14.4","screenshots":[{"url":"https://example.com/s2.png","width":1170,"height":2532}]},"relationships":{"build":{"data":{"type":"builds","id":"b1"}}}}],"included":[{"type":"builds","id":"b1","attributes":{"version":"42"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackScreenshotSubmissions","next":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackScreenshotSubmissions?cursor=next"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"s1","attributes":{"createdDate":"2021-03-01T10:00:00Z"}},{"id":"s0","attributes":{"createdDate":"2021-02-01T10:00:00Z"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackScreenshotSubmissions","next":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackScreenshotSubmissions?cursor=older"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"c1","attributes":{"createdDate":"2021-03-01T12:00:00Z","comment":"It crashed","deviceModel":"iPad8,1","osVersion":"14.3"},"relationships":{"build":{"data":{"type":"builds","id":"b2"}}}}],"included":[{"type":"builds","id":"b2","attributes":{"version":"41"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackCrashSubmissions"}}`,
+		},
+	)
+	defer ctx.Close()
+
+	feedback, err := client.ListBetaFeedback(ctx.Context, testID, time.Date(2021, time.February, 15, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, []BetaFeedback{
+		{
+			ID:          "s1",
+			Kind:        BetaFeedbackScreenshot,
+			CreatedDate: time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:          "c1",
+			Kind:        BetaFeedbackCrash,
+			CreatedDate: time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC),
+			Build:       "41",
+			DeviceModel: "iPad8,1",
+			OSVersion:   "14.3",
+			Comment:     "It crashed",
+		},
+		{
+			ID:          "s2",
+			Kind:        BetaFeedbackScreenshot,
+			CreatedDate: time.Date(2021, time.March, 2, 10, 0, 0, 0, time.UTC),
+			Build:       "42",
+			Tester:      "test@example.com",
+			DeviceModel: "iPhone13,2",
+			OSVersion:   "14.4",
+			Comment:     "Button is cut off",
+			Screenshots: []string{"https://example.com/s2.png"},
+		},
+	}, feedback)
+	// The older page of screenshot submissions is never requested.
+	assert.Equal(t, 3, ctx.CurrentResponseIndex)
+}
+
+func TestListBetaFeedback_SinceBoundary(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"s2","attributes":{"createdDate":"2021-03-01T10:00:00Z"}},{"id":"s1","attributes":{"createdDate":"2021-03-01T10:00:00Z"}},{"id":"s0","attributes":{"createdDate":"2021-03-01T09:59:59Z"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackScreenshotSubmissions"}}`,
+		},
+		response{
+			RawResponse: `{"data":[],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackCrashSubmissions"}}`,
+		},
+	)
+	defer ctx.Close()
+
+	// Submissions created at the same time as since are listed, since an earlier export may have missed
+	// some of them.
+	feedback, err := client.ListBetaFeedback(ctx.Context, testID, time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, feedback, 2)
+	assert.Equal(t, "s2", feedback[0].ID)
+	assert.Equal(t, "s1", feedback[1].ID)
+}
+
+func TestListBetaFeedback_All(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"s1","attributes":{"createdDate":"2021-03-01T10:00:00Z"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackScreenshotSubmissions"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"c1","attributes":{"createdDate":"2020-03-01T10:00:00Z"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackCrashSubmissions"}}`,
+		},
+	)
+	defer ctx.Close()
+
+	feedback, err := client.ListBetaFeedback(ctx.Context, testID, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, feedback, 2)
+	assert.Equal(t, "c1", feedback[0].ID)
+}
+
+func TestListBetaFeedback_Err(t *testing.T) {
+	t.Parallel()
+
+	screenshots := response{
+		RawResponse: `{"data":[],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/betaFeedbackScreenshotSubmissions"}}`,
+	}
+	notFound := response{
+		StatusCode:  http.StatusNotFound,
+		RawResponse: `{}`,
+	}
+
+	ctx, client := newTestContext(notFound)
+	defer ctx.Close()
+
+	_, err := client.ListBetaFeedback(ctx.Context, testID, time.Time{})
+	assert.Error(t, err)
+
+	ctx.SetResponses(screenshots, notFound)
+
+	_, err = client.ListBetaFeedback(ctx.Context, testID, time.Time{})
+	assert.Error(t, err)
+}
@@ -22,6 +22,8 @@ package client
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/cidertool/asc-go/asc"
 	"github.com/cidertool/cider/internal/parallel"
@@ -37,6 +39,22 @@ func (e errPlatformNotFound) Error() string {
 	return fmt.Sprintf(`platform %s could not be matched up with a supported App Store platform. supported values are "iOS", "macOS", or "tvOS"`, e.Platform)
 }
 
+type errNoLiveVersion struct {
+	Platform config.Platform
+}
+
+func (e errNoLiveVersion) Error() string {
+	return fmt.Sprintf("no version of the app is ready for sale on %s", e.Platform)
+}
+
+type errLocalesNotOnLiveVersion struct {
+	Locales []string
+}
+
+func (e errLocalesNotOnLiveVersion) Error() string {
+	return fmt.Sprintf("the version that is ready for sale isn't localized in %s", strings.Join(e.Locales, ", "))
+}
+
 func (c *ascClient) UpdateApp(ctx *context.Context, appID string, appInfoID string, versionID string, config config.App) error {
 	var g = parallel.New(ctx.MaxProcesses)
 
@@ -283,6 +301,148 @@ func (c *ascClient) UpdateAppLocalizations(ctx *context.Context, appID string, c
 	return g.Wait()
 }
 
+func (c *ascClient) GetAppLocalizations(ctx *context.Context, appID string) (config.AppLocalizations, error) {
+	appInfosResp, _, err := c.client.Apps.ListAppInfosForApp(ctx, appID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	locs := make(config.AppLocalizations)
+
+	for i := range appInfosResp.Data {
+		appInfo := appInfosResp.Data[i]
+		if *appInfo.Attributes.AppStoreState != asc.AppStoreVersionStatePrepareForSubmission {
+			continue
+		}
+
+		appLocResp, _, err := c.client.Apps.ListAppInfoLocalizationsForAppInfo(ctx, appInfo.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, loc := range appLocResp.Data {
+			if loc.Attributes == nil || loc.Attributes.Locale == nil {
+				continue
+			}
+
+			locs[*loc.Attributes.Locale] = config.AppLocalization{
+				Name:              stringValue(loc.Attributes.Name),
+				Subtitle:          stringValue(loc.Attributes.Subtitle),
+				PrivacyPolicyText: stringValue(loc.Attributes.PrivacyPolicyText),
+				PrivacyPolicyURL:  stringValue(loc.Attributes.PrivacyPolicyURL),
+			}
+		}
+	}
+
+	return locs, nil
+}
+
+func (c *ascClient) GetAppDetails(ctx *context.Context, appID string, appInfoID string) (*config.App, error) {
+	appResp, _, err := c.client.Apps.GetApp(ctx, appID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	details := config.App{
+		Availability: &config.Availability{},
+	}
+
+	if attrs := appResp.Data.Attributes; attrs != nil {
+		details.PrimaryLocale = stringValue(attrs.PrimaryLocale)
+		details.UsesThirdPartyContent = usesThirdPartyContent(attrs.ContentRightsDeclaration)
+		details.Availability.AvailableInNewTerritories = attrs.AvailableInNewTerritories
+	}
+
+	territoriesQuery := asc.ListTerritoriesQuery{Limit: maxPageSize}
+
+	for {
+		territoriesResp, _, err := c.client.Pricing.ListTerritoriesForApp(ctx, appID, &territoriesQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, territory := range territoriesResp.Data {
+			details.Availability.Territories = append(details.Availability.Territories, territory.ID)
+		}
+
+		if territoriesResp.Links.Next == nil || territoriesResp.Links.Next.Cursor() == "" {
+			break
+		}
+
+		territoriesQuery.Cursor = territoriesResp.Links.Next.Cursor()
+	}
+
+	pricesResp, _, err := c.client.Pricing.ListPricesForApp(ctx, appID, &asc.ListPricesQuery{
+		Include: []string{"priceTier"},
+		Limit:   maxPageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, price := range pricesResp.Data {
+		if price.Relationships == nil || price.Relationships.PriceTier == nil || price.Relationships.PriceTier.Data == nil {
+			continue
+		}
+
+		details.Availability.Pricing = append(details.Availability.Pricing, config.PriceSchedule{Tier: price.Relationships.PriceTier.Data.ID})
+	}
+
+	appInfoResp, _, err := c.client.Apps.GetAppInfo(ctx, appInfoID, &asc.GetAppInfoQuery{
+		Include: []string{
+			"primaryCategory",
+			"primarySubcategoryOne",
+			"primarySubcategoryTwo",
+			"secondaryCategory",
+			"secondarySubcategoryOne",
+			"secondarySubcategoryTwo",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if rels := appInfoResp.Data.Relationships; rels != nil {
+		details.Categories = &config.Categories{
+			Primary: relationshipID(rels.PrimaryCategory),
+			PrimarySubcategories: [2]string{
+				relationshipID(rels.PrimarySubcategoryOne),
+				relationshipID(rels.PrimarySubcategoryTwo),
+			},
+			Secondary: relationshipID(rels.SecondaryCategory),
+			SecondarySubcategories: [2]string{
+				relationshipID(rels.SecondarySubcategoryOne),
+				relationshipID(rels.SecondarySubcategoryTwo),
+			},
+		}
+	}
+
+	return &details, nil
+}
+
+func usesThirdPartyContent(declaration *string) *bool {
+	if declaration == nil {
+		return nil
+	}
+
+	switch *declaration {
+	case "USES_THIRD_PARTY_CONTENT":
+		return asc.Bool(true)
+	case "DOES_NOT_USE_THIRD_PARTY_CONTENT":
+		return asc.Bool(false)
+	}
+
+	return nil
+}
+
+func relationshipID(rel *asc.Relationship) string {
+	if rel == nil || rel.Data == nil {
+		return ""
+	}
+
+	return rel.Data.ID
+}
+
 func (c *ascClient) CreateVersionIfNeeded(ctx *context.Context, appID string, buildID string, config config.Version) (*asc.AppStoreVersion, error) {
 	platform := config.Platform.APIValue()
 	if platform == nil {
@@ -296,6 +456,11 @@ func (c *ascClient) CreateVersionIfNeeded(ctx *context.Context, appID string, bu
 		earliestReleaseDate = &asc.DateTime{Time: *config.EarliestReleaseDate}
 	}
 
+	var build *string
+	if buildID != "" {
+		build = &buildID
+	}
+
 	var versionResp *asc.AppStoreVersionResponse
 
 	versionsResp, _, err := c.client.Apps.ListAppStoreVersionsForApp(ctx, appID, &asc.ListAppStoreVersionsQuery{
@@ -310,7 +475,7 @@ func (c *ascClient) CreateVersionIfNeeded(ctx *context.Context, appID string, bu
 			ReleaseType:         releaseType,
 			UsesIDFA:            asc.Bool(config.IDFADeclaration != nil),
 			VersionString:       ctx.Version,
-		}, appID, &buildID)
+		}, appID, build)
 	} else {
 		latestVersion := versionsResp.Data[0]
 		versionResp, _, err = c.client.Apps.UpdateAppStoreVersion(ctx, latestVersion.ID, &asc.AppStoreVersionUpdateRequestAttributes{
@@ -319,12 +484,59 @@ func (c *ascClient) CreateVersionIfNeeded(ctx *context.Context, appID string, bu
 			ReleaseType:         releaseType,
 			UsesIDFA:            asc.Bool(config.IDFADeclaration != nil),
 			VersionString:       &ctx.Version,
-		}, &buildID)
+		}, build)
 	}
 
 	return &versionResp.Data, err
 }
 
+func (c *ascClient) GetVersionDetails(ctx *context.Context, versionID string) (*config.Version, error) {
+	resp, _, err := c.client.Apps.GetAppStoreVersion(ctx, versionID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var details config.Version
+
+	attrs := resp.Data.Attributes
+	if attrs == nil {
+		return &details, nil
+	}
+
+	details.Copyright = stringValue(attrs.Copyright)
+
+	if attrs.EarliestReleaseDate != nil {
+		date := attrs.EarliestReleaseDate.Time
+		details.EarliestReleaseDate = &date
+	}
+
+	switch stringValue(attrs.ReleaseType) {
+	case "MANUAL":
+		details.ReleaseType = config.ReleaseTypeManual
+	case "AFTER_APPROVAL":
+		details.ReleaseType = config.ReleaseTypeAfterApproval
+	case "SCHEDULED":
+		details.ReleaseType = config.ReleaseTypeScheduled
+	}
+
+	return &details, nil
+}
+
+func (c *ascClient) UpdateVersionDetails(ctx *context.Context, versionID string, config config.Version) error {
+	var earliestReleaseDate *asc.DateTime
+	if config.EarliestReleaseDate != nil {
+		earliestReleaseDate = &asc.DateTime{Time: *config.EarliestReleaseDate}
+	}
+
+	_, _, err := c.client.Apps.UpdateAppStoreVersion(ctx, versionID, &asc.AppStoreVersionUpdateRequestAttributes{
+		Copyright:           &config.Copyright,
+		EarliestReleaseDate: earliestReleaseDate,
+		ReleaseType:         config.ReleaseType.APIValue(),
+	}, nil)
+
+	return err
+}
+
 func (c *ascClient) UpdateVersionLocalizations(ctx *context.Context, versionID string, config config.VersionLocalizations) error {
 	var g = parallel.New(ctx.MaxProcesses)
 
@@ -382,6 +594,32 @@ func (c *ascClient) UpdateVersionLocalizations(ctx *context.Context, versionID s
 	return g.Wait()
 }
 
+func (c *ascClient) GetVersionLocalizations(ctx *context.Context, versionID string) (config.VersionLocalizations, error) {
+	locListResp, _, err := c.client.Apps.ListLocalizationsForAppStoreVersion(ctx, versionID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	locs := make(config.VersionLocalizations)
+
+	for _, loc := range locListResp.Data {
+		if loc.Attributes == nil || loc.Attributes.Locale == nil {
+			continue
+		}
+
+		locs[*loc.Attributes.Locale] = config.VersionLocalization{
+			Description:     stringValue(loc.Attributes.Description),
+			Keywords:        stringValue(loc.Attributes.Keywords),
+			MarketingURL:    stringValue(loc.Attributes.MarketingURL),
+			PromotionalText: stringValue(loc.Attributes.PromotionalText),
+			SupportURL:      stringValue(loc.Attributes.SupportURL),
+			WhatsNewText:    stringValue(loc.Attributes.WhatsNew),
+		}
+	}
+
+	return locs, nil
+}
+
 func appStoreVersionLocalizationUpdateRequestAttributes(ctx *context.Context, config config.VersionLocalization) *asc.AppStoreVersionLocalizationUpdateRequestAttributes {
 	attrs := asc.AppStoreVersionLocalizationUpdateRequestAttributes{}
 	if config.Description != "" {
@@ -467,6 +705,31 @@ func (c *ascClient) UpdateIDFADeclaration(ctx *context.Context, versionID string
 	return err
 }
 
+func (c *ascClient) GetReviewDetails(ctx *context.Context, versionID string) (*config.ReviewDetails, error) {
+	detailsResp, _, err := c.client.Submission.GetReviewDetailsForAppStoreVersion(ctx, versionID, nil)
+	if err != nil {
+		// Review details don't exist until they're created for the first time, which
+		// UpdateReviewDetails handles the same way.
+		return nil, nil
+	}
+
+	attrs := detailsResp.Data.Attributes
+	if attrs == nil {
+		return nil, nil
+	}
+
+	return reviewDetails(
+		attrs.ContactEmail,
+		attrs.ContactFirstName,
+		attrs.ContactLastName,
+		attrs.ContactPhone,
+		attrs.DemoAccountName,
+		attrs.DemoAccountPassword,
+		attrs.DemoAccountRequired,
+		attrs.Notes,
+	), nil
+}
+
 func (c *ascClient) UpdateReviewDetails(ctx *context.Context, versionID string, config config.ReviewDetails) error {
 	var reviewDetails *asc.AppStoreReviewDetail
 
@@ -554,3 +817,70 @@ func (c *ascClient) SubmitApp(ctx *context.Context, versionID string) error {
 
 	return err
 }
+
+func (c *ascClient) UpdateLivePromotionalText(ctx *context.Context, appID string, platform config.Platform, texts map[string]string) error {
+	value := platform.APIValue()
+	if value == nil {
+		return errPlatformNotFound{Platform: platform}
+	}
+
+	versionsResp, _, err := c.client.Apps.ListAppStoreVersionsForApp(ctx, appID, &asc.ListAppStoreVersionsQuery{
+		FilterAppStoreState: []string{string(asc.AppStoreVersionStateReadyForSale)},
+		FilterPlatform:      []string{string(*value)},
+	})
+	if err != nil {
+		return err
+	} else if len(versionsResp.Data) == 0 {
+		return errNoLiveVersion{Platform: platform}
+	}
+
+	version := versionsResp.Data[0]
+
+	locListResp, _, err := c.client.Apps.ListLocalizationsForAppStoreVersion(ctx, version.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	// Map of locales -> localization IDs
+	var locIDs = make(map[string]string)
+
+	for _, loc := range locListResp.Data {
+		if loc.Attributes != nil && loc.Attributes.Locale != nil {
+			locIDs[*loc.Attributes.Locale] = loc.ID
+		}
+	}
+
+	// Locales can't be added to a version that is ready for sale, so check them all before changing anything.
+	var missing []string
+
+	for locale := range texts {
+		if _, ok := locIDs[locale]; !ok {
+			missing = append(missing, locale)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+
+		return errLocalesNotOnLiveVersion{Locales: missing}
+	}
+
+	var g = parallel.New(ctx.MaxProcesses)
+
+	for locale, text := range texts {
+		locale := locale
+		text := text
+
+		g.Go(func() error {
+			ctx.Log.WithField("locale", locale).Debug("update promotional text")
+
+			_, _, err := c.client.Apps.UpdateAppStoreVersionLocalization(ctx, locIDs[locale], &asc.AppStoreVersionLocalizationUpdateRequestAttributes{
+				PromotionalText: &text,
+			})
+
+			return err
+		})
+	}
+
+	return g.Wait()
+}
@@ -150,6 +150,135 @@ func TestUpdateAppLocalizations_Happy(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// Test GetAppLocalizations
+
+func TestGetAppLocalizations_Happy(t *testing.T) {
+	t.Parallel()
+
+	prepareForSubmission := asc.AppStoreVersionStatePrepareForSubmission
+	readyForSale := asc.AppStoreVersionStateReadyForSale
+	ctx, client := newTestContext(
+		response{
+			Response: asc.AppInfosResponse{
+				Data: []asc.AppInfo{
+					{
+						Attributes: &asc.AppInfoAttributes{
+							AppStoreState: &readyForSale,
+						},
+						ID: "TEST",
+					},
+					{
+						Attributes: &asc.AppInfoAttributes{
+							AppStoreState: &prepareForSubmission,
+						},
+						ID: "TEST",
+					},
+				},
+			},
+		},
+		response{
+			Response: asc.AppInfoLocalizationsResponse{
+				Data: []asc.AppInfoLocalization{
+					{
+						Attributes: &asc.AppInfoLocalizationAttributes{
+							Locale:   asc.String("en-US"),
+							Name:     asc.String("My App"),
+							Subtitle: asc.String("Subtitle"),
+						},
+					},
+				},
+			},
+		},
+	)
+
+	defer ctx.Close()
+
+	locs, err := client.GetAppLocalizations(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, config.AppLocalizations{
+		"en-US": {
+			Name:     "My App",
+			Subtitle: "Subtitle",
+		},
+	}, locs)
+}
+
+func TestGetAppLocalizations_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+
+	defer ctx.Close()
+
+	_, err := client.GetAppLocalizations(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
+// Test GetAppDetails
+
+func TestGetAppDetails_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":{"id":"TEST","attributes":{"primaryLocale":"en-US","contentRightsDeclaration":"USES_THIRD_PARTY_CONTENT","availableInNewTerritories":true}}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"USA"}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/availableTerritories","next":"https://api.appstoreconnect.apple.com/v1/apps/TEST/availableTerritories?cursor=next"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"CAN"}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/availableTerritories?cursor=next"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"p1","relationships":{"priceTier":{"data":{"type":"appPriceTiers","id":"3"}}}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/prices"}}`,
+		},
+		response{
+			RawResponse: `{"data":{"id":"TEST","relationships":{"primaryCategory":{"data":{"type":"appCategories","id":"GAMES"}},"primarySubcategoryOne":{"data":{"type":"appCategories","id":"GAMES_RACING"}},"secondaryCategory":{"data":{"type":"appCategories","id":"SOCIAL_NETWORKING"}}}}}`,
+		},
+	)
+
+	defer ctx.Close()
+
+	details, err := client.GetAppDetails(ctx.Context, testID, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.App{
+		PrimaryLocale:         "en-US",
+		UsesThirdPartyContent: asc.Bool(true),
+		Availability: &config.Availability{
+			AvailableInNewTerritories: asc.Bool(true),
+			Pricing:                   []config.PriceSchedule{{Tier: "3"}},
+			Territories:               []string{"USA", "CAN"},
+		},
+		Categories: &config.Categories{
+			Primary:              "GAMES",
+			PrimarySubcategories: [2]string{"GAMES_RACING", ""},
+			Secondary:            "SOCIAL_NETWORKING",
+		},
+	}, details)
+}
+
+func TestGetAppDetails_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+
+	defer ctx.Close()
+
+	_, err := client.GetAppDetails(ctx.Context, testID, testID)
+	assert.Error(t, err)
+}
+
+// Test CreateVersionIfNeeded
 // Test CreateVersionIfNeeded
 
 func TestCreateVersionIfNeeded_Happy(t *testing.T) {
@@ -176,6 +305,27 @@ func TestCreateVersionIfNeeded_Happy(t *testing.T) {
 	assert.NotNil(t, version)
 }
 
+func TestCreateVersionIfNeeded_WithoutBuild(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[]}`,
+		},
+		response{
+			RawResponse: `{"data":{"id":"TEST"}}`,
+		},
+	)
+
+	defer ctx.Close()
+
+	version, err := client.CreateVersionIfNeeded(ctx.Context, testID, "", config.Version{
+		Platform: config.PlatformiOS,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "TEST", version.ID)
+}
+
 // Test UpdateVersionLocalizations
 
 func TestUpdateVersionLocalizations_Happy(t *testing.T) {
@@ -408,6 +558,210 @@ func TestUpdateVersionLocalizations_Happy(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// Test UpdateLivePromotionalText
+
+func TestUpdateLivePromotionalText_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"v1","attributes":{"appStoreState":"READY_FOR_SALE"}}]}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"l1","attributes":{"locale":"en-US"}},{"id":"l2","attributes":{"locale":"fr-FR"}}]}`,
+		},
+		response{
+			RawResponse: `{"data":{"id":"l1","attributes":{"locale":"en-US","promotionalText":"TEST"}}}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.UpdateLivePromotionalText(ctx.Context, testID, config.PlatformiOS, map[string]string{
+		"en-US": "TEST",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, ctx.CurrentResponseIndex)
+}
+
+func TestUpdateLivePromotionalText_ErrNoLiveVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[]}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.UpdateLivePromotionalText(ctx.Context, testID, config.PlatformMacOS, map[string]string{"en-US": "TEST"})
+	assert.EqualError(t, err, "no version of the app is ready for sale on macOS")
+
+	err = client.UpdateLivePromotionalText(ctx.Context, testID, "watchOS", map[string]string{"en-US": "TEST"})
+	assert.Error(t, err)
+}
+
+func TestUpdateLivePromotionalText_ErrMissingLocales(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"v1"}]}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"l1","attributes":{"locale":"en-US"}}]}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.UpdateLivePromotionalText(ctx.Context, testID, config.PlatformiOS, map[string]string{
+		"en-US": "TEST",
+		"ja":    "TEST",
+		"de-DE": "TEST",
+	})
+	assert.EqualError(t, err, "the version that is ready for sale isn't localized in de-DE, ja")
+	assert.Equal(t, 2, ctx.CurrentResponseIndex)
+}
+
+func TestUpdateLivePromotionalText_Err(t *testing.T) {
+	t.Parallel()
+
+	versions := response{
+		RawResponse: `{"data":[{"id":"v1"}]}`,
+	}
+	locs := response{
+		RawResponse: `{"data":[{"id":"l1","attributes":{"locale":"en-US"}}]}`,
+	}
+	notFound := response{
+		StatusCode:  http.StatusNotFound,
+		RawResponse: `{}`,
+	}
+	texts := map[string]string{"en-US": "TEST"}
+
+	ctx, client := newTestContext(notFound)
+	defer ctx.Close()
+
+	err := client.UpdateLivePromotionalText(ctx.Context, testID, config.PlatformiOS, texts)
+	assert.Error(t, err)
+
+	ctx.SetResponses(versions, notFound)
+
+	err = client.UpdateLivePromotionalText(ctx.Context, testID, config.PlatformiOS, texts)
+	assert.Error(t, err)
+
+	ctx.SetResponses(versions, locs, notFound)
+
+	err = client.UpdateLivePromotionalText(ctx.Context, testID, config.PlatformiOS, texts)
+	assert.Error(t, err)
+}
+
+// Test GetVersionLocalizations
+
+func TestGetVersionLocalizations_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.AppStoreVersionLocalizationsResponse{
+				Data: []asc.AppStoreVersionLocalization{
+					{
+						Attributes: &asc.AppStoreVersionLocalizationAttributes{
+							Description: asc.String("Description"),
+							Locale:      asc.String("en-US"),
+							WhatsNew:    asc.String("Bug fixes"),
+						},
+					},
+				},
+			},
+		},
+	)
+
+	defer ctx.Close()
+
+	locs, err := client.GetVersionLocalizations(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, config.VersionLocalizations{
+		"en-US": {
+			Description:  "Description",
+			WhatsNewText: "Bug fixes",
+		},
+	}, locs)
+}
+
+func TestGetVersionLocalizations_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+
+	defer ctx.Close()
+
+	_, err := client.GetVersionLocalizations(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
+// Test GetVersionDetails
+
+func TestGetVersionDetails_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":{"id":"TEST","attributes":{"copyright":"2020 Me","releaseType":"SCHEDULED","earliestReleaseDate":"2020-06-01T12:00:00Z"}}}`,
+		},
+	)
+
+	defer ctx.Close()
+
+	details, err := client.GetVersionDetails(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2020 Me", details.Copyright)
+	assert.Equal(t, config.ReleaseTypeScheduled, details.ReleaseType)
+	assert.True(t, time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC).Equal(*details.EarliestReleaseDate))
+}
+
+func TestGetVersionDetails_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+
+	defer ctx.Close()
+
+	_, err := client.GetVersionDetails(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
+// Test UpdateVersionDetails
+
+func TestUpdateVersionDetails_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.AppStoreVersionResponse{},
+		},
+	)
+
+	defer ctx.Close()
+
+	now := time.Now()
+	err := client.UpdateVersionDetails(ctx.Context, testID, config.Version{
+		Copyright:           "2020 Me",
+		ReleaseType:         config.ReleaseTypeScheduled,
+		EarliestReleaseDate: &now,
+	})
+	assert.NoError(t, err)
+}
+
+// Test UpdateIDFADeclaration
 // Test UpdateIDFADeclaration
 
 func TestUpdateIDFADeclaration_Happy(t *testing.T) {
@@ -586,6 +940,58 @@ func TestUpdateReviewDetails_ErrCreate(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// Test GetReviewDetails
+
+func TestGetReviewDetails_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.AppStoreReviewDetailResponse{
+				Data: asc.AppStoreReviewDetail{
+					Attributes: &asc.AppStoreReviewDetailAttributes{
+						ContactEmail:        asc.String("test@example.com"),
+						DemoAccountRequired: asc.Bool(true),
+						Notes:               asc.String("Notes"),
+					},
+					ID: "TEST",
+				},
+			},
+		},
+	)
+
+	defer ctx.Close()
+
+	details, err := client.GetReviewDetails(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.ReviewDetails{
+		Contact: &config.ContactPerson{
+			Email: "test@example.com",
+		},
+		DemoAccount: &config.DemoAccount{
+			Required: true,
+		},
+		Notes: "Notes",
+	}, details)
+}
+
+func TestGetReviewDetails_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+
+	defer ctx.Close()
+
+	details, err := client.GetReviewDetails(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Nil(t, details)
+}
+
 // Test EnablePhasedRelease
 
 func TestEnablePhasedRelease_Update(t *testing.T) {
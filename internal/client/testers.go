@@ -0,0 +1,381 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cidertool/asc-go/asc"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/internal/parallel"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+// maxPageSize is the most resources App Store Connect returns in a single page.
+const maxPageSize = 200
+
+// maxIncludedBetaGroups is the most beta groups App Store Connect includes with each beta tester.
+const maxIncludedBetaGroups = 50
+
+// Tester is a beta tester of an app, along with the names of the app's beta groups they belong to.
+type Tester struct {
+	// ID of the tester in App Store Connect, or empty if the tester doesn't exist yet.
+	ID        string
+	Email     string
+	FirstName string
+	LastName  string
+	Groups    []string
+}
+
+func (c *ascClient) ListBetaTesters(ctx *context.Context, appID string) ([]Tester, error) {
+	groupIDs, err := c.betaGroupIDs(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map of group IDs -> group names
+	var groupNames = make(map[string]string, len(groupIDs))
+
+	for name, id := range groupIDs {
+		groupNames[id] = name
+	}
+
+	var testers []Tester
+
+	query := asc.ListBetaTestersQuery{
+		FilterApps:      []string{appID},
+		Include:         []string{"betaGroups"},
+		Limit:           maxPageSize,
+		LimitBetaGroups: []string{strconv.Itoa(maxIncludedBetaGroups)},
+	}
+
+	for {
+		testersResp, _, err := c.client.TestFlight.ListBetaTesters(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tester := range testersResp.Data {
+			testers = append(testers, newTester(tester, groupNames))
+		}
+
+		if testersResp.Links.Next == nil || testersResp.Links.Next.Cursor() == "" {
+			break
+		}
+
+		query.Cursor = testersResp.Links.Next.Cursor()
+	}
+
+	ctx.Log.WithField("count", len(testers)).Debug("listed beta testers")
+
+	return testers, nil
+}
+
+func newTester(tester asc.BetaTester, groupNames map[string]string) Tester {
+	var t = Tester{ID: tester.ID}
+
+	if tester.Attributes != nil {
+		if tester.Attributes.Email != nil {
+			t.Email = string(*tester.Attributes.Email)
+		}
+
+		t.FirstName = stringValue(tester.Attributes.FirstName)
+		t.LastName = stringValue(tester.Attributes.LastName)
+	}
+
+	if tester.Relationships != nil && tester.Relationships.BetaGroups != nil {
+		for _, rel := range tester.Relationships.BetaGroups.Data {
+			// Testers can belong to the beta groups of other apps.
+			if name, ok := groupNames[rel.ID]; ok {
+				t.Groups = append(t.Groups, name)
+			}
+		}
+	}
+
+	return t
+}
+
+// BetaTesterStateInvited is the state of a beta tester who hasn't accepted their invitation yet.
+const BetaTesterStateInvited = "INVITED"
+
+// TesterActivity is the recent activity of a beta tester of an app.
+type TesterActivity struct {
+	// State of the tester, such as INVITED or ACCEPTED. Empty if App Store Connect doesn't report one.
+	State string
+	// Number of sessions the tester had with the app over the requested period.
+	Sessions int
+}
+
+// usagePeriods are the periods App Store Connect reports beta tester usage over, from shortest to longest.
+var usagePeriods = []struct {
+	Days   int
+	Period string
+}{
+	{Days: 7, Period: "P7D"},
+	{Days: 30, Period: "P30D"},
+	{Days: 90, Period: "P90D"},
+	{Days: 365, Period: "P365D"},
+}
+
+type errUsagePeriodTooLong struct {
+	Days int
+}
+
+func (e errUsagePeriodTooLong) Error() string {
+	return fmt.Sprintf("beta tester usage is reported for up to %d days, not %d", usagePeriods[len(usagePeriods)-1].Days, e.Days)
+}
+
+// betaTesterStatesResponse is the part of a list of beta testers that reports their state, which
+// the version of the API client in use doesn't decode.
+type betaTesterStatesResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes *struct {
+			State *string `json:"state,omitempty"`
+		} `json:"attributes,omitempty"`
+	} `json:"data"`
+	Links asc.PagedDocumentLinks `json:"links"`
+}
+
+// betaTesterUsagesResponse is a page of the beta tester usage metrics of an app, grouped by tester.
+type betaTesterUsagesResponse struct {
+	Data []struct {
+		DataPoints struct {
+			Values struct {
+				SessionCount int `json:"sessionCount"`
+			} `json:"values"`
+		} `json:"dataPoints"`
+		Dimensions struct {
+			BetaTesters struct {
+				Data string `json:"data"`
+			} `json:"betaTesters"`
+		} `json:"dimensions"`
+	} `json:"data"`
+	Links asc.PagedDocumentLinks `json:"links"`
+}
+
+func (c *ascClient) ListBetaTesterActivity(ctx *context.Context, appID string, days int) (map[string]TesterActivity, error) {
+	var activity = make(map[string]TesterActivity)
+
+	query := url.Values{}
+	query.Set("filter[apps]", appID)
+	query.Set("fields[betaTesters]", "state")
+	query.Set("limit", strconv.Itoa(maxPageSize))
+
+	for ref := (&asc.Reference{URL: url.URL{Path: "betaTesters", RawQuery: query.Encode()}}); ref != nil; {
+		var resp betaTesterStatesResponse
+		if _, err := c.client.FollowReference(ctx, ref, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, tester := range resp.Data {
+			if tester.Attributes != nil {
+				activity[tester.ID] = TesterActivity{State: stringValue(tester.Attributes.State)}
+			}
+		}
+
+		ref = resp.Links.Next
+	}
+
+	if days <= 0 {
+		return activity, nil
+	}
+
+	period, err := usagePeriod(days)
+	if err != nil {
+		return nil, err
+	}
+
+	query = url.Values{}
+	query.Set("groupBy", "betaTesters")
+	query.Set("period", period)
+	query.Set("limit", strconv.Itoa(maxPageSize))
+
+	for ref := (&asc.Reference{URL: url.URL{Path: fmt.Sprintf("apps/%s/metrics/betaTesterUsages", appID), RawQuery: query.Encode()}}); ref != nil; {
+		var resp betaTesterUsagesResponse
+		if _, err := c.client.FollowReference(ctx, ref, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, usage := range resp.Data {
+			id := usage.Dimensions.BetaTesters.Data
+			tester := activity[id]
+			tester.Sessions += usage.DataPoints.Values.SessionCount
+			activity[id] = tester
+		}
+
+		ref = resp.Links.Next
+	}
+
+	ctx.Log.WithField("period", period).Debug("listed beta tester activity")
+
+	return activity, nil
+}
+
+// usagePeriod returns the shortest period App Store Connect reports beta tester usage over that
+// covers the given number of days.
+func usagePeriod(days int) (string, error) {
+	for _, p := range usagePeriods {
+		if days <= p.Days {
+			return p.Period, nil
+		}
+	}
+
+	return "", errUsagePeriodTooLong{Days: days}
+}
+
+func (c *ascClient) AddBetaTesters(ctx *context.Context, appID string, testers []Tester) error {
+	if len(testers) == 0 {
+		return nil
+	}
+
+	groupIDs, err := c.betaGroupIDs(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	// Look up every group before changing anything, so an unknown group doesn't leave the testers half updated.
+	var ids = make([][]string, len(testers))
+
+	for i, tester := range testers {
+		if ids[i], err = lookupBetaGroupIDs(groupIDs, tester.Groups); err != nil {
+			return err
+		}
+	}
+
+	var g = parallel.New(ctx.MaxProcesses)
+
+	for i := range testers {
+		tester := testers[i]
+		ids := ids[i]
+
+		g.Go(func() error {
+			fields := log.Fields{"email": tester.Email, "groups": strings.Join(tester.Groups, ", ")}
+
+			if tester.ID == "" {
+				ctx.Log.WithFields(fields).Debug("create beta tester")
+
+				_, _, err := c.client.TestFlight.CreateBetaTester(ctx, asc.BetaTesterCreateRequestAttributes{
+					Email:     asc.Email(tester.Email),
+					FirstName: &tester.FirstName,
+					LastName:  &tester.LastName,
+				}, ids, nil)
+
+				return err
+			}
+
+			ctx.Log.WithFields(fields).Debug("add beta tester to groups")
+
+			_, err := c.client.TestFlight.AddBetaTesterToBetaGroups(ctx, tester.ID, ids)
+
+			return err
+		})
+	}
+
+	return g.Wait()
+}
+
+func (c *ascClient) RemoveBetaTesters(ctx *context.Context, appID string, testers []Tester) error {
+	if len(testers) == 0 {
+		return nil
+	}
+
+	groupIDs, err := c.betaGroupIDs(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	var ids = make([][]string, len(testers))
+
+	for i, tester := range testers {
+		if ids[i], err = lookupBetaGroupIDs(groupIDs, tester.Groups); err != nil {
+			return err
+		}
+	}
+
+	var g = parallel.New(ctx.MaxProcesses)
+
+	for i := range testers {
+		tester := testers[i]
+		ids := ids[i]
+
+		g.Go(func() error {
+			if len(ids) == 0 {
+				ctx.Log.WithField("email", tester.Email).Debug("remove beta tester from app")
+
+				_, err := c.client.TestFlight.RemoveSingleBetaTesterAccessApps(ctx, tester.ID, []string{appID})
+
+				return err
+			}
+
+			ctx.Log.
+				WithFields(log.Fields{"email": tester.Email, "groups": strings.Join(tester.Groups, ", ")}).
+				Debug("remove beta tester from groups")
+
+			_, err := c.client.TestFlight.RemoveBetaTesterFromBetaGroups(ctx, tester.ID, ids)
+
+			return err
+		})
+	}
+
+	return g.Wait()
+}
+
+// betaGroupIDs returns a map of the names of an app's beta groups to their IDs.
+func (c *ascClient) betaGroupIDs(ctx *context.Context, appID string) (map[string]string, error) {
+	groupsResp, _, err := c.client.TestFlight.ListBetaGroups(ctx, &asc.ListBetaGroupsQuery{
+		FilterApp: []string{appID},
+		Limit:     maxPageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids = make(map[string]string, len(groupsResp.Data))
+
+	for _, group := range groupsResp.Data {
+		if group.Attributes == nil || group.Attributes.Name == nil {
+			continue
+		}
+
+		ids[*group.Attributes.Name] = group.ID
+	}
+
+	return ids, nil
+}
+
+func lookupBetaGroupIDs(groupIDs map[string]string, names []string) ([]string, error) {
+	var ids = make([]string, len(names))
+
+	for i, name := range names {
+		id, ok := groupIDs[name]
+		if !ok {
+			return nil, errBetaGroupNotFound{Name: name}
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,314 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cidertool/asc-go/asc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBetaGroupsResponse() response {
+	return response{
+		Response: asc.BetaGroupsResponse{
+			Data: []asc.BetaGroup{
+				{ID: "g1", Attributes: &asc.BetaGroupAttributes{Name: asc.String("QA")}},
+				{ID: "g2", Attributes: &asc.BetaGroupAttributes{Name: asc.String("Friends")}},
+				{ID: "g3"},
+			},
+		},
+	}
+}
+
+// Test ListBetaTesters
+
+func TestListBetaTesters_Happy(t *testing.T) {
+	t.Parallel()
+
+	email1 := asc.Email("a@example.com")
+	email2 := asc.Email("b@example.com")
+
+	ctx, client := newTestContext(
+		newTestBetaGroupsResponse(),
+		response{
+			Response: asc.BetaTestersResponse{
+				Data: []asc.BetaTester{
+					{
+						ID: "t1",
+						Attributes: &asc.BetaTesterAttributes{
+							Email:     &email1,
+							FirstName: asc.String("Ada"),
+							LastName:  asc.String("Lovelace"),
+						},
+						Relationships: &asc.BetaTesterRelationships{
+							BetaGroups: &asc.PagedRelationship{
+								Data: []asc.RelationshipData{{ID: "g1"}, {ID: "other"}, {ID: "g2"}},
+							},
+						},
+					},
+				},
+				Links: asc.PagedDocumentLinks{
+					Next: &asc.Reference{URL: url.URL{Path: "/v1/betaTesters", RawQuery: "cursor=next"}},
+				},
+			},
+		},
+		response{
+			Response: asc.BetaTestersResponse{
+				Data: []asc.BetaTester{
+					{ID: "t2", Attributes: &asc.BetaTesterAttributes{Email: &email2}},
+				},
+			},
+		},
+	)
+	defer ctx.Close()
+
+	testers, err := client.ListBetaTesters(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, []Tester{
+		{ID: "t1", Email: "a@example.com", FirstName: "Ada", LastName: "Lovelace", Groups: []string{"QA", "Friends"}},
+		{ID: "t2", Email: "b@example.com"},
+	}, testers)
+	assert.Equal(t, 3, ctx.CurrentResponseIndex)
+}
+
+func TestListBetaTesters_ErrGroups(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		StatusCode:  http.StatusNotFound,
+		RawResponse: `{}`,
+	})
+	defer ctx.Close()
+
+	_, err := client.ListBetaTesters(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
+func TestListBetaTesters_ErrTesters(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		newTestBetaGroupsResponse(),
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	_, err := client.ListBetaTesters(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
+// Test ListBetaTesterActivity
+
+func TestListBetaTesterActivity_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"t1","attributes":{"state":"INSTALLED"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/betaTesters","next":"https://api.appstoreconnect.apple.com/v1/betaTesters?cursor=next"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"t2","attributes":{"state":"INVITED"}},{"id":"t3"}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/betaTesters"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"dataPoints":{"values":{"sessionCount":3,"crashCount":0,"feedbackCount":1}},"dimensions":{"betaTesters":{"data":"t1"}}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/apps/TEST/metrics/betaTesterUsages"}}`,
+		},
+	)
+	defer ctx.Close()
+
+	activity, err := client.ListBetaTesterActivity(ctx.Context, testID, 45)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]TesterActivity{
+		"t1": {State: "INSTALLED", Sessions: 3},
+		"t2": {State: BetaTesterStateInvited},
+	}, activity)
+	assert.Equal(t, 3, ctx.CurrentResponseIndex)
+}
+
+func TestListBetaTesterActivity_NoUsage(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"t1","attributes":{"state":"INVITED"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/betaTesters"}}`,
+		},
+	)
+	defer ctx.Close()
+
+	activity, err := client.ListBetaTesterActivity(ctx.Context, testID, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]TesterActivity{
+		"t1": {State: BetaTesterStateInvited},
+	}, activity)
+}
+
+func TestListBetaTesterActivity_Err(t *testing.T) {
+	t.Parallel()
+
+	states := response{
+		RawResponse: `{"data":[],"links":{"self":"https://api.appstoreconnect.apple.com/v1/betaTesters"}}`,
+	}
+	notFound := response{
+		StatusCode:  http.StatusNotFound,
+		RawResponse: `{}`,
+	}
+
+	ctx, client := newTestContext(notFound)
+	defer ctx.Close()
+
+	_, err := client.ListBetaTesterActivity(ctx.Context, testID, 30)
+	assert.Error(t, err)
+
+	ctx.SetResponses(states, notFound)
+
+	_, err = client.ListBetaTesterActivity(ctx.Context, testID, 30)
+	assert.Error(t, err)
+
+	ctx.SetResponses(states)
+
+	_, err = client.ListBetaTesterActivity(ctx.Context, testID, 400)
+	assert.EqualError(t, err, "beta tester usage is reported for up to 365 days, not 400")
+}
+
+func TestUsagePeriod(t *testing.T) {
+	t.Parallel()
+
+	for days, expected := range map[int]string{1: "P7D", 7: "P7D", 8: "P30D", 90: "P90D", 365: "P365D"} {
+		period, err := usagePeriod(days)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, period)
+	}
+}
+
+// Test AddBetaTesters
+
+func TestAddBetaTesters_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		newTestBetaGroupsResponse(),
+		response{RawResponse: `{}`},
+		response{RawResponse: `{}`},
+	)
+	defer ctx.Close()
+
+	err := client.AddBetaTesters(ctx.Context, testID, []Tester{
+		{Email: "new@example.com", Groups: []string{"QA"}},
+		{ID: "t1", Email: "a@example.com", Groups: []string{"Friends"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, ctx.CurrentResponseIndex)
+}
+
+func TestAddBetaTesters_NoTesters(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+	defer ctx.Close()
+
+	err := client.AddBetaTesters(ctx.Context, testID, nil)
+	assert.NoError(t, err)
+}
+
+func TestAddBetaTesters_ErrGroupNotFound(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(newTestBetaGroupsResponse())
+	defer ctx.Close()
+
+	err := client.AddBetaTesters(ctx.Context, testID, []Tester{
+		{Email: "new@example.com", Groups: []string{"QA"}},
+		{Email: "other@example.com", Groups: []string{"Nope"}},
+	})
+	assert.EqualError(t, err, "beta group not found matching Nope")
+	assert.Equal(t, 1, ctx.CurrentResponseIndex)
+}
+
+func TestAddBetaTesters_ErrCreate(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		newTestBetaGroupsResponse(),
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.AddBetaTesters(ctx.Context, testID, []Tester{
+		{Email: "new@example.com", Groups: []string{"QA"}},
+	})
+	assert.Error(t, err)
+}
+
+// Test RemoveBetaTesters
+
+func TestRemoveBetaTesters_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		newTestBetaGroupsResponse(),
+		response{RawResponse: `{}`},
+		response{RawResponse: `{}`},
+	)
+	defer ctx.Close()
+
+	err := client.RemoveBetaTesters(ctx.Context, testID, []Tester{
+		{ID: "t1", Email: "a@example.com"},
+		{ID: "t2", Email: "b@example.com", Groups: []string{"QA", "Friends"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, ctx.CurrentResponseIndex)
+}
+
+func TestRemoveBetaTesters_NoTesters(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+	defer ctx.Close()
+
+	err := client.RemoveBetaTesters(ctx.Context, testID, nil)
+	assert.NoError(t, err)
+}
+
+func TestRemoveBetaTesters_ErrRemove(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		newTestBetaGroupsResponse(),
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.RemoveBetaTesters(ctx.Context, testID, []Tester{
+		{ID: "t1", Email: "a@example.com"},
+	})
+	assert.Error(t, err)
+}
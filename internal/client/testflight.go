@@ -21,6 +21,9 @@ along with Cider.  If not, see <http://www.gnu.org/licenses/>.
 package client
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/cidertool/asc-go/asc"
 	"github.com/cidertool/cider/internal/log"
 	"github.com/cidertool/cider/internal/parallel"
@@ -28,6 +31,58 @@ import (
 	"github.com/cidertool/cider/pkg/context"
 )
 
+// defaultMaxBetaRemovals is the number of beta testers and beta groups a release can remove
+// when the configuration doesn't set a maximum.
+const defaultMaxBetaRemovals = 10
+
+type errInvalidBetaGroupSync struct {
+	Group string
+	Sync  string
+}
+
+func (e errInvalidBetaGroupSync) Error() string {
+	return fmt.Sprintf("invalid sync mode %s for beta group %s", e.Sync, e.Group)
+}
+
+type errTooManyBetaRemovals struct {
+	Count int
+	Max   int
+}
+
+func (e errTooManyBetaRemovals) Error() string {
+	return fmt.Sprintf("syncing beta groups would remove %d beta testers and groups, more than the maximum of %d. raise maxBetaRemovals to allow it", e.Count, e.Max)
+}
+
+type errInternalBetaGroupNotFound struct {
+	Group string
+}
+
+func (e errInternalBetaGroupNotFound) Error() string {
+	return fmt.Sprintf("internal beta group %s must be created in App Store Connect before it can be used", e.Group)
+}
+
+type errBetaGroupInternalMismatch struct {
+	Group    string
+	Internal bool
+}
+
+func (e errBetaGroupInternalMismatch) Error() string {
+	if e.Internal {
+		return fmt.Sprintf("beta group %s is internal in App Store Connect, but not in the configuration", e.Group)
+	}
+
+	return fmt.Sprintf("beta group %s is internal in the configuration, but not in App Store Connect", e.Group)
+}
+
+type errBetaTestersNotOnTeam struct {
+	Group  string
+	Emails []string
+}
+
+func (e errBetaTestersNotOnTeam) Error() string {
+	return fmt.Sprintf("internal beta group %s lists testers who aren't members of the team: %s", e.Group, strings.Join(e.Emails, ", "))
+}
+
 func (c *ascClient) UpdateBetaAppLocalizations(ctx *context.Context, appID string, config config.TestflightLocalizations) error {
 	var g = parallel.New(ctx.MaxProcesses)
 
@@ -131,6 +186,31 @@ func betaAppLocalizationCreateRequestAttributes(locale string, locConfig config.
 	return attrs
 }
 
+func (c *ascClient) GetBetaAppLocalizations(ctx *context.Context, appID string) (config.TestflightLocalizations, error) {
+	locListResp, _, err := c.client.TestFlight.ListBetaAppLocalizationsForApp(ctx, appID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	locs := make(config.TestflightLocalizations)
+
+	for _, loc := range locListResp.Data {
+		if loc.Attributes == nil || loc.Attributes.Locale == nil {
+			continue
+		}
+
+		locs[*loc.Attributes.Locale] = config.TestflightLocalization{
+			Description:       stringValue(loc.Attributes.Description),
+			FeedbackEmail:     stringValue(loc.Attributes.FeedbackEmail),
+			MarketingURL:      stringValue(loc.Attributes.MarketingURL),
+			PrivacyPolicyURL:  stringValue(loc.Attributes.PrivacyPolicyURL),
+			TVOSPrivacyPolicy: stringValue(loc.Attributes.TVOSPrivacyPolicy),
+		}
+	}
+
+	return locs, nil
+}
+
 func (c *ascClient) UpdateBetaBuildDetails(ctx *context.Context, buildID string, config config.Testflight) error {
 	_, _, err := c.client.TestFlight.UpdateBuildBetaDetail(ctx, buildID, &config.EnableAutoNotify)
 
@@ -196,6 +276,27 @@ func (c *ascClient) UpdateBetaBuildLocalizations(ctx *context.Context, buildID s
 	return g.Wait()
 }
 
+func (c *ascClient) GetBetaBuildLocalizations(ctx *context.Context, buildID string) (config.TestflightLocalizations, error) {
+	locListResp, _, err := c.client.TestFlight.ListBetaBuildLocalizationsForBuild(ctx, buildID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	locs := make(config.TestflightLocalizations)
+
+	for _, loc := range locListResp.Data {
+		if loc.Attributes == nil || loc.Attributes.Locale == nil {
+			continue
+		}
+
+		locs[*loc.Attributes.Locale] = config.TestflightLocalization{
+			WhatsNew: stringValue(loc.Attributes.WhatsNew),
+		}
+	}
+
+	return locs, nil
+}
+
 func (c *ascClient) UpdateBetaLicenseAgreement(ctx *context.Context, appID string, config config.Testflight) error {
 	if config.LicenseAgreement == "" {
 		return nil
@@ -220,6 +321,10 @@ func (c *ascClient) AssignBetaGroups(ctx *context.Context, appID string, buildID
 		return nil
 	}
 
+	if err := c.checkInternalBetaTesters(ctx, groups); err != nil {
+		return err
+	}
+
 	existingGroupsResp, _, err := c.client.TestFlight.ListBetaGroups(ctx, &asc.ListBetaGroupsQuery{
 		FilterApp: []string{appID},
 	})
@@ -255,6 +360,10 @@ func (c *ascClient) AssignBetaGroups(ctx *context.Context, appID string, buildID
 		}
 
 		g.Go(func() error {
+			if isInternal := boolValue(group.Attributes.IsInternalGroup); isInternal != configGroup.Internal {
+				return errBetaGroupInternalMismatch{Group: name, Internal: isInternal}
+			}
+
 			ctx.Log.WithField("group", name).Debug("update beta group")
 
 			return c.updateBetaGroup(ctx, g, appID, group.ID, buildID, configGroup)
@@ -272,6 +381,10 @@ func (c *ascClient) AssignBetaGroups(ctx *context.Context, appID string, buildID
 		}
 
 		g.Go(func() error {
+			if group.Internal {
+				return errInternalBetaGroupNotFound{Group: group.Name}
+			}
+
 			ctx.Log.WithField("group", group.Name).Debug("create beta group")
 
 			return c.createBetaGroup(ctx, g, appID, buildID, group)
@@ -283,21 +396,33 @@ func (c *ascClient) AssignBetaGroups(ctx *context.Context, appID string, buildID
 
 func (c *ascClient) updateBetaGroup(ctx *context.Context, g parallel.Group, appID string, groupID string, buildID string, group config.BetaGroup) error {
 	g.Go(func() error {
-		_, _, err := c.client.TestFlight.UpdateBetaGroup(ctx, groupID, &asc.BetaGroupUpdateRequestAttributes{
-			FeedbackEnabled:        &group.FeedbackEnabled,
-			Name:                   &group.Name,
-			PublicLinkEnabled:      &group.EnablePublicLink,
-			PublicLinkLimit:        &group.PublicLinkLimit,
-			PublicLinkLimitEnabled: &group.EnablePublicLinkLimit,
-		})
+		attributes := asc.BetaGroupUpdateRequestAttributes{
+			FeedbackEnabled: &group.FeedbackEnabled,
+			Name:            &group.Name,
+		}
 
-		return err
-	})
-	g.Go(func() error {
-		_, err := c.client.TestFlight.AddBuildsToBetaGroup(ctx, groupID, []string{buildID})
+		// Internal groups don't have a public link.
+		if !group.Internal {
+			attributes.PublicLinkEnabled = &group.EnablePublicLink
+			attributes.PublicLinkLimit = &group.PublicLinkLimit
+			attributes.PublicLinkLimitEnabled = &group.EnablePublicLinkLimit
+		}
+
+		_, _, err := c.client.TestFlight.UpdateBetaGroup(ctx, groupID, &attributes)
 
 		return err
 	})
+
+	if group.Internal && group.HasAccessToAllBuilds {
+		ctx.Log.WithField("group", group.Name).Debug("group has access to all builds")
+	} else {
+		g.Go(func() error {
+			_, err := c.client.TestFlight.AddBuildsToBetaGroup(ctx, groupID, []string{buildID})
+
+			return err
+		})
+	}
+
 	g.Go(func() error {
 		return c.updateBetaTestersForGroup(ctx, g, appID, groupID, group.Testers)
 	})
@@ -305,6 +430,89 @@ func (c *ascClient) updateBetaGroup(ctx *context.Context, g parallel.Group, appI
 	return nil
 }
 
+// checkInternalBetaTesters makes sure the testers of internal beta groups are members of the team,
+// since App Store Connect only lets team members test through internal groups.
+func (c *ascClient) checkInternalBetaTesters(ctx *context.Context, groups []config.BetaGroup) error {
+	var emails []string
+
+	for _, group := range groups {
+		if !group.Internal {
+			continue
+		}
+
+		for _, tester := range group.Testers {
+			if tester.Email != "" {
+				emails = append(emails, tester.Email)
+			}
+		}
+	}
+
+	if len(emails) == 0 {
+		return nil
+	}
+
+	usersResp, _, err := c.client.Users.ListUsers(ctx, &asc.ListUsersQuery{
+		FilterUsername: emails,
+		Limit:          len(emails),
+	})
+	if err != nil {
+		return err
+	}
+
+	var members = make(map[string]bool, len(usersResp.Data))
+
+	for _, user := range usersResp.Data {
+		if user.Attributes != nil && user.Attributes.Username != nil {
+			members[strings.ToLower(*user.Attributes.Username)] = true
+		}
+	}
+
+	for _, group := range groups {
+		if !group.Internal {
+			continue
+		}
+
+		var outsiders []string
+
+		for _, tester := range group.Testers {
+			if tester.Email != "" && !members[strings.ToLower(tester.Email)] {
+				outsiders = append(outsiders, tester.Email)
+			}
+		}
+
+		if len(outsiders) > 0 {
+			return errBetaTestersNotOnTeam{Group: group.Name, Emails: outsiders}
+		}
+	}
+
+	return nil
+}
+
+func (c *ascClient) GetBetaGroupPublicLinks(ctx *context.Context, appID string) (map[string]string, error) {
+	groupsResp, _, err := c.client.TestFlight.ListBetaGroups(ctx, &asc.ListBetaGroupsQuery{
+		FilterApp:               []string{appID},
+		FilterPublicLinkEnabled: []string{"true"},
+		Limit:                   maxPageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var links = make(map[string]string)
+
+	for _, group := range groupsResp.Data {
+		if group.Attributes == nil || group.Attributes.Name == nil {
+			continue
+		}
+
+		if link := stringValue(group.Attributes.PublicLink); link != "" && boolValue(group.Attributes.PublicLinkEnabled) {
+			links[*group.Attributes.Name] = link
+		}
+	}
+
+	return links, nil
+}
+
 func (c *ascClient) createBetaGroup(ctx *context.Context, g parallel.Group, appID string, buildID string, group config.BetaGroup) error {
 	newGroupResp, _, err := c.client.TestFlight.CreateBetaGroup(ctx, asc.BetaGroupCreateRequestAttributes{
 		FeedbackEnabled:        &group.FeedbackEnabled,
@@ -450,6 +658,187 @@ func (c *ascClient) AssignBetaTesters(ctx *context.Context, appID string, buildI
 	return g.Wait()
 }
 
+func (c *ascClient) SyncBetaGroups(ctx *context.Context, appID string, testflight config.Testflight) error {
+	// Map of group names -> whether or not they exist in the configuration
+	var managed = make(map[string]bool, len(testflight.BetaGroups))
+
+	// Map of group names -> config.BetaGroup, for groups synced authoritatively
+	var authoritative = make(map[string]config.BetaGroup)
+
+	for _, group := range testflight.BetaGroups {
+		managed[group.Name] = true
+
+		switch group.Sync {
+		case "", config.BetaGroupSyncAdditive:
+		case config.BetaGroupSyncAuthoritative:
+			authoritative[group.Name] = group
+		default:
+			return errInvalidBetaGroupSync{Group: group.Name, Sync: string(group.Sync)}
+		}
+	}
+
+	if len(authoritative) == 0 && !testflight.DeleteUnmanagedBetaGroups {
+		return nil
+	}
+
+	var groups []asc.BetaGroup
+
+	query := asc.ListBetaGroupsQuery{
+		FilterApp: []string{appID},
+		Limit:     maxPageSize,
+	}
+
+	for {
+		groupsResp, _, err := c.client.TestFlight.ListBetaGroups(ctx, &query)
+		if err != nil {
+			return err
+		}
+
+		groups = append(groups, groupsResp.Data...)
+
+		if groupsResp.Links.Next == nil || groupsResp.Links.Next.Cursor() == "" {
+			break
+		}
+
+		query.Cursor = groupsResp.Links.Next.Cursor()
+	}
+
+	// Map of group IDs -> IDs of the testers to remove from them
+	var testerRemovals = make(map[string][]string)
+
+	var groupDeletions []string
+
+	var count int
+
+	for _, group := range groups {
+		if group.Attributes == nil || group.Attributes.Name == nil {
+			continue
+		}
+
+		name := *group.Attributes.Name
+
+		if !managed[name] {
+			if testflight.DeleteUnmanagedBetaGroups && !boolValue(group.Attributes.IsInternalGroup) {
+				ctx.Log.WithField("group", name).Warn("deleting unmanaged beta group")
+
+				groupDeletions = append(groupDeletions, group.ID)
+				count++
+			}
+
+			continue
+		}
+
+		groupConfig, ok := authoritative[name]
+		if !ok {
+			continue
+		}
+
+		testerIDs, err := c.unlistedBetaTesters(ctx, group.ID, name, groupConfig.Testers)
+		if err != nil {
+			return err
+		}
+
+		if len(testerIDs) > 0 {
+			testerRemovals[group.ID] = testerIDs
+			count += len(testerIDs)
+		}
+	}
+
+	if count == 0 {
+		ctx.Log.Debug("no beta testers or groups to remove")
+
+		return nil
+	}
+
+	max := testflight.MaxBetaRemovals
+	if max == 0 {
+		max = defaultMaxBetaRemovals
+	}
+
+	if count > max {
+		return errTooManyBetaRemovals{Count: count, Max: max}
+	}
+
+	if ctx.PreviewBetaRemovals {
+		ctx.Log.WithField("count", count).Warn("previewing beta removals, nothing was removed")
+
+		return nil
+	}
+
+	var g = parallel.New(ctx.MaxProcesses)
+
+	for groupID, testerIDs := range testerRemovals {
+		groupID, testerIDs := groupID, testerIDs
+
+		g.Go(func() error {
+			_, err := c.client.TestFlight.RemoveBetaTestersFromBetaGroup(ctx, groupID, testerIDs)
+
+			return err
+		})
+	}
+
+	for _, groupID := range groupDeletions {
+		groupID := groupID
+
+		g.Go(func() error {
+			_, err := c.client.TestFlight.DeleteBetaGroup(ctx, groupID)
+
+			return err
+		})
+	}
+
+	return g.Wait()
+}
+
+// unlistedBetaTesters returns the IDs of the testers of the group whose emails aren't in the given testers,
+// logging each of them.
+func (c *ascClient) unlistedBetaTesters(ctx *context.Context, groupID string, groupName string, testers []config.BetaTester) ([]string, error) {
+	var listed = make(map[string]bool, len(testers))
+
+	for _, tester := range testers {
+		listed[strings.ToLower(tester.Email)] = true
+	}
+
+	var testerIDs []string
+
+	query := asc.ListBetaTestersForBetaGroupQuery{
+		Limit: maxPageSize,
+	}
+
+	for {
+		testersResp, _, err := c.client.TestFlight.ListBetaTestersForBetaGroup(ctx, groupID, &query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tester := range testersResp.Data {
+			if tester.Attributes == nil || tester.Attributes.Email == nil {
+				continue
+			}
+
+			email := string(*tester.Attributes.Email)
+			if listed[strings.ToLower(email)] {
+				continue
+			}
+
+			ctx.Log.WithFields(log.Fields{
+				"group": groupName,
+				"email": email,
+			}).Warn("removing beta tester from group")
+
+			testerIDs = append(testerIDs, tester.ID)
+		}
+
+		if testersResp.Links.Next == nil || testersResp.Links.Next.Cursor() == "" {
+			break
+		}
+
+		query.Cursor = testersResp.Links.Next.Cursor()
+	}
+
+	return testerIDs, nil
+}
+
 func (c *ascClient) listBetaTesters(ctx *context.Context, appID string, config []config.BetaTester) ([]asc.BetaTester, error) {
 	emailFilters := make([]string, 0)
 	firstNameFilters := make([]string, 0)
@@ -496,6 +885,29 @@ func (c *ascClient) createBetaTester(ctx *context.Context, tester config.BetaTes
 	return err
 }
 
+func (c *ascClient) GetBetaReviewDetails(ctx *context.Context, appID string) (*config.ReviewDetails, error) {
+	detailsResp, _, err := c.client.TestFlight.GetBetaAppReviewDetailsForApp(ctx, appID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := detailsResp.Data.Attributes
+	if attrs == nil {
+		return nil, nil
+	}
+
+	return reviewDetails(
+		attrs.ContactEmail,
+		attrs.ContactFirstName,
+		attrs.ContactLastName,
+		attrs.ContactPhone,
+		attrs.DemoAccountName,
+		attrs.DemoAccountPassword,
+		attrs.DemoAccountRequired,
+		attrs.Notes,
+	), nil
+}
+
 func (c *ascClient) UpdateBetaReviewDetails(ctx *context.Context, appID string, config config.ReviewDetails) error {
 	detailsResp, _, err := c.client.TestFlight.GetBetaAppReviewDetailsForApp(ctx, appID, nil)
 	if err != nil {
@@ -533,3 +945,9 @@ func (c *ascClient) SubmitBetaApp(ctx *context.Context, buildID string) error {
 
 	return err
 }
+
+func (c *ascClient) NotifyBetaTesters(ctx *context.Context, buildID string) error {
+	_, _, err := c.client.TestFlight.CreateAvailableBuildNotification(ctx, buildID)
+
+	return err
+}
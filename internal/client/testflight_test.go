@@ -179,6 +179,53 @@ func TestUpdateBetaAppLocalizations_ErrCreate(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// Test GetBetaAppLocalizations
+
+func TestGetBetaAppLocalizations_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.BetaAppLocalizationsResponse{
+				Data: []asc.BetaAppLocalization{
+					{
+						Attributes: &asc.BetaAppLocalizationAttributes{
+							Description:   asc.String("Description"),
+							FeedbackEmail: asc.String("test@example.com"),
+							Locale:        asc.String("en-US"),
+						},
+					},
+				},
+			},
+		},
+	)
+	defer ctx.Close()
+
+	locs, err := client.GetBetaAppLocalizations(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, config.TestflightLocalizations{
+		"en-US": {
+			Description:   "Description",
+			FeedbackEmail: "test@example.com",
+		},
+	}, locs)
+}
+
+func TestGetBetaAppLocalizations_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	_, err := client.GetBetaAppLocalizations(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
 // Test UpdateBetaBuildDetails
 
 func TestUpdateBetaBuildDetails_Happy(t *testing.T) {
@@ -350,6 +397,51 @@ func TestUpdateBetaBuildLocalizations_ErrCreate(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// Test GetBetaBuildLocalizations
+
+func TestGetBetaBuildLocalizations_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.BetaBuildLocalizationsResponse{
+				Data: []asc.BetaBuildLocalization{
+					{
+						Attributes: &asc.BetaBuildLocalizationAttributes{
+							Locale:   asc.String("en-US"),
+							WhatsNew: asc.String("Bug fixes"),
+						},
+					},
+				},
+			},
+		},
+	)
+	defer ctx.Close()
+
+	locs, err := client.GetBetaBuildLocalizations(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, config.TestflightLocalizations{
+		"en-US": {
+			WhatsNew: "Bug fixes",
+		},
+	}, locs)
+}
+
+func TestGetBetaBuildLocalizations_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	_, err := client.GetBetaBuildLocalizations(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
 // Test UpdateBetaLicenseAgreement
 
 func TestUpdateBetaLicenseAgreement_Happy(t *testing.T) {
@@ -604,6 +696,160 @@ func TestAssignBetaGroups_ErrCreate(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestAssignBetaGroups_Internal(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.BetaGroupsResponse{
+				Data: []asc.BetaGroup{
+					{
+						ID: testID,
+						Attributes: &asc.BetaGroupAttributes{
+							Name:            asc.String(testID),
+							IsInternalGroup: asc.Bool(true),
+						},
+					},
+				},
+			},
+		},
+		response{
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.AssignBetaGroups(ctx.Context, testID, testID, []config.BetaGroup{
+		{Name: testID, Internal: true, HasAccessToAllBuilds: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ctx.CurrentResponseIndex)
+}
+
+func TestAssignBetaGroups_ErrInternalNotOnTeam(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.UsersResponse{
+				Data: []asc.User{
+					{
+						ID: testID,
+						Attributes: &asc.UserAttributes{
+							Username: asc.String("Member@test.com"),
+						},
+					},
+				},
+			},
+		},
+	)
+	defer ctx.Close()
+
+	err := client.AssignBetaGroups(ctx.Context, testID, testID, []config.BetaGroup{
+		{
+			Name:     testID,
+			Internal: true,
+			Testers: []config.BetaTester{
+				{Email: "member@test.com"},
+				{Email: "outsider@test.com"},
+			},
+		},
+	})
+	assert.EqualError(t, err, "internal beta group TEST lists testers who aren't members of the team: outsider@test.com")
+}
+
+func TestAssignBetaGroups_ErrInternalNotFound(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[]}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.AssignBetaGroups(ctx.Context, testID, testID, []config.BetaGroup{
+		{Name: testID, Internal: true},
+	})
+	assert.EqualError(t, err, "internal beta group TEST must be created in App Store Connect before it can be used")
+}
+
+func TestAssignBetaGroups_ErrInternalMismatch(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.BetaGroupsResponse{
+				Data: []asc.BetaGroup{
+					{
+						ID: testID,
+						Attributes: &asc.BetaGroupAttributes{
+							Name:            asc.String(testID),
+							IsInternalGroup: asc.Bool(true),
+						},
+					},
+				},
+			},
+		},
+	)
+	defer ctx.Close()
+
+	err := client.AssignBetaGroups(ctx.Context, testID, testID, []config.BetaGroup{
+		{Name: testID},
+	})
+	assert.EqualError(t, err, "beta group TEST is internal in App Store Connect, but not in the configuration")
+}
+
+// Test GetBetaGroupPublicLinks
+
+func TestGetBetaGroupPublicLinks_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		Response: asc.BetaGroupsResponse{
+			Data: []asc.BetaGroup{
+				{
+					ID: "1",
+					Attributes: &asc.BetaGroupAttributes{
+						Name:              asc.String("Public"),
+						PublicLink:        asc.String("https://testflight.apple.com/join/TEST"),
+						PublicLinkEnabled: asc.Bool(true),
+					},
+				},
+				{
+					ID: "2",
+					Attributes: &asc.BetaGroupAttributes{
+						Name:              asc.String("Disabled"),
+						PublicLink:        asc.String("https://testflight.apple.com/join/OLD"),
+						PublicLinkEnabled: asc.Bool(false),
+					},
+				},
+				{ID: "3"},
+			},
+		},
+	})
+	defer ctx.Close()
+
+	links, err := client.GetBetaGroupPublicLinks(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Public": "https://testflight.apple.com/join/TEST",
+	}, links)
+}
+
+func TestGetBetaGroupPublicLinks_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		StatusCode:  http.StatusNotFound,
+		RawResponse: `{}`,
+	})
+	defer ctx.Close()
+
+	_, err := client.GetBetaGroupPublicLinks(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
 // Test AssignBetaTesters
 
 func TestAssignBetaTesters_Happy(t *testing.T) {
@@ -718,6 +964,222 @@ func TestAssignBetaTesters_ErrAssign(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// Test SyncBetaGroups
+
+func newTestSyncBetaGroupsResponses() []response {
+	listedEmail := asc.Email("Listed@test.com")
+	unlistedEmail := asc.Email("unlisted@test.com")
+
+	return []response{
+		{
+			Response: asc.BetaGroupsResponse{
+				Data: []asc.BetaGroup{
+					{ID: "1", Attributes: &asc.BetaGroupAttributes{Name: asc.String("Authoritative")}},
+					{ID: "2", Attributes: &asc.BetaGroupAttributes{Name: asc.String("Additive")}},
+					{ID: "3", Attributes: &asc.BetaGroupAttributes{Name: asc.String("Unmanaged")}},
+					{ID: "4", Attributes: &asc.BetaGroupAttributes{Name: asc.String("Internal"), IsInternalGroup: asc.Bool(true)}},
+					{ID: "5"},
+				},
+			},
+		},
+		{
+			Response: asc.BetaTestersResponse{
+				Data: []asc.BetaTester{
+					{ID: "10", Attributes: &asc.BetaTesterAttributes{Email: &listedEmail}},
+					{ID: "11", Attributes: &asc.BetaTesterAttributes{Email: &unlistedEmail}},
+					{ID: "12"},
+				},
+			},
+		},
+	}
+}
+
+func newTestSyncBetaGroupsConfig() config.Testflight {
+	return config.Testflight{
+		BetaGroups: []config.BetaGroup{
+			{
+				Name:    "Authoritative",
+				Sync:    config.BetaGroupSyncAuthoritative,
+				Testers: []config.BetaTester{{Email: "listed@test.com"}},
+			},
+			{
+				Name: "Additive",
+				Sync: config.BetaGroupSyncAdditive,
+			},
+		},
+		DeleteUnmanagedBetaGroups: true,
+	}
+}
+
+func TestSyncBetaGroups_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(append(newTestSyncBetaGroupsResponses(),
+		response{RawResponse: `{}`},
+		response{RawResponse: `{}`},
+	)...)
+	defer ctx.Close()
+
+	err := client.SyncBetaGroups(ctx.Context, testID, newTestSyncBetaGroupsConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, ctx.CurrentResponseIndex)
+}
+
+func TestSyncBetaGroups_Additive(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+	defer ctx.Close()
+
+	err := client.SyncBetaGroups(ctx.Context, testID, config.Testflight{
+		BetaGroups: []config.BetaGroup{{Name: "Additive"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ctx.CurrentResponseIndex)
+}
+
+func TestSyncBetaGroups_Preview(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(newTestSyncBetaGroupsResponses()...)
+	defer ctx.Close()
+
+	ctx.Context.PreviewBetaRemovals = true
+	err := client.SyncBetaGroups(ctx.Context, testID, newTestSyncBetaGroupsConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ctx.CurrentResponseIndex)
+}
+
+func TestSyncBetaGroups_ErrTooManyRemovals(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(newTestSyncBetaGroupsResponses()...)
+	defer ctx.Close()
+
+	cfg := newTestSyncBetaGroupsConfig()
+	cfg.MaxBetaRemovals = 1
+	err := client.SyncBetaGroups(ctx.Context, testID, cfg)
+	assert.EqualError(t, err, "syncing beta groups would remove 2 beta testers and groups, more than the maximum of 1. raise maxBetaRemovals to allow it")
+	assert.Equal(t, 2, ctx.CurrentResponseIndex)
+}
+
+func TestSyncBetaGroups_Paged(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{"data":[{"id":"1","attributes":{"name":"Authoritative"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/betaGroups","next":"https://api.appstoreconnect.apple.com/v1/betaGroups?cursor=groups"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"3","attributes":{"name":"Unmanaged"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/betaGroups?cursor=groups"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"10","attributes":{"email":"first@test.com"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/betaGroups/1/betaTesters","next":"https://api.appstoreconnect.apple.com/v1/betaGroups/1/betaTesters?cursor=testers"}}`,
+		},
+		response{
+			RawResponse: `{"data":[{"id":"11","attributes":{"email":"second@test.com"}}],"links":{"self":"https://api.appstoreconnect.apple.com/v1/betaGroups/1/betaTesters?cursor=testers"}}`,
+		},
+	)
+	defer ctx.Close()
+
+	cfg := newTestSyncBetaGroupsConfig()
+	cfg.MaxBetaRemovals = 1
+	err := client.SyncBetaGroups(ctx.Context, testID, cfg)
+	assert.EqualError(t, err, "syncing beta groups would remove 3 beta testers and groups, more than the maximum of 1. raise maxBetaRemovals to allow it")
+	assert.Equal(t, 4, ctx.CurrentResponseIndex)
+}
+
+func TestSyncBetaGroups_ErrInvalidSync(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext()
+	defer ctx.Close()
+
+	err := client.SyncBetaGroups(ctx.Context, testID, config.Testflight{
+		BetaGroups: []config.BetaGroup{{Name: "Group", Sync: "sometimes"}},
+	})
+	assert.EqualError(t, err, "invalid sync mode sometimes for beta group Group")
+}
+
+func TestSyncBetaGroups_ErrList(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(response{
+		StatusCode:  http.StatusNotFound,
+		RawResponse: `{}`,
+	})
+	defer ctx.Close()
+
+	err := client.SyncBetaGroups(ctx.Context, testID, newTestSyncBetaGroupsConfig())
+	assert.Error(t, err)
+
+	ctx.SetResponses(newTestSyncBetaGroupsResponses()[0], response{
+		StatusCode:  http.StatusNotFound,
+		RawResponse: `{}`,
+	})
+
+	err = client.SyncBetaGroups(ctx.Context, testID, newTestSyncBetaGroupsConfig())
+	assert.Error(t, err)
+}
+
+func TestSyncBetaGroups_ErrRemove(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(append(newTestSyncBetaGroupsResponses(),
+		response{StatusCode: http.StatusNotFound, RawResponse: `{}`},
+		response{StatusCode: http.StatusNotFound, RawResponse: `{}`},
+	)...)
+	defer ctx.Close()
+
+	err := client.SyncBetaGroups(ctx.Context, testID, newTestSyncBetaGroupsConfig())
+	assert.Error(t, err)
+}
+
+// Test GetBetaReviewDetails
+
+func TestGetBetaReviewDetails_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			Response: asc.BetaAppReviewDetailResponse{
+				Data: asc.BetaAppReviewDetail{
+					Attributes: &asc.BetaAppReviewDetailAttributes{
+						ContactEmail: asc.String("test@example.com"),
+						Notes:        asc.String("Notes"),
+					},
+					ID: "TEST",
+				},
+			},
+		},
+	)
+	defer ctx.Close()
+
+	details, err := client.GetBetaReviewDetails(ctx.Context, testID)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.ReviewDetails{
+		Contact: &config.ContactPerson{
+			Email: "test@example.com",
+		},
+		Notes: "Notes",
+	}, details)
+}
+
+func TestGetBetaReviewDetails_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	_, err := client.GetBetaReviewDetails(ctx.Context, testID)
+	assert.Error(t, err)
+}
+
 // Test UpdateBetaReviewDetails
 
 func TestUpdateBetaReviewDetails_Happy(t *testing.T) {
@@ -806,3 +1268,34 @@ func TestSubmitBetaApp_Err(t *testing.T) {
 	err := client.SubmitBetaApp(ctx.Context, testID)
 	assert.Error(t, err)
 }
+
+// Test NotifyBetaTesters
+
+func TestNotifyBetaTesters_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.NotifyBetaTesters(ctx.Context, testID)
+	assert.NoError(t, err)
+}
+
+func TestNotifyBetaTesters_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx, client := newTestContext(
+		response{
+			StatusCode:  http.StatusNotFound,
+			RawResponse: `{}`,
+		},
+	)
+	defer ctx.Close()
+
+	err := client.NotifyBetaTesters(ctx.Context, testID)
+	assert.Error(t, err)
+}
@@ -24,6 +24,7 @@ package defaults
 import (
 	"fmt"
 
+	"github.com/cidertool/cider/internal/pipe/testflight"
 	"github.com/cidertool/cider/pkg/context"
 )
 
@@ -38,4 +39,6 @@ type Defaulter interface {
 
 // Defaulters is the list of defaulters
 // nolint: gochecknoglobals
-var Defaulters = []Defaulter{}
+var Defaulters = []Defaulter{
+	testflight.Pipe{},
+}
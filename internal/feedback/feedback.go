@@ -0,0 +1,342 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package feedback writes the feedback beta testers submit through TestFlight to a directory, and
+// keeps track of how far previous exports got.
+package feedback
+
+import (
+	stdcontext "context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+)
+
+const (
+	// JSONFile is the name of the file an export lists its feedback in as JSON.
+	JSONFile = "feedback.json"
+	// CSVFile is the name of the file an export lists its feedback in as CSV.
+	CSVFile = "feedback.csv"
+	// ScreenshotsDirectory is the directory of an export the screenshots of its feedback are saved in.
+	ScreenshotsDirectory = "screenshots"
+	// defaultScreenshotExtension is used for screenshots whose URL has no file extension.
+	defaultScreenshotExtension = ".png"
+	// screenshotSeparator separates the screenshots of an entry within the screenshots column.
+	screenshotSeparator = ";"
+)
+
+// Header is the first row of the CSV files written by WriteCSV.
+var Header = []string{"app", "id", "kind", "created date", "build", "tester", "device model", "os version", "comment", "screenshots"}
+
+// ErrDownloadFailed happens when a screenshot can't be downloaded.
+type ErrDownloadFailed struct {
+	URL    string
+	Status int
+}
+
+func (e ErrDownloadFailed) Error() string {
+	return fmt.Sprintf("failed to download %s: %s", e.URL, http.StatusText(e.Status))
+}
+
+// ErrInvalidDate happens when a date can't be parsed.
+type ErrInvalidDate struct {
+	Value string
+}
+
+func (e ErrInvalidDate) Error() string {
+	return fmt.Sprintf("invalid date %s, expected a day like 2006-01-02 or an RFC 3339 time", strconv.Quote(e.Value))
+}
+
+// Entry is a feedback submission of a beta tester of an app, as written to an export.
+type Entry struct {
+	// Bundle ID of the app.
+	App         string    `json:"app"`
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	CreatedDate time.Time `json:"createdDate"`
+	Build       string    `json:"build,omitempty"`
+	Tester      string    `json:"tester,omitempty"`
+	DeviceModel string    `json:"deviceModel,omitempty"`
+	OSVersion   string    `json:"osVersion,omitempty"`
+	Comment     string    `json:"comment,omitempty"`
+	// Paths of the screenshots attached to the submission, relative to the export directory.
+	Screenshots []string `json:"screenshots,omitempty"`
+}
+
+// NewEntry returns the entry for feedback submitted to the app with the given bundle ID. Its screenshots
+// are left out until they are downloaded.
+func NewEntry(app string, feedback client.BetaFeedback) Entry {
+	return Entry{
+		App:         app,
+		ID:          feedback.ID,
+		Kind:        feedback.Kind,
+		CreatedDate: feedback.CreatedDate,
+		Build:       feedback.Build,
+		Tester:      feedback.Tester,
+		DeviceModel: feedback.DeviceModel,
+		OSVersion:   feedback.OSVersion,
+		Comment:     feedback.Comment,
+	}
+}
+
+// ScreenshotPath returns the path, relative to the export directory, that the screenshot of the entry at
+// the given index and URL is saved to.
+func ScreenshotPath(entry Entry, index int, rawURL string) string {
+	ext := defaultScreenshotExtension
+
+	if u, err := url.Parse(rawURL); err == nil && path.Ext(u.Path) != "" {
+		ext = path.Ext(u.Path)
+	}
+
+	return filepath.Join(ScreenshotsDirectory, fmt.Sprintf("%s-%s-%d%s", entry.App, entry.ID, index+1, ext))
+}
+
+// Download saves the file at the URL to the given path, unless a file was already saved there.
+func Download(ctx stdcontext.Context, rawURL, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrDownloadFailed{URL: rawURL, Status: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, body, 0600)
+}
+
+// Load returns the entries of the export in the directory, or none if nothing was exported there yet.
+func Load(dir string) ([]Entry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, JSONFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Save writes the entries to both the JSON and the CSV file of the export in the directory.
+func Save(dir string, entries []Entry) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	var files = []struct {
+		Name  string
+		Write func(io.Writer, []Entry) error
+	}{
+		{Name: JSONFile, Write: WriteJSON},
+		{Name: CSVFile, Write: WriteCSV},
+	}
+
+	for _, file := range files {
+		var b strings.Builder
+		if err := file.Write(&b, entries); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, file.Name), []byte(b.String()), 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Key identifies the entry among the feedback of every app.
+func (e Entry) Key() string {
+	return e.App + "/" + e.ID
+}
+
+// Merge returns the existing entries along with the added ones, oldest first. Added entries replace
+// existing ones of the same app and ID.
+func Merge(existing, added []Entry) []Entry {
+	var merged []Entry
+
+	var index = make(map[string]int)
+
+	for _, entries := range [][]Entry{existing, added} {
+		for _, entry := range entries {
+			key := entry.Key()
+			if i, ok := index[key]; ok {
+				merged[i] = entry
+
+				continue
+			}
+
+			index[key] = len(merged)
+			merged = append(merged, entry)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].CreatedDate.Before(merged[j].CreatedDate)
+	})
+
+	return merged
+}
+
+// WriteJSON writes the entries as an indented JSON array.
+func WriteJSON(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(entries)
+}
+
+// WriteCSV writes the entries as CSV, with Header as the first row. Screenshots are separated by semicolons.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(Header); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := writer.Write([]string{
+			entry.App,
+			entry.ID,
+			entry.Kind,
+			entry.CreatedDate.Format(time.RFC3339),
+			entry.Build,
+			entry.Tester,
+			entry.DeviceModel,
+			entry.OSVersion,
+			entry.Comment,
+			strings.Join(entry.Screenshots, screenshotSeparator),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// Cursor maps the bundle IDs of apps to the creation date of the newest feedback exported for them,
+// so later exports only fetch feedback from then on. Feedback created at that same time is fetched
+// again, since more of it may have been submitted after the export, and callers skip what they
+// already exported.
+type Cursor map[string]time.Time
+
+// ReadCursor reads the cursor file at the path, or returns an empty cursor if there is no file yet.
+func ReadCursor(path string) (Cursor, error) {
+	var cursor = make(Cursor)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursor, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	return cursor, nil
+}
+
+// Since returns the time feedback for the app must be created at or after to be exported, which is the
+// later of the cursor's position for the app and the given time.
+func (c Cursor) Since(app string, since time.Time) time.Time {
+	if last, ok := c[app]; ok && last.After(since) {
+		return last
+	}
+
+	return since
+}
+
+// Advance moves the cursor past the given entries.
+func (c Cursor) Advance(entries []Entry) {
+	for _, entry := range entries {
+		if entry.CreatedDate.After(c[entry.App]) {
+			c[entry.App] = entry.CreatedDate
+		}
+	}
+}
+
+// Write saves the cursor to the file at the path.
+func (c Cursor) Write(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// ParseDate parses a date given on the command line, either as a day like 2006-01-02 or as an RFC 3339 time.
+func ParseDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate{Value: value}
+	}
+
+	return t, nil
+}
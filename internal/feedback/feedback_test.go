@@ -0,0 +1,223 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package feedback
+
+import (
+	stdcontext "context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntry(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	entry := NewEntry("com.test.TEST", client.BetaFeedback{
+		ID:          "s1",
+		Kind:        client.BetaFeedbackScreenshot,
+		CreatedDate: created,
+		Build:       "42",
+		Tester:      "test@example.com",
+		DeviceModel: "iPhone13,2",
+		OSVersion:   "14.4",
+		Comment:     "TEST",
+		Screenshots: []string{"https://example.com/s1.png"},
+	})
+	assert.Equal(t, Entry{
+		App:         "com.test.TEST",
+		ID:          "s1",
+		Kind:        client.BetaFeedbackScreenshot,
+		CreatedDate: created,
+		Build:       "42",
+		Tester:      "test@example.com",
+		DeviceModel: "iPhone13,2",
+		OSVersion:   "14.4",
+		Comment:     "TEST",
+	}, entry)
+}
+
+func TestScreenshotPath(t *testing.T) {
+	t.Parallel()
+
+	entry := Entry{App: "com.test.TEST", ID: "s1"}
+
+	assert.Equal(t, filepath.Join("screenshots", "com.test.TEST-s1-1.jpg"), ScreenshotPath(entry, 0, "https://example.com/a/b.jpg?token=1"))
+	assert.Equal(t, filepath.Join("screenshots", "com.test.TEST-s1-2.png"), ScreenshotPath(entry, 1, "https://example.com/a/b"))
+}
+
+func TestDownload(t *testing.T) {
+	t.Parallel()
+
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("TEST"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), ScreenshotsDirectory, "s1.png")
+
+	err := Download(stdcontext.Background(), server.URL+"/s1.png", path)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "TEST", string(data))
+
+	// Screenshots that were already saved aren't downloaded again.
+	err = Download(stdcontext.Background(), server.URL+"/s1.png", path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	err = Download(stdcontext.Background(), server.URL+"/missing.png", filepath.Join(t.TempDir(), "missing.png"))
+	assert.EqualError(t, err, ErrDownloadFailed{URL: server.URL + "/missing.png", Status: http.StatusNotFound}.Error())
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "feedback")
+
+	entries, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entries = []Entry{
+		{
+			App:         "com.test.TEST",
+			ID:          "s1",
+			Kind:        client.BetaFeedbackScreenshot,
+			CreatedDate: time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC),
+			Comment:     "Says \"hi\", twice",
+			Screenshots: []string{"screenshots/a.png", "screenshots/b.png"},
+		},
+	}
+
+	err = Save(dir, entries)
+	assert.NoError(t, err)
+
+	loaded, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, entries, loaded)
+
+	data, err := os.ReadFile(filepath.Join(dir, CSVFile))
+	assert.NoError(t, err)
+	assert.Equal(t, `app,id,kind,created date,build,tester,device model,os version,comment,screenshots
+com.test.TEST,s1,screenshot,2021-03-01T10:00:00Z,,,,,"Says ""hi"", twice",screenshots/a.png;screenshots/b.png
+`, string(data))
+}
+
+func TestWriteJSON_Empty(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+
+	err := WriteJSON(&b, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", b.String())
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2021, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	merged := Merge([]Entry{
+		{App: "a", ID: "1", CreatedDate: day(1)},
+		{App: "a", ID: "3", CreatedDate: day(3), Comment: "old"},
+	}, []Entry{
+		{App: "a", ID: "3", CreatedDate: day(3), Comment: "new"},
+		{App: "b", ID: "1", CreatedDate: day(2)},
+	})
+	assert.Equal(t, []Entry{
+		{App: "a", ID: "1", CreatedDate: day(1)},
+		{App: "b", ID: "1", CreatedDate: day(2)},
+		{App: "a", ID: "3", CreatedDate: day(3), Comment: "new"},
+	}, merged)
+}
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cursor.json")
+	march := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	cursor, err := ReadCursor(path)
+	assert.NoError(t, err)
+	assert.Empty(t, cursor)
+	assert.Equal(t, march, cursor.Since("a", march))
+
+	cursor.Advance([]Entry{
+		{App: "a", CreatedDate: april},
+		{App: "a", CreatedDate: march},
+		{App: "b", CreatedDate: march},
+	})
+	assert.Equal(t, april, cursor.Since("a", march))
+	assert.Equal(t, april, cursor.Since("b", april))
+
+	err = cursor.Write(path)
+	assert.NoError(t, err)
+
+	read, err := ReadCursor(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Cursor{"a": april, "b": march}, read)
+
+	err = os.WriteFile(path, []byte("{"), 0600)
+	assert.NoError(t, err)
+
+	_, err = ReadCursor(path)
+	assert.Error(t, err)
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	date, err := ParseDate("2021-03-01")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC), date)
+
+	date, err = ParseDate("2021-03-01T10:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC), date)
+
+	_, err = ParseDate("yesterday")
+	assert.EqualError(t, err, ErrInvalidDate{Value: "yesterday"}.Error())
+}
@@ -49,6 +49,15 @@ func (e ErrWrongRef) Error() string {
 // ErrNoTag happens if the underlying git repository doesn't contain any tags.
 var ErrNoTag = errors.New("git doesn't contain any tags")
 
+// ErrNoMatchingTag happens if the underlying git repository doesn't contain any tags matching a pattern.
+type ErrNoMatchingTag struct {
+	Pattern string
+}
+
+func (e ErrNoMatchingTag) Error() string {
+	return fmt.Sprintf("git doesn't contain any tags matching %s", e.Pattern)
+}
+
 // ErrNotRepository happens if you try to run Cider against a folder
 // which is not a git repository.
 type ErrNotRepository struct {
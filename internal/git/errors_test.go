@@ -42,6 +42,14 @@ func TestErrWrongRefMessage(t *testing.T) {
 	assert.Equal(t, expected, err.Error())
 }
 
+func TestErrNoMatchingTagMessage(t *testing.T) {
+	t.Parallel()
+
+	err := ErrNoMatchingTag{"TEST/*"}
+	expected := "git doesn't contain any tags matching TEST/*"
+	assert.Equal(t, expected, err.Error())
+}
+
 func TestErrNotRepositoryMessage(t *testing.T) {
 	t.Parallel()
 
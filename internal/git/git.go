@@ -124,6 +124,70 @@ func (git *Git) ShowRef(spec, ref string) (string, error) {
 	return git.SanitizeProcess(git.Run("show", fmt.Sprintf("--format=%s", spec), ref, "--quiet"))
 }
 
+// LatestTag returns the most recent tag reachable from HEAD. If pattern is not empty, only tags
+// matching the glob pattern are considered. It returns ErrNoTag, or ErrNoMatchingTag if pattern
+// is not empty, when there is no such tag.
+func (git *Git) LatestTag(pattern string) (string, error) {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if pattern != "" {
+		args = append(args, "--match", pattern)
+	}
+
+	proc, err := git.Run(args...)
+	if err != nil && proc != nil && isNoTagError(proc.Stderr) {
+		if pattern != "" {
+			return "", ErrNoMatchingTag{Pattern: pattern}
+		}
+
+		return "", ErrNoTag
+	}
+
+	return git.SanitizeProcess(proc, err)
+}
+
+// isNoTagError reports whether the output of a failed git describe means no tag could describe HEAD.
+func isNoTagError(stderr string) bool {
+	return strings.Contains(stderr, "No names found") || strings.Contains(stderr, "No tags can describe")
+}
+
+// CommitMessagesSince returns the full messages of the commits reachable from HEAD but not from
+// the given ref, newest first. Every commit reachable from HEAD is included if ref is empty.
+func (git *Git) CommitMessagesSince(ref string) ([]string, error) {
+	rng := "HEAD"
+	if ref != "" {
+		rng = ref + "..HEAD"
+	}
+
+	proc, err := git.Run("log", "--format=%B%x00", rng)
+	if _, err := git.SanitizeProcess(proc, err); err != nil {
+		return nil, err
+	}
+
+	var messages []string
+
+	for _, message := range strings.Split(proc.Stdout, "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages, nil
+}
+
+// CreateTag creates an annotated tag with the given name and message pointing to HEAD.
+func (git *Git) CreateTag(name, message string) error {
+	_, err := git.SanitizeProcess(git.Run("tag", "--annotate", name, "--message", message))
+
+	return err
+}
+
+// PushTag pushes the given tag to the remote.
+func (git *Git) PushTag(remote, name string) error {
+	_, err := git.SanitizeProcess(git.Run("push", remote, "refs/tags/"+name))
+
+	return err
+}
+
 // ExtractRepoFromConfig gets the repo name from the Git config.
 func (git *Git) ExtractRepoFromConfig() (result Repo, err error) {
 	if !git.IsRepo() {
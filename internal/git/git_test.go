@@ -110,6 +110,73 @@ func TestShowRef(t *testing.T) {
 	assert.Equal(t, expected, got)
 }
 
+func TestLatestTag(t *testing.T) {
+	t.Parallel()
+
+	client := newMockGit(
+		t,
+		shelltest.Command{Stdout: "v1.0.0\n"},
+		shelltest.Command{Stdout: "ios-app/v2.0.0\n"},
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: No names found, cannot describe anything.\n"},
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: No tags can describe '4b825dc'.\n"},
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: not a git repository\n"},
+	)
+
+	tag, err := client.LatestTag("")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.0", tag)
+
+	tag, err = client.LatestTag("ios-app/*")
+	assert.NoError(t, err)
+	assert.Equal(t, "ios-app/v2.0.0", tag)
+
+	_, err = client.LatestTag("")
+	assert.Equal(t, ErrNoTag, err)
+
+	_, err = client.LatestTag("tv-app/*")
+	assert.Equal(t, ErrNoMatchingTag{Pattern: "tv-app/*"}, err)
+
+	_, err = client.LatestTag("")
+	assert.EqualError(t, err, "fatal: not a git repository")
+}
+
+func TestCommitMessagesSince(t *testing.T) {
+	t.Parallel()
+
+	client := newMockGit(
+		t,
+		shelltest.Command{Stdout: "feat: add widget\n\nBREAKING CHANGE: drops iOS 12\n\x00\nfix: crash\n\x00\n"},
+		shelltest.Command{Stdout: ""},
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: bad revision\n"},
+	)
+
+	messages, err := client.CommitMessagesSince("v1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"feat: add widget\n\nBREAKING CHANGE: drops iOS 12", "fix: crash"}, messages)
+
+	messages, err = client.CommitMessagesSince("")
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+
+	_, err = client.CommitMessagesSince("v9.9.9")
+	assert.EqualError(t, err, "fatal: bad revision")
+}
+
+func TestCreateAndPushTag(t *testing.T) {
+	t.Parallel()
+
+	client := newMockGit(
+		t,
+		shelltest.Command{},
+		shelltest.Command{},
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: tag already exists\n"},
+	)
+
+	assert.NoError(t, client.CreateTag("v1.1.0", "Release 1.1.0"))
+	assert.NoError(t, client.PushTag("origin", "v1.1.0"))
+	assert.EqualError(t, client.CreateTag("v1.1.0", "Release 1.1.0"), "fatal: tag already exists")
+}
+
 func TestExtractRemoteFromConfig_Happy(t *testing.T) {
 	t.Parallel()
 
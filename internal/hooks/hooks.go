@@ -0,0 +1,185 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package hooks runs user-configured commands around the release pipeline and its publish steps
+package hooks
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/shell"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/hashicorp/go-multierror"
+)
+
+// ErrHookFailed happens when a hook command exits unsuccessfully.
+type ErrHookFailed struct {
+	Phase   string
+	Command string
+	Err     error
+}
+
+func (e ErrHookFailed) Error() string {
+	return fmt.Sprintf("%s hook %q failed: %v", e.Phase, e.Command, e.Err)
+}
+
+func (e ErrHookFailed) Unwrap() error {
+	return e.Err
+}
+
+// ErrHookTimedOut happens when a hook command runs longer than its configured timeout.
+type ErrHookTimedOut struct {
+	Phase   string
+	Command string
+}
+
+func (e ErrHookTimedOut) Error() string {
+	return fmt.Sprintf("%s hook %q timed out", e.Phase, e.Command)
+}
+
+// Runner runs hooks. The zero value runs hooks in a login shell bound to the context.
+type Runner struct {
+	Shell shell.Shell
+}
+
+// Around runs the before hooks, then fn, and then either the after hooks or the onError hooks
+// depending on the result of fn. Skipped results count as successes. The extra environment is
+// added to the environment of every hook.
+func (r Runner) Around(ctx *context.Context, hooks *config.Hooks, extra context.Env, fn func() error) error {
+	if hooks == nil {
+		return fn()
+	}
+
+	if err := r.Run(ctx, "before", hooks.Before, Env(ctx, extra)); err != nil {
+		return err
+	}
+
+	err := fn()
+	if err != nil && !pipe.IsSkip(err) {
+		env := Env(ctx, extra)
+		env["CIDER_ERROR"] = err.Error()
+
+		if herr := r.Run(ctx, "onError", hooks.OnError, env); herr != nil {
+			return multierror.Append(err, herr)
+		}
+
+		return err
+	}
+
+	if herr := r.Run(ctx, "after", hooks.After, Env(ctx, extra)); herr != nil {
+		return herr
+	}
+
+	return err
+}
+
+// Run runs each hook in order with the given environment. Hooks that fail abort the run
+// unless they are configured to only warn.
+func (r Runner) Run(ctx *context.Context, phase string, hooks []config.Hook, env context.Env) error {
+	for _, hook := range hooks {
+		err := r.run(ctx, phase, hook, env)
+		if err == nil {
+			continue
+		}
+
+		if hook.FailurePolicy == config.HookFailurePolicyWarn {
+			ctx.Log.Warn(err.Error())
+
+			continue
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r Runner) run(ctx *context.Context, phase string, hook config.Hook, env context.Env) error {
+	hctx := *ctx
+
+	if hook.Timeout > 0 {
+		var cancel gocontext.CancelFunc
+
+		hctx.Context, cancel = gocontext.WithTimeout(ctx.Context, hook.Timeout)
+		defer cancel()
+	}
+
+	sh := r.Shell
+	if sh == nil {
+		sh = shell.New(&hctx)
+	}
+
+	cmd := sh.NewScript(hook.Command)
+	if hook.Dir != "" {
+		cmd.Dir = filepath.Join(ctx.CurrentDirectory, hook.Dir)
+	}
+
+	cmd.Env = append(env.Strings(), hook.Env...)
+
+	shell.StreamOutput(cmd, func(line string) {
+		ctx.Log.Info(line)
+	})
+
+	ctx.Log.WithField("cmd", hook.Command).Infof("running %s hook", phase)
+
+	_, err := sh.Exec(cmd)
+
+	if errors.Is(hctx.Err(), gocontext.DeadlineExceeded) && ctx.Err() == nil {
+		return ErrHookTimedOut{Phase: phase, Command: hook.Command}
+	} else if err != nil {
+		return ErrHookFailed{Phase: phase, Command: hook.Command, Err: err}
+	}
+
+	return nil
+}
+
+// Env returns the environment hooks are run with, which is the context environment
+// plus details about the release and any extra variables provided.
+func Env(ctx *context.Context, extra context.Env) context.Env {
+	env := ctx.Env.Copy()
+	env["CIDER_VERSION"] = ctx.Version
+	env["CIDER_BUILD"] = ctx.Build
+	env["CIDER_PUBLISH_MODE"] = ctx.PublishMode.String()
+	env["CIDER_GIT_TAG"] = ctx.Git.CurrentTag
+	env["CIDER_GIT_COMMIT"] = ctx.Git.FullCommit
+	env["CIDER_GIT_SHORT_COMMIT"] = ctx.Git.ShortCommit
+	env["CIDER_GIT_URL"] = ctx.Git.URL
+	env["CIDER_APPS"] = strings.Join(ctx.AppsToRelease, ",")
+
+	for k, v := range extra {
+		env[k] = v
+	}
+
+	return env
+}
+
+// AppEnv returns the extra environment for hooks configured on an app.
+func AppEnv(name string, app config.App) context.Env {
+	return context.Env{
+		"CIDER_APP_NAME":      name,
+		"CIDER_APP_BUNDLE_ID": app.BundleID,
+	}
+}
@@ -0,0 +1,202 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package hooks
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/shell/shelltest"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestError = errors.New("TEST")
+
+func newMockRunner(t *testing.T, ctx *context.Context, commands ...shelltest.Command) Runner {
+	t.Helper()
+
+	return Runner{
+		Shell: &shelltest.Shell{
+			T:        t,
+			Context:  ctx,
+			Commands: commands,
+		},
+	}
+}
+
+func newTestHooks() *config.Hooks {
+	return &config.Hooks{
+		Before:  []config.Hook{{Command: "echo before"}},
+		After:   []config.Hook{{Command: "echo after"}},
+		OnError: []config.Hook{{Command: "echo error"}},
+	}
+}
+
+func TestAround_NoHooks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	runner := newMockRunner(t, ctx)
+
+	var called bool
+	err := runner.Around(ctx, nil, nil, func() error {
+		called = true
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestAround_Success(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	runner := newMockRunner(t, ctx, shelltest.Command{Stdout: "before"}, shelltest.Command{Stdout: "after"})
+
+	var called bool
+	err := runner.Around(ctx, newTestHooks(), nil, func() error {
+		called = true
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestAround_Skip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	runner := newMockRunner(t, ctx, shelltest.Command{}, shelltest.Command{})
+
+	err := runner.Around(ctx, newTestHooks(), nil, func() error {
+		return pipe.ErrSkipSubmitEnabled
+	})
+	assert.True(t, pipe.IsSkip(err))
+}
+
+func TestAround_Error(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	runner := newMockRunner(t, ctx, shelltest.Command{}, shelltest.Command{})
+
+	err := runner.Around(ctx, newTestHooks(), nil, func() error {
+		return errTestError
+	})
+	assert.ErrorIs(t, err, errTestError)
+
+	runner = newMockRunner(t, ctx, shelltest.Command{}, shelltest.Command{ReturnCode: 1})
+	err = runner.Around(ctx, newTestHooks(), nil, func() error {
+		return errTestError
+	})
+	assert.ErrorIs(t, err, errTestError)
+
+	var herr ErrHookFailed
+	assert.ErrorAs(t, err, &herr)
+	assert.Equal(t, "onError", herr.Phase)
+}
+
+func TestAround_BeforeFails(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	runner := newMockRunner(t, ctx, shelltest.Command{ReturnCode: 1})
+
+	var called bool
+	err := runner.Around(ctx, newTestHooks(), nil, func() error {
+		called = true
+
+		return nil
+	})
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestRun_FailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	runner := newMockRunner(t, ctx, shelltest.Command{ReturnCode: 1}, shelltest.Command{})
+
+	err := runner.Run(ctx, "before", []config.Hook{
+		{Command: "false", FailurePolicy: config.HookFailurePolicyWarn},
+		{Command: "true"},
+	}, nil)
+	assert.NoError(t, err)
+
+	runner = newMockRunner(t, ctx, shelltest.Command{ReturnCode: 1})
+
+	err = runner.Run(ctx, "before", []config.Hook{
+		{Command: "false", FailurePolicy: config.HookFailurePolicyFail},
+		{Command: "true"},
+	}, nil)
+	assert.EqualError(t, err, `before hook "false" failed: 1`)
+}
+
+func TestRun_Timeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+
+	err := Runner{}.Run(ctx, "after", []config.Hook{
+		{Command: "exec sleep 5", Timeout: 10 * time.Millisecond},
+	}, nil)
+	assert.Equal(t, ErrHookTimedOut{Phase: "after", Command: "exec sleep 5"}, err)
+}
+
+func TestEnv(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.Env = context.Env{"HOME": "/home"}
+	ctx.Version = "1.0"
+	ctx.Build = "2"
+	ctx.PublishMode = context.PublishModeAppStore
+	ctx.Git = context.GitInfo{
+		CurrentTag:  "v1.0",
+		Commit:      "abcdef",
+		ShortCommit: "abc",
+		FullCommit:  "abcdef",
+		URL:         "https://github.com/cidertool/cider",
+	}
+	ctx.AppsToRelease = []string{"My App", "Your App"}
+
+	env := Env(ctx, AppEnv("My App", config.App{BundleID: "com.app.MyApp"}))
+	assert.Equal(t, context.Env{
+		"HOME":                   "/home",
+		"CIDER_VERSION":          "1.0",
+		"CIDER_BUILD":            "2",
+		"CIDER_PUBLISH_MODE":     "appstore",
+		"CIDER_GIT_TAG":          "v1.0",
+		"CIDER_GIT_COMMIT":       "abcdef",
+		"CIDER_GIT_SHORT_COMMIT": "abc",
+		"CIDER_GIT_URL":          "https://github.com/cidertool/cider",
+		"CIDER_APPS":             "My App,Your App",
+		"CIDER_APP_NAME":         "My App",
+		"CIDER_APP_BUNDLE_ID":    "com.app.MyApp",
+	}, env)
+}
@@ -22,6 +22,7 @@ along with Cider.  If not, see <http://www.gnu.org/licenses/>.
 package log
 
 import (
+	"fmt"
 	"os"
 	"sync"
 
@@ -36,6 +37,7 @@ type Interface interface {
 	SetColorMode(v bool)
 	SetDebug(v bool)
 	SetPadding(v int)
+	WithPrefix(prefix string) Interface
 }
 
 // Fields re-exports log.Fields from github.com/apex/log.
@@ -82,8 +84,43 @@ func (l *Log) SetPadding(v int) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if handler, ok := l.Handler.(*cli.Handler); ok {
+	handler := l.Handler
+	for {
+		prefixed, ok := handler.(*prefixHandler)
+		if !ok {
+			break
+		}
+
+		handler = prefixed.Handler
+	}
+
+	if handler, ok := handler.(*cli.Handler); ok {
 		handler.Padding = v
-		l.Handler = handler
 	}
 }
+
+// WithPrefix returns a logger that writes to the same handler, prefixing every message with the given string.
+func (l *Log) WithPrefix(prefix string) Interface {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return &Log{
+		Logger: log.Logger{
+			Handler: &prefixHandler{Handler: l.Handler, prefix: prefix},
+			Level:   l.Level,
+		},
+	}
+}
+
+type prefixHandler struct {
+	log.Handler
+	prefix string
+}
+
+// HandleLog implements log.Handler.
+func (h *prefixHandler) HandleLog(e *log.Entry) error {
+	entry := *e
+	entry.Message = fmt.Sprintf("[%s] %s", h.prefix, e.Message)
+
+	return h.Handler.HandleLog(&entry)
+}
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	alog "github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,3 +46,20 @@ func TestLog(t *testing.T) {
 	log.SetDebug(false)
 	assert.Equal(t, log.Level, alog.InfoLevel)
 }
+
+func TestLog_WithPrefix(t *testing.T) {
+	t.Parallel()
+
+	handler := memory.New()
+	log := &Log{Logger: alog.Logger{Handler: handler, Level: alog.InfoLevel}}
+
+	prefixed := log.WithPrefix("My App")
+	prefixed.SetPadding(4)
+	prefixed.WithField("key", "value").Info("hello")
+	log.Info("goodbye")
+
+	assert.Len(t, handler.Entries, 2)
+	assert.Equal(t, "[My App] hello", handler.Entries[0].Message)
+	assert.Equal(t, alog.Fields{"key": "value"}, handler.Entries[0].Fields)
+	assert.Equal(t, "goodbye", handler.Entries[1].Message)
+}
@@ -0,0 +1,125 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package announce is a pipe that announces releases once they have been submitted for review
+package announce
+
+import (
+	"fmt"
+
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/template"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/hashicorp/go-multierror"
+)
+
+const defaultMessage = "{{ .app }} {{ .version }} has been submitted for {{ .mode }} review."
+
+// ErrSkipNoAnnouncers happens when none of the apps being released have announcers configured.
+var ErrSkipNoAnnouncers = pipe.Skip("no announcers configured")
+
+// Announcer is implemented by types that can announce a release.
+type Announcer interface {
+	fmt.Stringer
+
+	// Announce the release, using the given template to render messages
+	Announce(ctx *context.Context, tmpl *template.Template) error
+}
+
+// Pipe is a global hook pipe.
+type Pipe struct{}
+
+// String is the name of this pipe.
+func (Pipe) String() string {
+	return "announcing release"
+}
+
+// Run executes the pipe.
+func (p Pipe) Run(ctx *context.Context) error {
+	if ctx.SkipSubmit {
+		return pipe.ErrSkipSubmitEnabled
+	}
+
+	var announced bool
+
+	var errors *multierror.Error
+
+	for _, name := range ctx.AppsToRelease {
+		app, ok := ctx.Config[name]
+		if !ok {
+			return pipe.ErrMissingApp{Name: name}
+		}
+
+		announcers := Announcers(app.Announce)
+		if len(announcers) == 0 || ctx.Summary.Failed(name) {
+			continue
+		}
+
+		announced = true
+
+		version := ctx.VersionForApp(name)
+		tmpl := template.New(ctx).WithFields(template.Fields{
+			"app":         name,
+			"bundleID":    app.BundleID,
+			"version":     version.Version,
+			"build":       version.Build,
+			"mode":        ctx.PublishMode.String(),
+			"publicLinks": ctx.PublicLinks.ForApp(name),
+		})
+
+		for _, announcer := range announcers {
+			ctx.Log.WithField("app", name).Infof("announcing to %s", announcer)
+
+			if err := announcer.Announce(ctx, tmpl); err != nil {
+				errors = multierror.Append(errors, fmt.Errorf("announcing %s to %s: %w", name, announcer, err))
+			}
+		}
+	}
+
+	if !announced {
+		return ErrSkipNoAnnouncers
+	}
+
+	return errors.ErrorOrNil()
+}
+
+// Announcers returns the announcers enabled by the given configuration.
+func Announcers(cfg *config.Announce) []Announcer {
+	if cfg == nil {
+		return nil
+	}
+
+	var announcers []Announcer
+
+	if cfg.Webhook != nil {
+		announcers = append(announcers, Webhook{*cfg.Webhook})
+	}
+
+	if cfg.Slack != nil {
+		announcers = append(announcers, Slack{*cfg.Slack})
+	}
+
+	if cfg.SMTP != nil {
+		announcers = append(announcers, SMTP{*cfg.SMTP})
+	}
+
+	return announcers
+}
@@ -0,0 +1,341 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package announce
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	Header http.Header
+	Body   string
+}
+
+func newTestServer(t *testing.T, status int) (*httptest.Server, <-chan request) {
+	t.Helper()
+
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requests <- request{Header: r.Header, Body: string(body)}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+// smtpServer is a minimal stand-in for an SMTP server that records the messages it receives.
+type smtpServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []string
+	wg       sync.WaitGroup
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	s := &smtpServer{listener: listener}
+
+	s.wg.Add(1)
+
+	go s.serve()
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+		s.wg.Wait()
+	})
+
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost ready")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+		case "EHLO", "HELO", "MAIL", "RCPT", "RSET", "NOOP":
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+
+			var msg strings.Builder
+
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				msg.WriteString(line)
+			}
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func newTestContext(announce *config.Announce) *context.Context {
+	ctx := context.New(config.Project{
+		"My App": {
+			BundleID: "com.app.MyApp",
+			Announce: announce,
+		},
+	})
+	ctx.AppsToRelease = []string{"My App"}
+	ctx.Version = "1.0"
+	ctx.PublishMode = context.PublishModeAppStore
+
+	return ctx
+}
+
+func TestAnnounce_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "announcing release", Pipe{}.String())
+}
+
+func TestAnnounce_Happy(t *testing.T) {
+	t.Parallel()
+
+	webhookServer, webhookRequests := newTestServer(t, http.StatusOK)
+	slackServer, slackRequests := newTestServer(t, http.StatusOK)
+	smtpServer := newSMTPServer(t)
+
+	ctx := newTestContext(&config.Announce{
+		Webhook: &config.WebhookAnnouncer{
+			URL:     webhookServer.URL,
+			Headers: map[string]string{"Authorization": "Bearer TEST"},
+		},
+		Slack: &config.SlackAnnouncer{
+			URL:       slackServer.URL,
+			Message:   "{{ .app }} {{ .version }} is out",
+			Channel:   "#releases",
+			IconEmoji: ":apple:",
+		},
+		SMTP: &config.SMTPAnnouncer{
+			Host: "127.0.0.1",
+			Port: smtpServer.port(),
+			From: "cider@example.com",
+			To:   []string{"team@example.com"},
+		},
+	})
+
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+
+	req := <-webhookRequests
+	assert.Equal(t, "Bearer TEST", req.Header.Get("Authorization"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(req.Body), &body))
+	assert.Equal(t, map[string]string{
+		"app":     "My App",
+		"version": "1.0",
+		"build":   "",
+		"mode":    "appstore",
+		"message": "My App 1.0 has been submitted for appstore review.",
+	}, body)
+
+	req = <-slackRequests
+	assert.JSONEq(t, `{"text": "My App 1.0 is out", "channel": "#releases", "icon_emoji": ":apple:"}`, req.Body)
+
+	smtpServer.mu.Lock()
+	defer smtpServer.mu.Unlock()
+	assert.Len(t, smtpServer.messages, 1)
+	assert.Contains(t, smtpServer.messages[0], "To: team@example.com\r\n")
+	assert.Contains(t, smtpServer.messages[0], "Subject: My App 1.0 submitted for review\r\n")
+	assert.Contains(t, smtpServer.messages[0], "\r\n\r\nMy App 1.0 has been submitted for appstore review.\r\n")
+}
+
+func TestAnnounce_CustomWebhookBody(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newTestServer(t, http.StatusNoContent)
+	ctx := newTestContext(&config.Announce{
+		Webhook: &config.WebhookAnnouncer{
+			URL:         server.URL,
+			ContentType: "text/plain",
+			Body:        "{{ .bundleID }}@{{ .version }}",
+		},
+	})
+
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+
+	req := <-requests
+	assert.Equal(t, "text/plain", req.Header.Get("Content-Type"))
+	assert.Equal(t, "com.app.MyApp@1.0", req.Body)
+}
+
+func TestAnnounce_AppVersion(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newTestServer(t, http.StatusNoContent)
+	ctx := newTestContext(&config.Announce{
+		Webhook: &config.WebhookAnnouncer{
+			URL:         server.URL,
+			ContentType: "text/plain",
+			Body:        "{{ .version }} ({{ .build }})",
+		},
+	})
+	ctx.AppVersions = map[string]context.AppVersion{
+		"My App": {Version: "2.0", Build: "42"},
+	}
+
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+
+	req := <-requests
+	assert.Equal(t, "2.0 (42)", req.Body)
+}
+
+func TestAnnounce_PublicLinks(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newTestServer(t, http.StatusNoContent)
+	ctx := newTestContext(&config.Announce{
+		Webhook: &config.WebhookAnnouncer{
+			URL:         server.URL,
+			ContentType: "text/plain",
+			Body:        `Join at {{ index .publicLinks "Public" }}`,
+		},
+	})
+	ctx.PublicLinks.Add("My App", "Public", "https://testflight.apple.com/join/TEST")
+	ctx.PublicLinks.Add("Other App", "Public", "https://testflight.apple.com/join/OTHER")
+
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+
+	req := <-requests
+	assert.Equal(t, "Join at https://testflight.apple.com/join/TEST", req.Body)
+}
+
+func TestAnnounce_Skips(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(nil)
+	err := Pipe{}.Run(ctx)
+	assert.Equal(t, ErrSkipNoAnnouncers, err)
+
+	ctx = newTestContext(&config.Announce{Slack: &config.SlackAnnouncer{URL: "http://localhost"}})
+	ctx.Summary.Record(context.AppOutcome{App: "My App", Status: context.AppStatusFailed})
+	err = Pipe{}.Run(ctx)
+	assert.Equal(t, ErrSkipNoAnnouncers, err)
+
+	ctx = newTestContext(&config.Announce{Slack: &config.SlackAnnouncer{URL: "http://localhost"}})
+	ctx.SkipSubmit = true
+	err = Pipe{}.Run(ctx)
+	assert.True(t, pipe.IsSkip(err))
+}
+
+func TestAnnounce_Err(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newTestServer(t, http.StatusInternalServerError)
+	ctx := newTestContext(&config.Announce{
+		Slack: &config.SlackAnnouncer{URL: server.URL},
+		SMTP:  &config.SMTPAnnouncer{Host: "127.0.0.1", Port: 1, From: "cider@example.com"},
+	})
+
+	err := Pipe{}.Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrUnexpectedStatus{StatusCode: http.StatusInternalServerError}.Error())
+	assert.Contains(t, err.Error(), ErrMissingRecipients.Error())
+	<-requests
+
+	ctx = newTestContext(&config.Announce{Slack: &config.SlackAnnouncer{URL: "{{ .nothing }}"}})
+	err = Pipe{}.Run(ctx)
+	assert.Error(t, err)
+
+	ctx = newTestContext(nil)
+	ctx.AppsToRelease = []string{"Other App"}
+	err = Pipe{}.Run(ctx)
+	assert.Equal(t, pipe.ErrMissingApp{Name: "Other App"}, err)
+}
+
+func TestSMTP_Message(t *testing.T) {
+	t.Parallel()
+
+	msg := SMTP{config.SMTPAnnouncer{From: "a@example.com", To: []string{"b@example.com", "c@example.com"}}}.message("Hi", "line 1\nline 2")
+	assert.Equal(t, "From: a@example.com\r\nTo: b@example.com, c@example.com\r\nSubject: Hi\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\nline 1\r\nline 2\r\n", string(msg))
+}
@@ -0,0 +1,86 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package announce
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/cidertool/cider/internal/template"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+// Slack announces a release by posting to a Slack incoming webhook.
+type Slack struct {
+	config.SlackAnnouncer
+}
+
+type slackPayload struct {
+	Text      string `json:"text"`
+	Channel   string `json:"channel,omitempty"`
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+	IconURL   string `json:"icon_url,omitempty"`
+}
+
+func (Slack) String() string {
+	return "slack"
+}
+
+// Announce the release.
+func (s Slack) Announce(ctx *context.Context, tmpl *template.Template) error {
+	url, err := tmpl.Apply(s.URL)
+	if err != nil {
+		return err
+	}
+
+	message := s.Message
+	if message == "" {
+		message = defaultMessage
+	}
+
+	text, err := tmpl.Apply(message)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(slackPayload{
+		Text:      text,
+		Channel:   s.Channel,
+		Username:  s.Username,
+		IconEmoji: s.IconEmoji,
+		IconURL:   s.IconURL,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", defaultContentType)
+
+	return send(req)
+}
@@ -0,0 +1,107 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package announce
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/cidertool/cider/internal/template"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+const (
+	defaultSMTPPort = 587
+	defaultSubject  = "{{ .app }} {{ .version }} submitted for review"
+)
+
+// ErrMissingRecipients happens when an email announcer has no recipients.
+var ErrMissingRecipients = errors.New("no recipients configured")
+
+// SMTP announces a release by sending an email.
+type SMTP struct {
+	config.SMTPAnnouncer
+}
+
+func (SMTP) String() string {
+	return "smtp"
+}
+
+// Announce the release.
+func (s SMTP) Announce(ctx *context.Context, tmpl *template.Template) error {
+	if len(s.To) == 0 {
+		return ErrMissingRecipients
+	}
+
+	subject := s.Subject
+	if subject == "" {
+		subject = defaultSubject
+	}
+
+	subject, err := tmpl.Apply(subject)
+	if err != nil {
+		return err
+	}
+
+	body := s.Body
+	if body == "" {
+		body = defaultMessage
+	}
+
+	body, err = tmpl.Apply(body)
+	if err != nil {
+		return err
+	}
+
+	port := s.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, ctx.Env["SMTP_PASSWORD"], s.Host)
+	}
+
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(port))
+
+	return smtp.SendMail(addr, auth, s.From, s.To, s.message(subject, body))
+}
+
+func (s SMTP) message(subject, body string) []byte {
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	return []byte(msg.String())
+}
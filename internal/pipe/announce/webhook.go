@@ -0,0 +1,129 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package announce
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cidertool/cider/internal/closer"
+	"github.com/cidertool/cider/internal/template"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+const defaultContentType = "application/json"
+
+// ErrUnexpectedStatus happens when a webhook responds with a non-successful status code.
+type ErrUnexpectedStatus struct {
+	StatusCode int
+}
+
+func (e ErrUnexpectedStatus) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Webhook announces a release by sending a request to a generic webhook.
+type Webhook struct {
+	config.WebhookAnnouncer
+}
+
+func (Webhook) String() string {
+	return "webhook"
+}
+
+// Announce the release.
+func (w Webhook) Announce(ctx *context.Context, tmpl *template.Template) error {
+	url, err := tmpl.Apply(w.URL)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+
+	if w.Body == "" {
+		body, err = defaultWebhookBody(tmpl)
+	} else {
+		var s string
+		s, err = tmpl.Apply(w.Body)
+		body = []byte(s)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	contentType := w.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+
+	return send(req)
+}
+
+func defaultWebhookBody(tmpl *template.Template) ([]byte, error) {
+	var fields = map[string]string{
+		"app":     "{{ .app }}",
+		"version": "{{ .version }}",
+		"build":   "{{ .build }}",
+		"mode":    "{{ .mode }}",
+		"message": defaultMessage,
+	}
+
+	for key, value := range fields {
+		applied, err := tmpl.Apply(value)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[key] = applied
+	}
+
+	return json.Marshal(fields)
+}
+
+func send(req *http.Request) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer closer.Close(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return ErrUnexpectedStatus{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
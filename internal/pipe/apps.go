@@ -0,0 +1,180 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package pipe
+
+import (
+	gocontext "context"
+	"errors"
+	"sync"
+
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/hashicorp/go-multierror"
+)
+
+// AppFunc processes a single app. It is given a copy of the context scoped to that app.
+type AppFunc func(ctx *context.Context, name string, app config.App) error
+
+// ForEachApp calls fn for each app selected for release, with the context's version and build set
+// to the ones resolved for that app. Up to ctx.AppConcurrency apps are
+// processed at a time, in which case their log messages are prefixed with their names.
+//
+// In fail-fast mode, the first error stops any new apps from starting and cancels the ones
+// still running. In aggregate mode, every app is processed and all errors are returned
+// together. When ctx.KeepGoing is set, every app is processed and errors are only recorded
+// in ctx.Summary. Skips don't count as errors, and the first one is returned if every app
+// otherwise succeeded.
+func ForEachApp(ctx *context.Context, fn AppFunc) error {
+	var apps = make([]config.App, len(ctx.AppsToRelease))
+
+	for i, name := range ctx.AppsToRelease {
+		app, ok := ctx.Config[name]
+		if !ok {
+			return ErrMissingApp{Name: name}
+		}
+
+		apps[i] = app
+	}
+
+	size := ctx.AppConcurrency
+	if size < 1 {
+		size = 1
+	}
+
+	failFast := ctx.AppErrorMode != context.AppErrorModeAggregate && !ctx.KeepGoing
+
+	cctx, cancel := gocontext.WithCancel(ctx.Context)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		errs   *multierror.Error
+		skip   error
+		failed bool
+		sem    = make(chan struct{}, size)
+	)
+
+	for i, name := range ctx.AppsToRelease {
+		sem <- struct{}{}
+
+		mu.Lock()
+		stop := failed && failFast
+		mu.Unlock()
+
+		if stop {
+			break
+		}
+
+		version := ctx.VersionForApp(name)
+
+		actx := *ctx
+		actx.Context = cctx
+		actx.Version = version.Version
+		actx.Build = version.Build
+		actx.Semver = version.Semver
+
+		if size > 1 && len(apps) > 1 {
+			actx.Log = ctx.Log.WithPrefix(name)
+		}
+
+		wg.Add(1)
+
+		go func(name string, app config.App) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := fn(&actx, name, app)
+			recordOutcome(&actx, name, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case err == nil:
+			case IsSkip(err):
+				if skip == nil {
+					skip = err
+				}
+			case failed && failFast:
+				// Errors after the first are likely caused by the cancellation.
+			default:
+				failed = true
+				errs = multierror.Append(errs, err)
+
+				if failFast {
+					cancel()
+				}
+			}
+		}(name, apps[i])
+	}
+
+	wg.Wait()
+
+	if errs != nil && ctx.KeepGoing {
+		for _, err := range errs.Errors {
+			ctx.Log.WithError(err).Error("continuing with remaining apps")
+		}
+
+		return skip
+	}
+
+	if errs != nil {
+		if len(errs.Errors) == 1 {
+			return errs.Errors[0]
+		}
+
+		return errs
+	}
+
+	return skip
+}
+
+func recordOutcome(ctx *context.Context, name string, err error) {
+	var outcome = context.AppOutcome{App: name, Status: context.AppStatusSucceeded}
+
+	if ctx.SkipUpdateMetadata {
+		outcome.Skipped = append(outcome.Skipped, "metadata")
+	}
+
+	if ctx.SkipUpdatePricing && ctx.PublishMode == context.PublishModeAppStore {
+		outcome.Skipped = append(outcome.Skipped, "pricing")
+	}
+
+	if ctx.SkipSubmit {
+		outcome.Skipped = append(outcome.Skipped, "submit")
+	}
+
+	switch {
+	case err == nil:
+	case IsSkip(err):
+		outcome.Status = context.AppStatusSkipped
+
+		if !errors.Is(err, ErrSkipSubmitEnabled) {
+			outcome.Skipped = append(outcome.Skipped, err.Error())
+		}
+	default:
+		outcome.Status = context.AppStatusFailed
+		outcome.Err = err
+	}
+
+	ctx.Summary.Record(outcome)
+}
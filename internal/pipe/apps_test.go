@@ -0,0 +1,223 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package pipe
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestError = errors.New("TEST")
+
+func newAppsContext(names ...string) *context.Context {
+	apps := make(config.Project, len(names))
+	for _, name := range names {
+		apps[name] = config.App{BundleID: "com.app." + name}
+	}
+
+	ctx := context.New(apps)
+	ctx.AppsToRelease = names
+
+	return ctx
+}
+
+func TestForEachApp(t *testing.T) {
+	t.Parallel()
+
+	ctx := newAppsContext("a", "b", "c", "d")
+	ctx.AppConcurrency = 2
+
+	var (
+		mu      sync.Mutex
+		visited []string
+		running int32
+		maxSeen int32
+	)
+
+	err := ForEachApp(ctx, func(ctx *context.Context, name string, app config.App) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if n > maxSeen {
+			maxSeen = n
+		}
+
+		assert.Equal(t, "com.app."+name, app.BundleID)
+		visited = append(visited, name)
+
+		return nil
+	})
+	assert.NoError(t, err)
+
+	sort.Strings(visited)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, visited)
+	assert.LessOrEqual(t, maxSeen, int32(2))
+}
+
+func TestForEachApp_AppVersions(t *testing.T) {
+	t.Parallel()
+
+	ctx := newAppsContext("a", "b")
+	ctx.Version = "1.0"
+	ctx.Build = "1"
+	ctx.AppVersions = map[string]context.AppVersion{
+		"b": {Version: "2.0", Build: "7", Semver: context.Semver{Major: 2, RawVersion: "2.0"}},
+	}
+
+	err := ForEachApp(ctx, func(actx *context.Context, name string, app config.App) error {
+		assert.Equal(t, ctx.VersionForApp(name).Version, actx.Version)
+		assert.Equal(t, ctx.VersionForApp(name).Build, actx.Build)
+		assert.Equal(t, ctx.VersionForApp(name).Semver, actx.Semver)
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0", ctx.Version)
+}
+
+func TestForEachApp_MissingApp(t *testing.T) {
+	t.Parallel()
+
+	ctx := newAppsContext("a")
+	ctx.AppsToRelease = []string{"a", "b"}
+
+	err := ForEachApp(ctx, func(ctx *context.Context, name string, app config.App) error {
+		assert.FailNow(t, "should not be called")
+
+		return nil
+	})
+	assert.Equal(t, ErrMissingApp{Name: "b"}, err)
+}
+
+func TestForEachApp_Skip(t *testing.T) {
+	t.Parallel()
+
+	ctx := newAppsContext("a", "b")
+
+	var calls int
+
+	err := ForEachApp(ctx, func(ctx *context.Context, name string, app config.App) error {
+		calls++
+
+		return ErrSkipSubmitEnabled
+	})
+	assert.Equal(t, ErrSkipSubmitEnabled, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestForEachApp_FailFast(t *testing.T) {
+	t.Parallel()
+
+	ctx := newAppsContext("a", "b", "c")
+
+	var visited []string
+
+	err := ForEachApp(ctx, func(ctx *context.Context, name string, app config.App) error {
+		visited = append(visited, name)
+
+		if name == "b" {
+			return errTestError
+		}
+
+		return nil
+	})
+	assert.Equal(t, errTestError, err)
+	assert.Equal(t, []string{"a", "b"}, visited)
+}
+
+func TestForEachApp_Aggregate(t *testing.T) {
+	t.Parallel()
+
+	ctx := newAppsContext("a", "b", "c")
+	ctx.AppErrorMode = context.AppErrorModeAggregate
+	ctx.AppConcurrency = 3
+
+	var calls int32
+
+	err := ForEachApp(ctx, func(ctx *context.Context, name string, app config.App) error {
+		atomic.AddInt32(&calls, 1)
+
+		if name == "c" {
+			return nil
+		}
+
+		return errTestError
+	})
+
+	var merr *multierror.Error
+	assert.ErrorAs(t, err, &merr)
+	assert.Len(t, merr.Errors, 2)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestForEachApp_KeepGoing(t *testing.T) {
+	t.Parallel()
+
+	ctx := newAppsContext("a", "b", "c")
+	ctx.KeepGoing = true
+	ctx.SkipUpdateMetadata = true
+
+	var visited []string
+
+	err := ForEachApp(ctx, func(ctx *context.Context, name string, app config.App) error {
+		visited = append(visited, name)
+
+		switch name {
+		case "a":
+			return errTestError
+		case "b":
+			return Skip("build is processing")
+		}
+
+		return nil
+	})
+	assert.True(t, IsSkip(err))
+	assert.Equal(t, []string{"a", "b", "c"}, visited)
+
+	outcome, ok := ctx.Summary.Outcome("a")
+	assert.True(t, ok)
+	assert.Equal(t, context.AppOutcome{
+		App:     "a",
+		Status:  context.AppStatusFailed,
+		Skipped: []string{"metadata"},
+		Err:     errTestError,
+	}, outcome)
+
+	outcome, _ = ctx.Summary.Outcome("b")
+	assert.Equal(t, context.AppStatusSkipped, outcome.Status)
+	assert.Equal(t, []string{"metadata", "build is processing"}, outcome.Skipped)
+
+	outcome, _ = ctx.Summary.Outcome("c")
+	assert.Equal(t, context.AppStatusSucceeded, outcome.Status)
+	assert.True(t, ctx.Summary.Failed("a"))
+	assert.False(t, ctx.Summary.Failed("c"))
+}
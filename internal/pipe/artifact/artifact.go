@@ -0,0 +1,108 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package artifact is a pipe that reads the version and build to release from a build artifact
+package artifact
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cidertool/cider/internal/artifact"
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+// ErrSkipNoArtifact happens when no build artifact was provided.
+var ErrSkipNoArtifact = pipe.Skip("no build artifact provided")
+
+// ErrMultipleApps happens when a build artifact is provided while releasing more than one app.
+var ErrMultipleApps = errors.New("a build artifact can only be released for a single app")
+
+// ErrBundleIDMismatch happens when the app in a build artifact isn't the app being released.
+type ErrBundleIDMismatch struct {
+	App      string
+	Expected string
+	Actual   string
+}
+
+func (e ErrBundleIDMismatch) Error() string {
+	return fmt.Sprintf("build artifact has bundle ID %s, but app %s has bundle ID %s", e.Actual, e.App, e.Expected)
+}
+
+// Pipe is a global hook pipe.
+type Pipe struct{}
+
+// String is the name of this pipe.
+func (Pipe) String() string {
+	return "reading build artifact"
+}
+
+// Run executes the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	if ctx.ArtifactPath == "" {
+		return ErrSkipNoArtifact
+	}
+
+	if len(ctx.AppsToRelease) != 1 {
+		return ErrMultipleApps
+	}
+
+	name := ctx.AppsToRelease[0]
+
+	app, ok := ctx.Config[name]
+	if !ok {
+		return pipe.ErrMissingApp{Name: name}
+	}
+
+	path := ctx.ArtifactPath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ctx.CurrentDirectory, path)
+	}
+
+	info, err := artifact.Read(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if info.BundleID != app.BundleID {
+		return ErrBundleIDMismatch{App: name, Expected: app.BundleID, Actual: info.BundleID}
+	}
+
+	ctx.Version = info.Version
+	ctx.Build = info.Build
+
+	// The artifact takes precedence over a version configured for the app.
+	if ctx.AppVersions == nil {
+		ctx.AppVersions = make(map[string]context.AppVersion)
+	}
+
+	ctx.AppVersions[name] = context.AppVersion{Version: info.Version, Build: info.Build}
+
+	ctx.Log.WithFields(log.Fields{
+		"app":     name,
+		"version": info.Version,
+		"build":   info.Build,
+	}).Info("releasing build artifact")
+
+	return nil
+}
@@ -0,0 +1,131 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package artifact
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+const testInfoPlist = `<plist version="1.0"><dict>
+	<key>CFBundleIdentifier</key><string>com.app.bundleid</string>
+	<key>CFBundleShortVersionString</key><string>1.2.3</string>
+	<key>CFBundleVersion</key><string>42</string>
+</dict></plist>`
+
+func TestArtifact_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "reading build artifact", Pipe{}.String())
+}
+
+func TestArtifact_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t, "com.app.bundleid")
+
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3", ctx.Version)
+	assert.Equal(t, "42", ctx.Build)
+	assert.Equal(t, context.AppVersion{Version: "1.2.3", Build: "42"}, ctx.VersionForApp("My App"))
+}
+
+func TestArtifact_SkipNoArtifact(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t, "com.app.bundleid")
+	ctx.ArtifactPath = ""
+
+	err := Pipe{}.Run(ctx)
+	assert.Equal(t, ErrSkipNoArtifact, err)
+	assert.Empty(t, ctx.Version)
+}
+
+func TestArtifact_ErrBundleIDMismatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t, "com.app.other")
+
+	err := Pipe{}.Run(ctx)
+	assert.EqualError(t, err, "build artifact has bundle ID com.app.bundleid, but app My App has bundle ID com.app.other")
+	assert.Empty(t, ctx.Version)
+}
+
+func TestArtifact_ErrApps(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t, "com.app.bundleid")
+	ctx.AppsToRelease = []string{"My App", "Other App"}
+
+	err := Pipe{}.Run(ctx)
+	assert.Equal(t, ErrMultipleApps, err)
+
+	ctx.AppsToRelease = []string{"Other App"}
+
+	err = Pipe{}.Run(ctx)
+	assert.Equal(t, pipe.ErrMissingApp{Name: "Other App"}, err)
+}
+
+func TestArtifact_ErrRead(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t, "com.app.bundleid")
+	ctx.ArtifactPath = "Missing.ipa"
+
+	err := Pipe{}.Run(ctx)
+	assert.Error(t, err)
+	assert.Empty(t, ctx.Version)
+}
+
+func newTestContext(t *testing.T, bundleID string) *context.Context {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	f, err := os.Create(filepath.Join(dir, "App.ipa"))
+	assert.NoError(t, err)
+
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	fw, err := w.Create("Payload/App.app/Info.plist")
+	assert.NoError(t, err)
+	_, err = fw.Write([]byte(testInfoPlist))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	ctx := context.New(config.Project{
+		"My App": {BundleID: bundleID, Version: "1.0.0"},
+	})
+	ctx.AppsToRelease = []string{"My App"}
+	ctx.CurrentDirectory = dir
+	ctx.ArtifactPath = "App.ipa"
+
+	return ctx
+}
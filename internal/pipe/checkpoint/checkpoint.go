@@ -0,0 +1,111 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package checkpoint is a pipe that sets up the checkpoint used to resume interrupted releases
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+// ErrSkipCheckpointsDisabled happens when no state directory is set.
+var ErrSkipCheckpointsDisabled = pipe.Skip("checkpoints are disabled")
+
+// Pipe is a global hook pipe.
+type Pipe struct{}
+
+// String is the name of this pipe.
+func (Pipe) String() string {
+	return "preparing checkpoint"
+}
+
+// Run executes the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	if ctx.StateDirectory == "" {
+		return ErrSkipCheckpointsDisabled
+	}
+
+	dir := ctx.StatePath()
+
+	hash, err := configHash(ctx)
+	if err != nil {
+		return err
+	}
+
+	key := context.CheckpointKey{
+		Version:     ctx.Version,
+		Build:       ctx.Build,
+		AppVersions: appVersions(ctx),
+		PublishMode: ctx.PublishMode.String(),
+		ConfigHash:  hash,
+	}
+
+	if !ctx.Resume {
+		ctx.Checkpoint = context.NewCheckpoint(dir, key)
+
+		return nil
+	}
+
+	checkpoint, resumed, err := context.LoadCheckpoint(dir, key)
+	if err != nil {
+		return err
+	}
+
+	if resumed {
+		ctx.Log.WithField("dir", dir).Info("resuming from checkpoint")
+
+		for _, r := range checkpoint.Resources() {
+			ctx.Resources.AddForPlatform(r.App, r.Platform, r.Type, r.ID)
+		}
+	} else {
+		ctx.Log.WithField("dir", dir).Warn("no checkpoint matches this release, starting from the beginning")
+	}
+
+	ctx.Checkpoint = checkpoint
+
+	return nil
+}
+
+func appVersions(ctx *context.Context) string {
+	versions := make([]string, 0, len(ctx.AppVersions))
+
+	for name, version := range ctx.AppVersions {
+		versions = append(versions, fmt.Sprintf("%s=%s (%s)", name, version.Version, version.Build))
+	}
+
+	sort.Strings(versions)
+
+	return strings.Join(versions, ", ")
+}
+
+func configHash(ctx *context.Context) (string, error) {
+	s, err := ctx.RawConfig.String()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s))), nil
+}
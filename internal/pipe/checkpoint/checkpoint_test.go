@@ -0,0 +1,98 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package checkpoint
+
+import (
+	"testing"
+
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "preparing checkpoint", Pipe{}.String())
+}
+
+func TestCheckpoint_Disabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	err := Pipe{}.Run(ctx)
+	assert.Equal(t, ErrSkipCheckpointsDisabled, err)
+	assert.Nil(t, ctx.Checkpoint)
+}
+
+func TestCheckpoint_Resume(t *testing.T) {
+	t.Parallel()
+
+	newContext := func(dir string, project config.Project) *context.Context {
+		ctx := context.New(project)
+		ctx.CurrentDirectory = dir
+		ctx.StateDirectory = ".cider"
+		ctx.Version = "1.0"
+		ctx.PublishMode = context.PublishModeAppStore
+
+		return ctx
+	}
+
+	dir := t.TempDir()
+	project := config.Project{"My App": {BundleID: "com.app"}}
+
+	ctx := newContext(dir, project)
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, ctx.Checkpoint)
+	ctx.Resources.Add("com.app", "apps", "TEST")
+	err = ctx.Checkpoint.Complete("com.app", "metadata", ctx.Resources.List())
+	assert.NoError(t, err)
+
+	// Without --resume, the checkpoint is started over
+	ctx = newContext(dir, project)
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ctx.Checkpoint.Done("com.app", "metadata"))
+	assert.Empty(t, ctx.Resources.List())
+
+	ctx = newContext(dir, project)
+	ctx.Resume = true
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ctx.Checkpoint.Done("com.app", "metadata"))
+	assert.Equal(t, []context.Resource{{App: "com.app", Type: "apps", ID: "TEST"}}, ctx.Resources.List())
+
+	// Changing the configuration invalidates the checkpoint
+	ctx = newContext(dir, config.Project{"My App": {BundleID: "com.app.changed"}})
+	ctx.Resume = true
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ctx.Checkpoint.Done("com.app", "metadata"))
+
+	// Releasing an app with a different version invalidates the checkpoint
+	ctx = newContext(dir, project)
+	ctx.AppVersions = map[string]context.AppVersion{"My App": {Version: "1.1"}}
+	ctx.Resume = true
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ctx.Checkpoint.Done("com.app", "metadata"))
+}
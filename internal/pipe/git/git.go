@@ -24,10 +24,12 @@ package git
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/cidertool/cider/internal/bump"
 	"github.com/cidertool/cider/internal/git"
 	"github.com/cidertool/cider/internal/log"
 	"github.com/cidertool/cider/internal/pipe"
@@ -49,7 +51,7 @@ func (Pipe) String() string {
 
 // Run executes the hooks.
 func (p Pipe) Run(ctx *context.Context) error {
-	if ctx.SkipGit && ctx.Version != "" {
+	if ctx.SkipGit && (ctx.Version != "" || appsHaveVersions(ctx)) {
 		ctx.Git = context.GitInfo{
 			CurrentTag:  NoTag,
 			Commit:      "none",
@@ -83,14 +85,14 @@ func (p Pipe) Run(ctx *context.Context) error {
 		"url":    info.URL,
 	}).Debug("git info")
 
-	if ctx.Version == "" {
-		tag, err := getTag(client)
-		if err != nil {
-			return git.ErrNoTag
-		}
+	if err := resolveAppTags(ctx, client); err != nil {
+		return err
+	}
 
-		ctx.Git.CurrentTag = tag
-		ctx.Version = strings.TrimPrefix(tag, "v")
+	if ctx.Version == "" && !appsHaveVersions(ctx) {
+		if err := resolveVersion(ctx, client); err != nil {
+			return err
+		}
 	}
 
 	ctx.Log.WithFields(log.Fields{
@@ -99,7 +101,184 @@ func (p Pipe) Run(ctx *context.Context) error {
 		"workdir": ctx.CurrentDirectory,
 	}).Info("releasing")
 
-	return validate(ctx, client)
+	if err := validate(ctx, client); err != nil {
+		return err
+	}
+
+	return tagNextVersion(ctx, client)
+}
+
+// tagNextVersion creates an annotated tag at the current commit for each version computed because the
+// next version was requested, and pushes them to the origin remote if requested, so the release can be
+// resumed or repeated from the tags without computing the versions again.
+func tagNextVersion(ctx *context.Context, client *git.Git) error {
+	if !ctx.NextVersion || !(ctx.TagNextVersion || ctx.PushNextVersion) {
+		return nil
+	}
+
+	var tags []string
+
+	for _, name := range ctx.AppsToRelease {
+		app := ctx.Config[name]
+		version := ctx.AppVersions[name]
+
+		// Mirrors resolveAppTags, which only computes versions for these apps.
+		if app.TagPrefix == "" || app.Version != "" || version.Version == "" {
+			continue
+		}
+
+		tag, err := createTag(ctx, client, app.TagPrefix, version.Version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		version.Tag = tag
+		ctx.AppVersions[name] = version
+		tags = append(tags, tag)
+	}
+
+	if ctx.Version != "" {
+		tag, err := createTag(ctx, client, "", ctx.Version)
+		if err != nil {
+			return err
+		}
+
+		ctx.Git.CurrentTag = tag
+		tags = append(tags, tag)
+	}
+
+	if !ctx.PushNextVersion {
+		return nil
+	}
+
+	for _, tag := range tags {
+		if err := client.PushTag("origin", tag); err != nil {
+			return err
+		}
+
+		ctx.Log.WithField("tag", tag).Info("pushed tag")
+	}
+
+	return nil
+}
+
+func createTag(ctx *context.Context, client *git.Git, prefix, version string) (string, error) {
+	tag, err := bump.TagName(ctx, prefix, version)
+	if err != nil {
+		return "", err
+	}
+
+	if err := client.CreateTag(tag, fmt.Sprintf("Release %s", version)); err != nil {
+		return "", err
+	}
+
+	ctx.Log.WithField("tag", tag).Info("tagged next version")
+
+	return tag, nil
+}
+
+// resolveVersion sets the version from the latest tag, or computes the version following it if the next version
+// was requested.
+func resolveVersion(ctx *context.Context, client *git.Git) error {
+	if ctx.NextVersion {
+		next, err := nextVersion(client, "")
+		if err != nil {
+			return err
+		}
+
+		ctx.Version = next
+		ctx.VersionFromGit = true
+
+		ctx.Log.WithField("version", next).Info("computed next version")
+
+		return nil
+	}
+
+	tag, err := getTag(client)
+	if err != nil {
+		return git.ErrNoTag
+	}
+
+	ctx.Git.CurrentTag = tag
+	ctx.Version = strings.TrimPrefix(tag, "v")
+	ctx.VersionFromGit = true
+
+	return nil
+}
+
+// nextVersion computes the version following the latest tag starting with prefix from the commits made since.
+func nextVersion(client *git.Git, prefix string) (string, error) {
+	result, err := bump.Compute(client, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	if result.Increment == bump.None {
+		return "", bump.ErrNoChanges{Tag: result.PreviousTag}
+	}
+
+	return result.Next, nil
+}
+
+// resolveAppTags sets the version of each app configured with a tag prefix from the latest tag
+// matching that prefix, or the version following it if the next version was requested, unless a
+// version was already given for the app or for every app.
+func resolveAppTags(ctx *context.Context, client *git.Git) error {
+	for _, name := range ctx.AppsToRelease {
+		app := ctx.Config[name]
+		version := ctx.AppVersions[name]
+
+		if app.TagPrefix == "" || version.Version != "" || app.Version != "" || ctx.Version != "" {
+			continue
+		}
+
+		if ctx.NextVersion {
+			next, err := nextVersion(client, app.TagPrefix)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+
+			version.Version = next
+		} else {
+			tag, err := getAppTag(client, app.TagPrefix)
+			if err != nil {
+				return git.ErrNoMatchingTag{Pattern: app.TagPrefix + "*"}
+			}
+
+			version.Tag = tag
+			version.Version = strings.TrimPrefix(strings.TrimPrefix(tag, app.TagPrefix), "v")
+		}
+
+		if ctx.AppVersions == nil {
+			ctx.AppVersions = make(map[string]context.AppVersion)
+		}
+
+		ctx.AppVersions[name] = version
+
+		ctx.Log.WithFields(log.Fields{
+			"app":     name,
+			"tag":     version.Tag,
+			"version": version.Version,
+		}).Info("resolved app version")
+	}
+
+	return nil
+}
+
+// appsHaveVersions reports whether every app being released has its own version, in which case
+// the project's latest tag isn't needed.
+func appsHaveVersions(ctx *context.Context) bool {
+	if len(ctx.AppsToRelease) == 0 {
+		return false
+	}
+
+	for _, name := range ctx.AppsToRelease {
+		if ctx.AppVersions[name].Version == "" && ctx.Config[name].Version == "" {
+			return false
+		}
+	}
+
+	return true
 }
 
 func getInfo(client *git.Git) (context.GitInfo, error) {
@@ -145,16 +324,45 @@ func getGitInfo(client *git.Git) (context.GitInfo, error) {
 
 func validate(ctx *context.Context, client *git.Git) error {
 	proc, err := client.Run("status", "--porcelain")
-	if strings.TrimSpace(proc.Stdout) != "" || err != nil {
+	if err != nil {
 		return git.ErrDirty{Status: proc.Stdout}
 	}
 
+	status := proc.Stdout
+
+	if dir, ok := stateDirectoryInWorkTree(ctx); ok {
+		// The checkpoints and snapshots of a release don't make the working copy dirty, otherwise
+		// an interrupted release could never be resumed.
+		prefix, err := client.SanitizeProcess(client.Run("rev-parse", "--show-prefix"))
+		if err != nil {
+			return err
+		}
+
+		status = withoutPath(status, prefix+dir)
+	}
+
+	if strings.TrimSpace(status) != "" {
+		return git.ErrDirty{Status: status}
+	}
+
+	tags := make([]string, 0, len(ctx.AppsToRelease)+1)
+
 	if ctx.Git.CurrentTag != NoTag {
-		_, err := client.SanitizeProcess(client.Run("describe", "--exact-match", "--tags", "--match", ctx.Git.CurrentTag))
+		tags = append(tags, ctx.Git.CurrentTag)
+	}
+
+	for _, name := range ctx.AppsToRelease {
+		if tag := ctx.AppVersions[name].Tag; tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	for _, tag := range tags {
+		_, err := client.SanitizeProcess(client.Run("describe", "--exact-match", "--tags", "--match", tag))
 		if err != nil {
 			return git.ErrWrongRef{
 				Commit: ctx.Git.Commit,
-				Tag:    ctx.Git.CurrentTag,
+				Tag:    tag,
 			}
 		}
 	}
@@ -162,6 +370,51 @@ func validate(ctx *context.Context, client *git.Git) error {
 	return nil
 }
 
+// stateDirectoryInWorkTree returns the path of the state directory relative to the current directory, if it
+// is inside of it.
+func stateDirectoryInWorkTree(ctx *context.Context) (string, bool) {
+	dir := ctx.StatePath()
+	if dir == "" {
+		return "", false
+	}
+
+	base, err := filepath.Abs(ctx.CurrentDirectory)
+	if err != nil {
+		return "", false
+	}
+
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(base, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// withoutPath removes the lines about the given path, relative to the root of the repository, and anything
+// inside of it from the output of git status --porcelain.
+func withoutPath(status, path string) string {
+	var lines []string
+
+	for _, line := range strings.Split(status, "\n") {
+		if len(line) > 3 {
+			file := strings.TrimSuffix(strings.Trim(line[3:], `"`), "/")
+			if file == path || strings.HasPrefix(file, path+"/") {
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func getCommitDate(client *git.Git) (time.Time, error) {
 	commit, err := client.Show("%ct")
 	if err != nil {
@@ -201,3 +454,7 @@ func getTag(client *git.Git) (string, error) {
 func getURL(client *git.Git) (string, error) {
 	return client.SanitizeProcess(client.Run("ls-remote", "--get-url"))
 }
+
+func getAppTag(client *git.Git, prefix string) (string, error) {
+	return client.SanitizeProcess(client.Run("describe", "--tags", "--abbrev=0", "--match", prefix+"*"))
+}
@@ -90,6 +90,305 @@ func TestGit_SkipGit(t *testing.T) {
 	assert.EqualError(t, err, pipe.ErrSkipGitEnabled.Error())
 }
 
+func TestGit_SkipGitWithAppVersions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", Version: "2.0"},
+	})
+	ctx.AppsToRelease = []string{"ios"}
+	ctx.SkipGit = true
+
+	p := Pipe{}
+
+	err := p.Run(ctx)
+	assert.EqualError(t, err, pipe.ErrSkipGitEnabled.Error())
+}
+
+func TestGit_NoTagWithAppVersions(t *testing.T) {
+	t.Parallel()
+
+	expected := context.GitInfo{
+		CurrentTag:  NoTag,
+		Commit:      "abcdef1234567890abcdef1234567890abcdef12",
+		ShortCommit: "abcdef12",
+		FullCommit:  "abcdef1234567890abcdef1234567890abcdef12",
+		CommitDate:  time.Unix(1600914830, 0).UTC(),
+		URL:         "git@github.com:cidertool/cider.git",
+	}
+
+	ctx := context.New(config.Project{
+		"ios":   {BundleID: "com.app.ios", Version: "2.0"},
+		"watch": {BundleID: "com.app.watch"},
+	})
+	ctx.AppsToRelease = []string{"ios", "watch"}
+	ctx.AppVersions = map[string]context.AppVersion{
+		"watch": {Version: "1.1"},
+	}
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: expected.ShortCommit},
+		shelltest.Command{Stdout: expected.FullCommit},
+		shelltest.Command{Stdout: strconv.FormatInt(expected.CommitDate.Unix(), 10)},
+		shelltest.Command{Stdout: expected.URL},
+		shelltest.Command{Stdout: ""},
+	)
+
+	err := p.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, ctx.Git)
+	assert.Empty(t, ctx.Version)
+}
+
+func TestGit_AppTagPrefixes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios":   {BundleID: "com.app.ios", TagPrefix: "ios-app/"},
+		"watch": {BundleID: "com.app.watch", TagPrefix: "watch-app/"},
+		"tv":    {BundleID: "com.app.tv", TagPrefix: "tv-app/", Version: "3.0.0"},
+	})
+	ctx.AppsToRelease = []string{"ios", "watch", "tv"}
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{Stdout: "ios-app/v2.4.0"},
+		shelltest.Command{Stdout: "watch-app/1.1.0"},
+		shelltest.Command{Stdout: ""},
+		shelltest.Command{Stdout: "ios-app/v2.4.0"},
+		shelltest.Command{Stdout: "watch-app/1.1.0"},
+	)
+
+	err := p.Run(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, ctx.Version)
+	assert.Equal(t, NoTag, ctx.Git.CurrentTag)
+	assert.Equal(t, map[string]context.AppVersion{
+		"ios":   {Version: "2.4.0", Tag: "ios-app/v2.4.0"},
+		"watch": {Version: "1.1.0", Tag: "watch-app/1.1.0"},
+	}, ctx.AppVersions)
+}
+
+func TestGit_AppTagPrefixesWithSetVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", TagPrefix: "ios-app/"},
+	})
+	ctx.AppsToRelease = []string{"ios"}
+	ctx.Version = "2.0.0"
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{Stdout: ""},
+	)
+
+	err := p.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0", ctx.Version)
+	assert.False(t, ctx.VersionFromGit)
+	assert.Empty(t, ctx.AppVersions)
+	assert.Equal(t, "2.0.0", ctx.VersionForApp("ios").Version)
+}
+
+func TestGit_NextVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.NextVersion = true
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{Stdout: "v1.2.3"},
+		shelltest.Command{Stdout: "fix: crash on launch\n\x00\nfeat: add widget\n\x00\n"},
+		shelltest.Command{Stdout: ""},
+	)
+
+	err := p.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.0", ctx.Version)
+	assert.True(t, ctx.VersionFromGit)
+	assert.Equal(t, NoTag, ctx.Git.CurrentTag)
+}
+
+func TestGit_NextVersionTagAndPush(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", TagPrefix: "ios-app/"},
+		"tv":  {BundleID: "com.app.tv"},
+	})
+	ctx.AppsToRelease = []string{"ios", "tv"}
+	ctx.NextVersion = true
+	ctx.PushNextVersion = true
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{Stdout: "ios-app/v2.4.0"},
+		shelltest.Command{Stdout: "feat!: drop iOS 12\n\x00\n"},
+		shelltest.Command{Stdout: "v1.2.3"},
+		shelltest.Command{Stdout: "fix: crash on launch\n\x00\n"},
+		shelltest.Command{Stdout: ""},
+		shelltest.Command{Stdout: ""},
+		shelltest.Command{Stdout: ""},
+		shelltest.Command{Stdout: ""},
+		shelltest.Command{Stdout: ""},
+	)
+
+	err := p.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.4", ctx.Version)
+	assert.Equal(t, "v1.2.4", ctx.Git.CurrentTag)
+	assert.Equal(t, map[string]context.AppVersion{
+		"ios": {Version: "3.0.0", Tag: "ios-app/v3.0.0"},
+	}, ctx.AppVersions)
+}
+
+func TestGit_Err_NextVersionTag(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.NextVersion = true
+	ctx.TagNextVersion = true
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{Stdout: "v1.2.3"},
+		shelltest.Command{Stdout: "fix: crash on launch\n\x00\n"},
+		shelltest.Command{Stdout: ""},
+		shelltest.Command{ReturnCode: 128, Stderr: "fatal: tag 'v1.2.4' already exists\n"},
+	)
+
+	err := p.Run(ctx)
+	assert.EqualError(t, err, "fatal: tag 'v1.2.4' already exists")
+}
+
+func TestGit_NextVersionForAppTagPrefixes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", TagPrefix: "ios-app/"},
+	})
+	ctx.AppsToRelease = []string{"ios"}
+	ctx.NextVersion = true
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{Stdout: "ios-app/v2.4.0"},
+		shelltest.Command{Stdout: "feat!: drop iOS 12\n\x00\n"},
+		shelltest.Command{Stdout: ""},
+	)
+
+	err := p.Run(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, ctx.Version)
+	assert.Equal(t, map[string]context.AppVersion{
+		"ios": {Version: "3.0.0"},
+	}, ctx.AppVersions)
+}
+
+func TestGit_Err_NextVersionNoChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", TagPrefix: "ios-app/"},
+	})
+	ctx.AppsToRelease = []string{"ios"}
+	ctx.NextVersion = true
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{Stdout: "ios-app/v2.4.0"},
+		shelltest.Command{Stdout: "chore: update dependencies\n\x00\n"},
+	)
+
+	err := p.Run(ctx)
+	assert.EqualError(t, err, "ios: no commits since ios-app/v2.4.0 warrant a new version")
+}
+
+func TestGit_Err_NoAppTag(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", TagPrefix: "ios-app/"},
+	})
+	ctx.AppsToRelease = []string{"ios"}
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{ReturnCode: 128, Stderr: "no names found"},
+	)
+
+	err := p.Run(ctx)
+	assert.EqualError(t, err, "git doesn't contain any tags matching ios-app/*")
+}
+
+func TestGit_Err_AppTagNotAtHead(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", TagPrefix: "ios-app/"},
+	})
+	ctx.AppsToRelease = []string{"ios"}
+
+	p := Pipe{}
+	p.client = newMockGitWithContext(ctx,
+		shelltest.Command{Stdout: "true"},
+		shelltest.Command{Stdout: "abcdef12"},
+		shelltest.Command{Stdout: "abcdef1234567890abcdef1234567890abcdef12"},
+		shelltest.Command{Stdout: "1600914830"},
+		shelltest.Command{Stdout: "git@github.com:cidertool/cider.git"},
+		shelltest.Command{Stdout: "ios-app/v2.4.0"},
+		shelltest.Command{Stdout: ""},
+		shelltest.Command{ReturnCode: 128, Stderr: "no tag exactly matches"},
+	)
+
+	err := p.Run(ctx)
+	assert.EqualError(t, err, "git tag ios-app/v2.4.0 was not made against commit abcdef1234567890abcdef1234567890abcdef12")
+}
+
 func TestGit_Err_NoGit(t *testing.T) {
 	t.Parallel()
 
@@ -276,6 +575,14 @@ func TestGit_Err_InvalidTag(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestWithoutPath(t *testing.T) {
+	t.Parallel()
+
+	status := "?? .cider/\n M .cider/checkpoint.json\n?? .ciderignored\n M main.go"
+	assert.Equal(t, "?? .ciderignored\n M main.go", withoutPath(status, ".cider"))
+	assert.Equal(t, "", withoutPath("?? .cider/", ".cider"))
+}
+
 func newMockGitWithContext(ctx *context.Context, commands ...shelltest.Command) *git.Git {
 	return &git.Git{
 		Shell: &shelltest.Shell{
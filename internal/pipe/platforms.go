@@ -0,0 +1,98 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package pipe
+
+import (
+	"fmt"
+
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+// ErrPlatformFailed happens when releasing one of the platforms of an app that is released on
+// more than one platform fails.
+type ErrPlatformFailed struct {
+	Platform config.Platform
+	Err      error
+}
+
+func (e ErrPlatformFailed) Error() string {
+	return fmt.Sprintf("%s: %s", e.Platform, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e ErrPlatformFailed) Unwrap() error {
+	return e.Err
+}
+
+// PlatformFunc processes a single platform of an app. It is given a copy of the context scoped
+// to that platform, and the app's version for it.
+type PlatformFunc func(ctx *context.Context, version config.Version) error
+
+// ForEachPlatform calls fn for each platform the app is released on, one at a time. When the app's
+// versions are configured per platform, log messages are prefixed with the platform and errors
+// are wrapped in ErrPlatformFailed. The first error stops any remaining platforms from being
+// processed. Skips don't count as errors, and the first one is returned if every platform
+// otherwise succeeded.
+func ForEachPlatform(ctx *context.Context, app config.App, fn PlatformFunc) error {
+	var skip error
+
+	multiPlatform := IsMultiPlatform(app)
+
+	for _, version := range app.Versions.ForPlatforms() {
+		pctx := *ctx
+
+		if multiPlatform {
+			pctx.Log = ctx.Log.WithPrefix(string(version.Platform))
+		}
+
+		err := fn(&pctx, version)
+
+		switch {
+		case err == nil:
+		case IsSkip(err):
+			if skip == nil {
+				skip = err
+			}
+		case multiPlatform:
+			return ErrPlatformFailed{Platform: version.Platform, Err: err}
+		default:
+			return err
+		}
+	}
+
+	return skip
+}
+
+// IsMultiPlatform returns true if the app's versions are configured per platform.
+func IsMultiPlatform(app config.App) bool {
+	return len(app.Versions.Platforms) > 0
+}
+
+// PlatformStep returns the name of the given step for one of the platforms of the app, for use with
+// Step. Steps are only qualified with the platform if the app's versions are configured per platform.
+func PlatformStep(app config.App, platform config.Platform, step string) string {
+	if !IsMultiPlatform(app) {
+		return step
+	}
+
+	return fmt.Sprintf("%s (%s)", step, platform)
+}
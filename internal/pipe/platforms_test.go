@@ -0,0 +1,103 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package pipe
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachPlatform_Single(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	app := config.App{Versions: config.Version{Platform: config.PlatformiOS}}
+
+	var platforms []config.Platform
+
+	err := ForEachPlatform(ctx, app, func(pctx *context.Context, version config.Version) error {
+		assert.Same(t, ctx.Log, pctx.Log)
+
+		platforms = append(platforms, version.Platform)
+
+		return errTestError
+	})
+	assert.Equal(t, errTestError, err)
+	assert.Equal(t, []config.Platform{config.PlatformiOS}, platforms)
+	assert.Equal(t, "submit", PlatformStep(app, config.PlatformiOS, "submit"))
+}
+
+func TestForEachPlatform_Multiple(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	app := config.App{Versions: config.Version{
+		Platforms: config.PlatformVersions{
+			config.PlatformTvOS:  {},
+			config.PlatformiOS:   {},
+			config.PlatformMacOS: {},
+		},
+	}}
+
+	var platforms []config.Platform
+
+	err := ForEachPlatform(ctx, app, func(pctx *context.Context, version config.Version) error {
+		assert.NotSame(t, ctx.Log, pctx.Log)
+
+		platforms = append(platforms, version.Platform)
+
+		if version.Platform == config.PlatformMacOS {
+			return ErrSkipSubmitEnabled
+		}
+
+		return nil
+	})
+	assert.Equal(t, ErrSkipSubmitEnabled, err)
+	assert.Equal(t, []config.Platform{config.PlatformiOS, config.PlatformMacOS, config.PlatformTvOS}, platforms)
+	assert.Equal(t, "submit (tvOS)", PlatformStep(app, config.PlatformTvOS, "submit"))
+}
+
+func TestForEachPlatform_MultipleErr(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	app := config.App{Versions: config.Version{
+		Platforms: config.PlatformVersions{
+			config.PlatformiOS:   {},
+			config.PlatformMacOS: {},
+		},
+	}}
+
+	var calls int
+
+	err := ForEachPlatform(ctx, app, func(pctx *context.Context, version config.Version) error {
+		calls++
+
+		return errTestError
+	})
+	assert.Equal(t, 1, calls)
+	assert.EqualError(t, err, "iOS: TEST")
+	assert.True(t, errors.Is(err, errTestError))
+}
@@ -0,0 +1,248 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package plugin is a pipe that runs an external program as a step of the release pipeline
+package plugin
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/shell"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+// ProtocolVersion is the version of the JSON protocol spoken with plugins.
+const ProtocolVersion = 1
+
+// ErrPluginFailed happens when a plugin reports an error or exits unsuccessfully.
+type ErrPluginFailed struct {
+	Name    string
+	Message string
+}
+
+func (e ErrPluginFailed) Error() string {
+	return fmt.Sprintf("plugin %s failed: %s", e.Name, e.Message)
+}
+
+// ErrPluginTimedOut happens when a plugin runs longer than its configured timeout.
+type ErrPluginTimedOut struct {
+	Name string
+}
+
+func (e ErrPluginTimedOut) Error() string {
+	return fmt.Sprintf("plugin %s timed out", e.Name)
+}
+
+// Request is the JSON object sent to the standard input of a plugin.
+type Request struct {
+	ProtocolVersion int     `json:"protocolVersion"`
+	Plugin          string  `json:"plugin"`
+	Context         Release `json:"context"`
+}
+
+// Release describes the state of the release for a plugin.
+type Release struct {
+	Version            string                `json:"version"`
+	Build              string                `json:"build"`
+	PublishMode        string                `json:"publishMode"`
+	Apps               []string              `json:"apps"`
+	AppVersions        map[string]AppVersion `json:"appVersions"`
+	CurrentDirectory   string                `json:"currentDirectory"`
+	Git                Git                   `json:"git"`
+	Resources          []context.Resource    `json:"resources"`
+	PublicLinks        []context.PublicLink  `json:"publicLinks"`
+	SkipUpdatePricing  bool                  `json:"skipUpdatePricing"`
+	SkipUpdateMetadata bool                  `json:"skipUpdateMetadata"`
+	SkipSubmit         bool                  `json:"skipSubmit"`
+	MetadataOnly       bool                  `json:"metadataOnly"`
+}
+
+// AppVersion describes the version an app is released with for a plugin.
+type AppVersion struct {
+	Version string `json:"version"`
+	Build   string `json:"build"`
+	Tag     string `json:"tag,omitempty"`
+}
+
+// Git describes the state of the Git repository for a plugin.
+type Git struct {
+	CurrentTag  string    `json:"currentTag"`
+	Commit      string    `json:"commit"`
+	ShortCommit string    `json:"shortCommit"`
+	FullCommit  string    `json:"fullCommit"`
+	CommitDate  time.Time `json:"commitDate"`
+	URL         string    `json:"url"`
+}
+
+// Message is a JSON object a plugin writes to its standard output, one per line.
+type Message struct {
+	Type    string `json:"type"`
+	Level   string `json:"level,omitempty"`
+	Message string `json:"message"`
+}
+
+// Pipe runs a plugin.
+type Pipe struct {
+	Plugin config.Plugin
+	Shell  shell.Shell
+}
+
+// String is the name of this pipe.
+func (p Pipe) String() string {
+	return "running plugin " + p.Plugin.Name
+}
+
+// Run the pipe.
+func (p Pipe) Run(ctx *context.Context) error {
+	input, err := json.Marshal(NewRequest(ctx, p.Plugin))
+	if err != nil {
+		return err
+	}
+
+	pctx := *ctx
+
+	if p.Plugin.Timeout > 0 {
+		var cancel gocontext.CancelFunc
+
+		pctx.Context, cancel = gocontext.WithTimeout(ctx.Context, p.Plugin.Timeout)
+		defer cancel()
+	}
+
+	sh := p.Shell
+	if sh == nil {
+		sh = shell.New(&pctx)
+	}
+
+	cmd := sh.NewCommand(p.program(ctx), p.Plugin.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(ctx.Env.Strings(), p.Plugin.Env...)
+
+	var result error
+
+	shell.StreamStdout(cmd, func(line string) {
+		if err := p.handle(ctx, line); err != nil && result == nil {
+			result = err
+		}
+	})
+	shell.StreamStderr(cmd, func(line string) {
+		ctx.Log.Info(line)
+	})
+
+	_, err = sh.Exec(cmd)
+
+	switch {
+	case errors.Is(pctx.Err(), gocontext.DeadlineExceeded) && ctx.Err() == nil:
+		return ErrPluginTimedOut{Name: p.Plugin.Name}
+	case result != nil:
+		return result
+	case err != nil:
+		return ErrPluginFailed{Name: p.Plugin.Name, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (p Pipe) program(ctx *context.Context) string {
+	program := p.Plugin.Command
+	if ctx.CurrentDirectory != "" && strings.ContainsRune(program, '/') && !filepath.IsAbs(program) {
+		program = filepath.Join(ctx.CurrentDirectory, program)
+	}
+
+	return program
+}
+
+func (p Pipe) handle(ctx *context.Context, line string) error {
+	var msg Message
+	if err := json.Unmarshal([]byte(line), &msg); err != nil || msg.Type == "" {
+		ctx.Log.Info(line)
+
+		return nil
+	}
+
+	switch msg.Type {
+	case "skip":
+		return pipe.Skip(msg.Message)
+	case "error":
+		return ErrPluginFailed{Name: p.Plugin.Name, Message: msg.Message}
+	}
+
+	switch msg.Level {
+	case "debug":
+		ctx.Log.Debug(msg.Message)
+	case "warn":
+		ctx.Log.Warn(msg.Message)
+	case "error":
+		ctx.Log.Error(msg.Message)
+	default:
+		ctx.Log.Info(msg.Message)
+	}
+
+	return nil
+}
+
+// NewRequest returns the request sent to the given plugin for the current state of the release.
+func NewRequest(ctx *context.Context, plugin config.Plugin) Request {
+	var appVersions = make(map[string]AppVersion, len(ctx.AppsToRelease))
+
+	for _, name := range ctx.AppsToRelease {
+		version := ctx.VersionForApp(name)
+		appVersions[name] = AppVersion{
+			Version: version.Version,
+			Build:   version.Build,
+			Tag:     version.Tag,
+		}
+	}
+
+	return Request{
+		ProtocolVersion: ProtocolVersion,
+		Plugin:          plugin.Name,
+		Context: Release{
+			Version:          ctx.Version,
+			Build:            ctx.Build,
+			PublishMode:      ctx.PublishMode.String(),
+			Apps:             ctx.AppsToRelease,
+			AppVersions:      appVersions,
+			CurrentDirectory: ctx.CurrentDirectory,
+			Git: Git{
+				CurrentTag:  ctx.Git.CurrentTag,
+				Commit:      ctx.Git.Commit,
+				ShortCommit: ctx.Git.ShortCommit,
+				FullCommit:  ctx.Git.FullCommit,
+				CommitDate:  ctx.Git.CommitDate,
+				URL:         ctx.Git.URL,
+			},
+			Resources:          ctx.Resources.List(),
+			PublicLinks:        ctx.PublicLinks.List(),
+			SkipUpdatePricing:  ctx.SkipUpdatePricing,
+			SkipUpdateMetadata: ctx.SkipUpdateMetadata,
+			SkipSubmit:         ctx.SkipSubmit,
+			MetadataOnly:       ctx.MetadataOnly,
+		},
+	}
+}
@@ -0,0 +1,178 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/shell"
+	"github.com/cidertool/cider/internal/shell/shelltest"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingShell struct {
+	shelltest.Shell
+	stdin []byte
+}
+
+func (sh *recordingShell) Exec(cmd *exec.Cmd) (*shell.CompletedProcess, error) {
+	stdin, err := io.ReadAll(cmd.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	sh.stdin = stdin
+
+	return sh.Shell.Exec(cmd)
+}
+
+func newMockPipe(t *testing.T, ctx *context.Context, commands ...shelltest.Command) Pipe {
+	t.Helper()
+
+	return Pipe{
+		Plugin: config.Plugin{Name: "test", Command: "./plugin", After: "publish"},
+		Shell: &shelltest.Shell{
+			T:        t,
+			Context:  ctx,
+			Commands: commands,
+		},
+	}
+}
+
+func TestPlugin_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "running plugin test", Pipe{Plugin: config.Plugin{Name: "test"}}.String())
+}
+
+func TestPlugin_Happy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	p := newMockPipe(t, ctx, shelltest.Command{
+		Stdout: `{"type": "log", "level": "warn", "message": "careful"}
+not json
+{"type": "log", "message": "done"}`,
+		Stderr: "some diagnostics",
+	})
+
+	err := p.Run(ctx)
+	assert.NoError(t, err)
+}
+
+func TestPlugin_Skip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	p := newMockPipe(t, ctx, shelltest.Command{
+		Stdout: `{"type": "skip", "message": "nothing to do"}`,
+	})
+
+	err := p.Run(ctx)
+	assert.True(t, pipe.IsSkip(err))
+	assert.EqualError(t, err, "nothing to do")
+}
+
+func TestPlugin_Err(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	p := newMockPipe(t, ctx, shelltest.Command{
+		Stdout:     `{"type": "error", "message": "upload failed"}`,
+		ReturnCode: 1,
+	})
+
+	err := p.Run(ctx)
+	assert.Equal(t, ErrPluginFailed{Name: "test", Message: "upload failed"}, err)
+
+	p = newMockPipe(t, ctx, shelltest.Command{ReturnCode: 1})
+
+	err = p.Run(ctx)
+	assert.Equal(t, ErrPluginFailed{Name: "test", Message: "1"}, err)
+}
+
+func TestPlugin_Timeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	p := Pipe{Plugin: config.Plugin{Name: "test", Command: "sleep", Args: []string{"5"}, Timeout: 10 * time.Millisecond}}
+
+	err := p.Run(ctx)
+	assert.Equal(t, ErrPluginTimedOut{Name: "test"}, err)
+}
+
+func TestPlugin_Request(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.Version = "1.0"
+	ctx.Build = "2"
+	ctx.PublishMode = context.PublishModeTestflight
+	ctx.AppsToRelease = []string{"My App", "Other App"}
+	ctx.AppVersions = map[string]context.AppVersion{
+		"Other App": {Version: "2.0", Tag: "other/v2.0"},
+	}
+	ctx.Git.CurrentTag = "v1.0"
+	ctx.Resources.Add("com.app.MyApp", "builds", "TEST")
+	ctx.PublicLinks.Add("My App", "Public", "https://testflight.apple.com/join/TEST")
+
+	p := Pipe{Plugin: config.Plugin{Name: "test", Command: "cat"}}
+	sh := &recordingShell{Shell: shelltest.Shell{T: t, Context: ctx, Commands: []shelltest.Command{{}}}}
+	p.Shell = sh
+
+	err := p.Run(ctx)
+	assert.NoError(t, err)
+
+	var req Request
+	err = json.Unmarshal(sh.stdin, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion, req.ProtocolVersion)
+	assert.Equal(t, "test", req.Plugin)
+	assert.Equal(t, "1.0", req.Context.Version)
+	assert.Equal(t, "2", req.Context.Build)
+	assert.Equal(t, "testflight", req.Context.PublishMode)
+	assert.Equal(t, []string{"My App", "Other App"}, req.Context.Apps)
+	assert.Equal(t, map[string]AppVersion{
+		"My App":    {Version: "1.0", Build: "2"},
+		"Other App": {Version: "2.0", Build: "2", Tag: "other/v2.0"},
+	}, req.Context.AppVersions)
+	assert.Equal(t, "v1.0", req.Context.Git.CurrentTag)
+	assert.Equal(t, []context.Resource{{App: "com.app.MyApp", Type: "builds", ID: "TEST"}}, req.Context.Resources)
+	assert.Equal(t, []context.PublicLink{{App: "My App", Group: "Public", URL: "https://testflight.apple.com/join/TEST"}}, req.Context.PublicLinks)
+}
+
+func TestPlugin_Program(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.CurrentDirectory = "project"
+
+	assert.Equal(t, filepath.Join("project", "scripts", "plugin"), Pipe{Plugin: config.Plugin{Command: "./scripts/plugin"}}.program(ctx))
+	assert.Equal(t, "plugin", Pipe{Plugin: config.Plugin{Command: "plugin"}}.program(ctx))
+}
@@ -23,11 +23,19 @@ package semver
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/cidertool/cider/pkg/config"
 	"github.com/cidertool/cider/pkg/context"
 )
 
+var (
+	appleVersionRegex     = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+	prereleaseNumberRegex = regexp.MustCompile(`(\d+)$`)
+)
+
 // Pipe is a global hook pipe.
 type Pipe struct{}
 
@@ -36,20 +44,166 @@ func (Pipe) String() string {
 	return "parsing version"
 }
 
+// ErrNoVersion happens when no version could be determined for an app.
+type ErrNoVersion struct {
+	App string
+}
+
+func (e ErrNoVersion) Error() string {
+	return fmt.Sprintf("no version found for app %s", e.App)
+}
+
+// ErrInvalidVersion happens when a version can't be used as the version string of an app in App Store Connect.
+type ErrInvalidVersion struct {
+	Version string
+	Reason  string
+}
+
+func (e ErrInvalidVersion) Error() string {
+	return fmt.Sprintf("version %s can't be released: %s", e.Version, e.Reason)
+}
+
+// ErrInvalidPrereleaseStrategy happens when an app is configured with an unknown prerelease strategy.
+type ErrInvalidPrereleaseStrategy struct {
+	Strategy string
+}
+
+func (e ErrInvalidPrereleaseStrategy) Error() string {
+	return fmt.Sprintf("invalid prerelease strategy %s, must be one of reject, strip or build", e.Strategy)
+}
+
 // Run executes the hooks.
 func (p Pipe) Run(ctx *context.Context) error {
-	sv, err := semver.NewVersion(ctx.Version)
+	if ctx.Version != "" || len(ctx.AppsToRelease) == 0 {
+		sv, err := parse(ctx.Version)
+		if err != nil {
+			return err
+		}
+
+		ctx.Semver = sv
+	}
+
+	versions := make(map[string]context.AppVersion, len(ctx.AppsToRelease))
+
+	for _, name := range ctx.AppsToRelease {
+		version := ctx.AppVersions[name]
+		app := ctx.Config[name]
+
+		// A version given on the command line takes precedence over the app's configuration, while
+		// one derived from the project's latest tag only applies to apps that don't have their own.
+		if version.Version == "" && !ctx.VersionFromGit {
+			version.Version = ctx.Version
+		}
+
+		if version.Version == "" {
+			version.Version = app.Version
+		}
+
+		if version.Version == "" {
+			version.Version = ctx.Version
+		}
+
+		if version.Build == "" {
+			version.Build = ctx.Build
+		}
+
+		if version.Build == "" {
+			version.Build = app.Build
+		}
+
+		if version.Version == "" {
+			return ErrNoVersion{App: name}
+		}
+
+		sv, err := parse(version.Version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		version.Semver = sv
+
+		version, err = applyAppleRules(ctx, app, version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		versions[name] = version
+	}
+
+	ctx.AppVersions = versions
+
+	return nil
+}
+
+func parse(version string) (context.Semver, error) {
+	sv, err := semver.NewVersion(version)
 	if err != nil {
-		return fmt.Errorf("failed to parse tag %s as semver: %w", ctx.Version, err)
+		return context.Semver{}, fmt.Errorf("failed to parse tag %s as semver: %w", version, err)
 	}
 
-	ctx.Semver = context.Semver{
+	return context.Semver{
 		Major:      sv.Major(),
 		Minor:      sv.Minor(),
 		Patch:      sv.Patch(),
 		Prerelease: sv.Prerelease(),
 		RawVersion: sv.Original(),
+	}, nil
+}
+
+// applyAppleRules checks that the version is made of up to three non-negative integers, as App Store Connect
+// requires, and maps a version with a prerelease or build metadata according to the app's prerelease strategy.
+func applyAppleRules(ctx *context.Context, app config.App, version context.AppVersion) (context.AppVersion, error) {
+	raw := version.Version
+	core := raw
+
+	if i := strings.IndexAny(raw, "-+"); i >= 0 {
+		core = raw[:i]
 	}
 
-	return nil
+	if !appleVersionRegex.MatchString(core) {
+		return version, ErrInvalidVersion{
+			Version: raw,
+			Reason:  "App Store Connect only accepts up to three non-negative integers separated by periods, such as 1.2.3",
+		}
+	}
+
+	if core == raw {
+		return version, nil
+	}
+
+	prerelease := version.Semver.Prerelease
+
+	if prerelease != "" && ctx.PublishMode == context.PublishModeAppStore {
+		return version, ErrInvalidVersion{
+			Version: raw,
+			Reason:  fmt.Sprintf("prereleases can't be published to the App Store, release %s instead", core),
+		}
+	}
+
+	switch app.PrereleaseStrategy {
+	case "", config.PrereleaseStrategyReject:
+		return version, ErrInvalidVersion{
+			Version: raw,
+			Reason:  fmt.Sprintf("App Store Connect doesn't accept prereleases or build metadata, set prereleaseStrategy to strip or build to release it as %s", core),
+		}
+	case config.PrereleaseStrategyStrip:
+	case config.PrereleaseStrategyBuild:
+		if version.Build == "" && prerelease != "" {
+			match := prereleaseNumberRegex.FindString(prerelease)
+			if match == "" {
+				return version, ErrInvalidVersion{
+					Version: raw,
+					Reason:  fmt.Sprintf("prerelease %s doesn't end with a number to use as the build", prerelease),
+				}
+			}
+
+			version.Build = match
+		}
+	default:
+		return version, ErrInvalidPrereleaseStrategy{Strategy: string(app.PrereleaseStrategy)}
+	}
+
+	version.Version = core
+
+	return version, nil
 }
@@ -69,3 +69,182 @@ func TestSemver(t *testing.T) {
 	assert.Error(t, err)
 	assert.Empty(t, ctx.Semver)
 }
+
+func TestSemver_Apps(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios":   {BundleID: "com.app.ios", Version: "2.0.0", Build: "7"},
+		"watch": {BundleID: "com.app.watch"},
+		"tv":    {BundleID: "com.app.tv", Version: "3.0.0"},
+	})
+	ctx.AppsToRelease = []string{"ios", "watch", "tv"}
+	ctx.Version = "1.0.0"
+	ctx.VersionFromGit = true
+	ctx.AppVersions = map[string]context.AppVersion{
+		"tv": {Version: "3.1.0", Tag: "tv-app/v3.1.0"},
+	}
+
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]context.AppVersion{
+		"ios": {
+			Version: "2.0.0",
+			Build:   "7",
+			Semver:  context.Semver{Major: 2, RawVersion: "2.0.0"},
+		},
+		"watch": {
+			Version: "1.0.0",
+			Semver:  context.Semver{Major: 1, RawVersion: "1.0.0"},
+		},
+		"tv": {
+			Version: "3.1.0",
+			Tag:     "tv-app/v3.1.0",
+			Semver:  context.Semver{Major: 3, Minor: 1, RawVersion: "3.1.0"},
+		},
+	}, ctx.AppVersions)
+	assert.Equal(t, context.Semver{Major: 1, RawVersion: "1.0.0"}, ctx.Semver)
+}
+
+func TestSemver_AppsWithSetVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios": {BundleID: "com.app.ios", Version: "2.0.0", Build: "7"},
+		"tv":  {BundleID: "com.app.tv", Version: "3.0.0", Build: "9"},
+	})
+	ctx.AppsToRelease = []string{"ios", "tv"}
+	ctx.Version = "1.0.0"
+	ctx.Build = "1"
+	ctx.AppVersions = map[string]context.AppVersion{
+		"tv": {Version: "3.1.0", Build: "12"},
+	}
+
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]context.AppVersion{
+		"ios": {
+			Version: "1.0.0",
+			Build:   "1",
+			Semver:  context.Semver{Major: 1, RawVersion: "1.0.0"},
+		},
+		"tv": {
+			Version: "3.1.0",
+			Build:   "12",
+			Semver:  context.Semver{Major: 3, Minor: 1, RawVersion: "3.1.0"},
+		},
+	}, ctx.AppVersions)
+}
+
+func TestSemver_AppsWithoutSharedVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"ios":   {BundleID: "com.app.ios", Version: "2.0.0"},
+		"watch": {BundleID: "com.app.watch"},
+	})
+	ctx.AppsToRelease = []string{"ios"}
+
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, ctx.Semver)
+	assert.Equal(t, "2.0.0", ctx.AppVersions["ios"].Version)
+
+	ctx.AppsToRelease = []string{"ios", "watch"}
+	err = Pipe{}.Run(ctx)
+	assert.EqualError(t, err, "no version found for app watch")
+
+	ctx.AppVersions = map[string]context.AppVersion{
+		"watch": {Version: "bad"},
+	}
+	err = Pipe{}.Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "watch: failed to parse tag bad as semver")
+}
+
+func TestSemver_AppleVersionRules(t *testing.T) {
+	t.Parallel()
+
+	newContext := func(version string, app config.App) *context.Context {
+		app.BundleID = "com.app.ios"
+		ctx := context.New(config.Project{"ios": app})
+		ctx.AppsToRelease = []string{"ios"}
+		ctx.PublishMode = context.PublishModeTestflight
+		ctx.Version = version
+
+		return ctx
+	}
+
+	// Plain versions are released as-is
+	ctx := newContext("1.2", config.App{})
+	err := Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2", ctx.AppVersions["ios"].Version)
+
+	// Prereleases are rejected by default
+	ctx = newContext("1.2.0-beta.3", config.App{})
+	err = Pipe{}.Run(ctx)
+	assert.EqualError(t, err, "ios: version 1.2.0-beta.3 can't be released: App Store Connect doesn't accept prereleases or build metadata, set prereleaseStrategy to strip or build to release it as 1.2.0")
+
+	ctx = newContext("1.2.0+abc123", config.App{PrereleaseStrategy: config.PrereleaseStrategyReject})
+	err = Pipe{}.Run(ctx)
+	assert.Error(t, err)
+
+	// Strip
+	ctx = newContext("1.2.0-beta.3+abc123", config.App{PrereleaseStrategy: config.PrereleaseStrategyStrip})
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.0", ctx.AppVersions["ios"].Version)
+	assert.Empty(t, ctx.AppVersions["ios"].Build)
+	assert.Equal(t, "beta.3", ctx.AppVersions["ios"].Semver.Prerelease)
+
+	// Build
+	ctx = newContext("1.2.0-beta.3", config.App{PrereleaseStrategy: config.PrereleaseStrategyBuild})
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.0", ctx.AppVersions["ios"].Version)
+	assert.Equal(t, "3", ctx.AppVersions["ios"].Build)
+
+	ctx = newContext("1.2.0-rc12", config.App{PrereleaseStrategy: config.PrereleaseStrategyBuild})
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "12", ctx.AppVersions["ios"].Build)
+
+	// A given build takes precedence over the prerelease
+	ctx = newContext("1.2.0-beta.3", config.App{PrereleaseStrategy: config.PrereleaseStrategyBuild, Build: "40"})
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "40", ctx.AppVersions["ios"].Build)
+
+	ctx = newContext("1.2.0-beta", config.App{PrereleaseStrategy: config.PrereleaseStrategyBuild})
+	err = Pipe{}.Run(ctx)
+	assert.EqualError(t, err, "ios: version 1.2.0-beta can't be released: prerelease beta doesn't end with a number to use as the build")
+
+	// Build metadata alone doesn't need a build number
+	ctx = newContext("1.2.0+abc123", config.App{PrereleaseStrategy: config.PrereleaseStrategyBuild})
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.0", ctx.AppVersions["ios"].Version)
+	assert.Empty(t, ctx.AppVersions["ios"].Build)
+
+	// Prereleases can't be published to the App Store, whatever the strategy
+	ctx = newContext("1.2.0-beta.3", config.App{PrereleaseStrategy: config.PrereleaseStrategyStrip})
+	ctx.PublishMode = context.PublishModeAppStore
+	err = Pipe{}.Run(ctx)
+	assert.EqualError(t, err, "ios: version 1.2.0-beta.3 can't be released: prereleases can't be published to the App Store, release 1.2.0 instead")
+
+	ctx = newContext("1.2.0+abc123", config.App{PrereleaseStrategy: config.PrereleaseStrategyStrip})
+	ctx.PublishMode = context.PublishModeAppStore
+	err = Pipe{}.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.0", ctx.AppVersions["ios"].Version)
+
+	// Versions that don't follow Apple's format
+	ctx = newContext("v1.2.0", config.App{})
+	err = Pipe{}.Run(ctx)
+	assert.EqualError(t, err, "ios: version v1.2.0 can't be released: App Store Connect only accepts up to three non-negative integers separated by periods, such as 1.2.3")
+
+	ctx = newContext("1.2.0-beta.3", config.App{PrereleaseStrategy: "guess"})
+	err = Pipe{}.Run(ctx)
+	assert.EqualError(t, err, "ios: invalid prerelease strategy guess, must be one of reject, strip or build")
+}
@@ -0,0 +1,65 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package pipe
+
+import (
+	"github.com/cidertool/cider/internal/log"
+	"github.com/cidertool/cider/pkg/context"
+)
+
+// Step runs fn as the named step of releasing the app with the given bundle ID. If the
+// checkpoint shows the step was completed by a previous run, fn is not called. Otherwise
+// the step is recorded in the checkpoint once fn succeeds.
+func Step(ctx *context.Context, bundleID, step string, fn func() error) error {
+	if ctx.Checkpoint.Done(bundleID, step) {
+		ctx.Log.WithField("step", step).Info("skipping step completed by a previous run")
+
+		return nil
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	return ctx.Checkpoint.Complete(bundleID, step, ctx.Resources.List())
+}
+
+// UseBuild records the build the app with the given bundle ID is released with on the given
+// platform. If the checkpoint was made by a previous run that released another build, the
+// steps it completed for the app are run again.
+func UseBuild(ctx *context.Context, bundleID, platform, buildID string) error {
+	previousBuildID, err := ctx.Checkpoint.UseBuild(bundleID, platform, buildID)
+	if err != nil {
+		return err
+	}
+
+	if previousBuildID != "" {
+		ctx.Resources.Remove(context.Resource{App: bundleID, Platform: platform, Type: "builds", ID: previousBuildID})
+		ctx.Log.WithFields(log.Fields{
+			"previous": previousBuildID,
+			"build":    buildID,
+		}).Warn("build changed since the checkpoint was saved, running every step again")
+	}
+
+	ctx.Resources.AddForPlatform(bundleID, platform, "builds", buildID)
+
+	return nil
+}
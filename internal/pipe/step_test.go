@@ -0,0 +1,99 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package pipe
+
+import (
+	"testing"
+
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStep(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.Checkpoint = context.NewCheckpoint(t.TempDir(), context.CheckpointKey{})
+
+	var calls int
+
+	fn := func() error {
+		calls++
+
+		return nil
+	}
+
+	err := Step(ctx, "com.app", "metadata", func() error {
+		return errTestError
+	})
+	assert.Equal(t, errTestError, err)
+	assert.False(t, ctx.Checkpoint.Done("com.app", "metadata"))
+
+	err = Step(ctx, "com.app", "metadata", fn)
+	assert.NoError(t, err)
+	assert.True(t, ctx.Checkpoint.Done("com.app", "metadata"))
+
+	err = Step(ctx, "com.app", "metadata", fn)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestStep_NoCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+
+	var calls int
+
+	for i := 0; i < 2; i++ {
+		err := Step(ctx, "com.app", "metadata", func() error {
+			calls++
+
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestUseBuild(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.Checkpoint = context.NewCheckpoint(t.TempDir(), context.CheckpointKey{})
+
+	err := UseBuild(ctx, "com.app", "", "1")
+	assert.NoError(t, err)
+	err = Step(ctx, "com.app", "metadata", func() error { return nil })
+	assert.NoError(t, err)
+
+	err = UseBuild(ctx, "com.app", "", "1")
+	assert.NoError(t, err)
+	assert.True(t, ctx.Checkpoint.Done("com.app", "metadata"))
+	assert.Equal(t, []context.Resource{{App: "com.app", Type: "builds", ID: "1"}}, ctx.Resources.List())
+
+	err = UseBuild(ctx, "com.app", "", "2")
+	assert.NoError(t, err)
+	assert.False(t, ctx.Checkpoint.Done("com.app", "metadata"))
+	assert.Equal(t, []context.Resource{{App: "com.app", Type: "builds", ID: "2"}}, ctx.Resources.List())
+}
@@ -24,8 +24,10 @@ package store
 import (
 	"github.com/cidertool/asc-go/asc"
 	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/hooks"
 	"github.com/cidertool/cider/internal/log"
 	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/snapshot"
 	"github.com/cidertool/cider/pkg/config"
 	"github.com/cidertool/cider/pkg/context"
 )
@@ -33,6 +35,7 @@ import (
 // Pipe is a global hook pipe.
 type Pipe struct {
 	Client client.Client
+	Hooks  hooks.Runner
 }
 
 // String is the name of this pipe.
@@ -46,21 +49,13 @@ func (p *Pipe) Publish(ctx *context.Context) error {
 		p.Client = client.New(ctx)
 	}
 
-	for _, name := range ctx.AppsToRelease {
-		app, ok := ctx.Config[name]
-		if !ok {
-			return pipe.ErrMissingApp{Name: name}
-		}
-
+	return pipe.ForEachApp(ctx, func(ctx *context.Context, name string, app config.App) error {
 		ctx.Log.WithField("app", name).Info("updating metadata")
 
-		err := p.doRelease(ctx, app)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+		return p.Hooks.Around(ctx, app.Hooks, hooks.AppEnv(name, app), func() error {
+			return p.doRelease(ctx, app)
+		})
+	})
 }
 
 func (p *Pipe) doRelease(ctx *context.Context, config config.App) error {
@@ -69,46 +64,107 @@ func (p *Pipe) doRelease(ctx *context.Context, config config.App) error {
 		return err
 	}
 
-	isInitial, err := p.Client.ReleaseForAppIsInitial(ctx, app.ID)
+	ctx.Resources.Add(config.BundleID, "apps", app.ID)
+
+	return pipe.ForEachPlatform(ctx, config, p.releasePlatform(config, app))
+}
+
+func (p *Pipe) releasePlatform(appConfig config.App, app *asc.App) pipe.PlatformFunc {
+	return func(ctx *context.Context, versionConfig config.Version) error {
+		platformConfig := appConfig
+		platformConfig.Versions = versionConfig
+
+		step := func(name string) string {
+			return pipe.PlatformStep(appConfig, versionConfig.Platform, name)
+		}
+
+		return p.releaseVersion(ctx, platformConfig, app, step)
+	}
+}
+
+func (p *Pipe) releaseVersion(ctx *context.Context, config config.App, app *asc.App, step func(string) string) error {
+	platform := config.Versions.Platform
+
+	isInitial, err := p.Client.ReleaseForAppIsInitial(ctx, app.ID, platform)
 	if err != nil {
 		return err
 	}
 
 	ctx.VersionIsInitialRelease = isInitial
 
-	build, err := p.Client.GetBuild(ctx, app)
-	if err != nil {
-		return err
+	// In metadata-only mode, the version is prepared before its build is uploaded, so no build is looked up
+	// and the version keeps whichever build is already attached to it, if any.
+	var build *asc.Build
+
+	var buildID string
+
+	if !ctx.MetadataOnly {
+		build, err = p.Client.GetBuild(ctx, app, platform, config.BuildSelection)
+		if err != nil {
+			return err
+		}
+
+		buildID = build.ID
 	}
 
-	version, err := p.Client.CreateVersionIfNeeded(ctx, app.ID, build.ID, config.Versions)
+	version, err := p.Client.CreateVersionIfNeeded(ctx, app.ID, buildID, config.Versions)
 	if err != nil {
 		return err
 	}
 
-	ctx.Log.WithFields(log.Fields{
-		"app":     *app.Attributes.BundleID,
-		"build":   *build.Attributes.Version,
-		"version": *version.Attributes.VersionString,
-	}).Info("found resources")
+	fields := log.Fields{
+		"app":      *app.Attributes.BundleID,
+		"version":  *version.Attributes.VersionString,
+		"platform": platform,
+	}
+
+	if build != nil {
+		if err := pipe.UseBuild(ctx, config.BundleID, string(platform), build.ID); err != nil {
+			return err
+		}
+
+		fields["build"] = *build.Attributes.Version
+	}
+
+	ctx.Resources.AddForPlatform(config.BundleID, string(platform), "appStoreVersions", version.ID)
+
+	ctx.Log.WithFields(fields).Info("found resources")
+
+	if config.ExportCompliance != nil && build != nil {
+		ctx.Log.Info("updating export compliance")
+
+		if err := pipe.Step(ctx, config.BundleID, step("export compliance"), func() error {
+			return p.Client.UpdateBuildExportCompliance(ctx, app.ID, build, *config.ExportCompliance)
+		}); err != nil {
+			return err
+		}
+	}
 
 	if ctx.SkipUpdateMetadata {
 		ctx.Log.Warn("skipping updating metdata")
 	} else {
 		ctx.Log.Info("updating metadata")
-		if err := p.updateVersionDetails(ctx, config, app, version); err != nil {
+		if err := pipe.Step(ctx, config.BundleID, step("metadata"), func() error {
+			if err := p.saveSnapshot(ctx, config, app, version); err != nil {
+				return err
+			}
+
+			return p.updateVersionDetails(ctx, config, app, version)
+		}); err != nil {
 			return err
 		}
 	}
 
-	if ctx.SkipSubmit {
+	if ctx.SkipSubmit || ctx.MetadataOnly {
 		return pipe.ErrSkipSubmitEnabled
 	}
 
 	if config.Versions.PhasedReleaseEnabled && !ctx.VersionIsInitialRelease {
 		ctx.Log.Info("preparing phased release details")
 
-		if err := p.Client.EnablePhasedRelease(ctx, version.ID); err != nil {
+		if err := pipe.Step(ctx, config.BundleID, step("phased release"), func() error {
+			return p.Client.EnablePhasedRelease(ctx, version.ID)
+		}); err != nil {
 			return err
 		}
 	}
@@ -117,7 +173,30 @@ func (p *Pipe) doRelease(ctx *context.Context, config config.App) error {
 		WithField("version", *version.Attributes.VersionString).
 		Info("submitting to app store")
 
-	return p.Client.SubmitApp(ctx, version.ID)
+	return pipe.Step(ctx, config.BundleID, step("submit"), func() error {
+		return p.Client.SubmitApp(ctx, version.ID)
+	})
+}
+
+func (p *Pipe) saveSnapshot(ctx *context.Context, config config.App, app *asc.App, version *asc.AppStoreVersion) error {
+	dir := ctx.StatePath(snapshot.Directory)
+	if dir == "" {
+		return nil
+	}
+
+	snap, err := snapshot.TakeAppStore(ctx, p.Client, config.BundleID, config.Versions.Platform, app.ID, version.ID)
+	if err != nil {
+		return err
+	}
+
+	path, err := snap.Save(dir)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log.WithField("path", path).Info("saved metadata snapshot")
+
+	return nil
 }
 
 func (p *Pipe) updateVersionDetails(ctx *context.Context, config config.App, app *asc.App, version *asc.AppStoreVersion) error {
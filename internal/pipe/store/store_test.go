@@ -21,10 +21,15 @@ along with Cider.  If not, see <http://www.gnu.org/licenses/>.
 package store
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/cidertool/asc-go/asc"
 	"github.com/cidertool/cider/internal/client/clienttest"
 	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/snapshot"
 	"github.com/cidertool/cider/pkg/config"
 	"github.com/cidertool/cider/pkg/context"
 	"github.com/stretchr/testify/assert"
@@ -36,6 +41,10 @@ func TestStore_Happy(t *testing.T) {
 	ctx := context.New(config.Project{
 		"TEST": {
 			BundleID: "com.test.TEST",
+			ExportCompliance: &config.ExportCompliance{
+				UsesNonExemptEncryption: true,
+				EncryptionDeclaration:   "TEST",
+			},
 			Versions: config.Version{
 				PhasedReleaseEnabled: true,
 				IDFADeclaration: &config.IDFADeclaration{
@@ -71,6 +80,64 @@ func TestStore_Happy(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestStore_Happy_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+	ctx.CurrentDirectory = t.TempDir()
+	ctx.StateDirectory = ".cider"
+	ctx.SkipSubmit = true
+
+	p := Pipe{}
+	p.Client = &clienttest.Client{}
+
+	err := p.Publish(ctx)
+	assert.EqualError(t, err, pipe.ErrSkipSubmitEnabled.Error())
+
+	matches, err := filepath.Glob(filepath.Join(ctx.CurrentDirectory, ".cider", snapshot.Directory, "*-com.test.TEST-appstore.yaml"))
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+
+	info, err := os.Stat(matches[0])
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Size())
+}
+
+func TestStore_Happy_MultiplePlatforms(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Versions: config.Version{
+				Platforms: config.PlatformVersions{
+					config.PlatformMacOS: {},
+					config.PlatformiOS:   {},
+				},
+			},
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+
+	p := Pipe{}
+	p.Client = &clienttest.Client{}
+
+	err := p.Publish(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []context.Resource{
+		{App: "com.test.TEST", Type: "apps", ID: "TEST"},
+		{App: "com.test.TEST", Platform: "iOS", Type: "builds", ID: "TEST"},
+		{App: "com.test.TEST", Platform: "iOS", Type: "appStoreVersions", ID: "TEST"},
+		{App: "com.test.TEST", Platform: "macOS", Type: "builds", ID: "TEST"},
+		{App: "com.test.TEST", Platform: "macOS", Type: "appStoreVersions", ID: "TEST"},
+	}, ctx.Resources.List())
+}
+
 func TestStore_Happy_Skips(t *testing.T) {
 	t.Parallel()
 
@@ -91,6 +158,63 @@ func TestStore_Happy_Skips(t *testing.T) {
 	assert.EqualError(t, err, pipe.ErrSkipSubmitEnabled.Error())
 }
 
+type metadataOnlyClient struct {
+	clienttest.Client
+	versionBuildIDs []string
+	submitted       bool
+}
+
+func (c *metadataOnlyClient) GetBuild(ctx *context.Context, app *asc.App, platform config.Platform, selection *config.BuildSelection) (*asc.Build, error) {
+	return nil, errors.New("no build should be looked up in metadata-only mode")
+}
+
+func (c *metadataOnlyClient) CreateVersionIfNeeded(ctx *context.Context, appID string, buildID string, config config.Version) (*asc.AppStoreVersion, error) {
+	c.versionBuildIDs = append(c.versionBuildIDs, buildID)
+
+	return c.Client.CreateVersionIfNeeded(ctx, appID, buildID, config)
+}
+
+func (c *metadataOnlyClient) SubmitApp(ctx *context.Context, versionID string) error {
+	c.submitted = true
+
+	return nil
+}
+
+func TestStore_Happy_MetadataOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			ExportCompliance: &config.ExportCompliance{
+				UsesNonExemptEncryption: true,
+				EncryptionDeclaration:   "TEST",
+			},
+			Versions: config.Version{
+				Localizations: config.VersionLocalizations{
+					"en-US": {Description: "TEST"},
+				},
+			},
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+	ctx.MetadataOnly = true
+
+	client := &metadataOnlyClient{}
+
+	p := Pipe{}
+	p.Client = client
+
+	err := p.Publish(ctx)
+	assert.EqualError(t, err, pipe.ErrSkipSubmitEnabled.Error())
+	assert.Equal(t, []string{""}, client.versionBuildIDs)
+	assert.False(t, client.submitted)
+	assert.Equal(t, []context.Resource{
+		{App: "com.test.TEST", Type: "apps", ID: "TEST"},
+		{App: "com.test.TEST", Type: "appStoreVersions", ID: "TEST"},
+	}, ctx.Resources.List())
+}
+
 func TestStore_Happy_NoApps(t *testing.T) {
 	t.Parallel()
 
@@ -38,8 +38,6 @@ func (Pipe) String() string {
 
 // Run executes the hooks.
 func (p Pipe) Run(ctx *context.Context) error {
-	var template = template.New(ctx)
-
 	project, err := ctx.RawConfig.Copy()
 
 	if err != nil {
@@ -50,7 +48,7 @@ func (p Pipe) Run(ctx *context.Context) error {
 
 	for appName := range project {
 		app := project[appName]
-		if err := updateApp(&app, template); err != nil {
+		if err := updateApp(&app, newTemplate(ctx, appName)); err != nil {
 			errors = multierror.Append(errors, err)
 		}
 
@@ -62,6 +60,14 @@ func (p Pipe) Run(ctx *context.Context) error {
 	return errors.ErrorOrNil()
 }
 
+// newTemplate returns a template whose version is the one the named app is released with.
+func newTemplate(ctx *context.Context, appName string) *template.Template {
+	actx := *ctx
+	actx.Version = ctx.VersionForApp(appName).Version
+
+	return template.New(&actx)
+}
+
 func updateApp(app *config.App, tmpl *template.Template) error {
 	var errors error
 
@@ -153,6 +159,15 @@ func updateAppVersions(version *config.Version, tmpl *template.Template) error {
 		errors = multierror.Append(errors, err)
 	}
 
+	for platform := range version.Platforms {
+		platformVersion := version.Platforms[platform]
+		if err := updateAppVersions(&platformVersion, tmpl); err != nil {
+			errors = multierror.Append(errors, err)
+		}
+
+		version.Platforms[platform] = platformVersion
+	}
+
 	return errors
 }
 
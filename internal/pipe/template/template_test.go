@@ -153,6 +153,59 @@ func TestTemplateWithBadPatterns(t *testing.T) {
 	assert.Equal(t, 55, merr.Len())
 }
 
+func TestTemplatePlatformVersions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"First": {
+			Versions: config.Version{
+				Copyright: goodTemplatePattern,
+				Platforms: config.PlatformVersions{
+					config.PlatformMacOS: {
+						Copyright: goodTemplatePattern,
+						Localizations: config.VersionLocalizations{
+							"en-US": {Description: goodTemplatePattern},
+						},
+					},
+					config.PlatformTvOS: {
+						Copyright: badTemplatePattern,
+					},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0"
+
+	pipe := Pipe{}
+	err := pipe.Run(ctx)
+	assert.Error(t, err)
+
+	versions := ctx.Config["First"].Versions
+	assert.Equal(t, "1.0", versions.Copyright)
+	assert.Equal(t, "1.0", versions.Platforms[config.PlatformMacOS].Copyright)
+	assert.Equal(t, "1.0", versions.Platforms[config.PlatformMacOS].Localizations["en-US"].Description)
+	assert.Equal(t, goodTemplatePattern, ctx.RawConfig["First"].Versions.Platforms[config.PlatformMacOS].Copyright)
+}
+
+func TestTemplateAppVersions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"First":  {Versions: config.Version{Copyright: goodTemplatePattern}},
+		"Second": {Versions: config.Version{Copyright: goodTemplatePattern}},
+	})
+	ctx.Version = "1.0"
+	ctx.AppVersions = map[string]context.AppVersion{
+		"Second": {Version: "2.0"},
+	}
+
+	pipe := Pipe{}
+	err := pipe.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0", ctx.Config["First"].Versions.Copyright)
+	assert.Equal(t, "2.0", ctx.Config["Second"].Versions.Copyright)
+}
+
 func fullyPopulatedProject(good bool) config.Project {
 	var pattern string
 	if good {
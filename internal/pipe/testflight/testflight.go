@@ -23,18 +23,34 @@ package testflight
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/cidertool/asc-go/asc"
 	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/internal/hooks"
 	"github.com/cidertool/cider/internal/log"
 	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/snapshot"
+	"github.com/cidertool/cider/internal/template"
 	"github.com/cidertool/cider/pkg/config"
 	"github.com/cidertool/cider/pkg/context"
 )
 
+// ErrMixedSilentBetaGroups happens when an app that notifies testers automatically gives its builds to both
+// silent and notifying beta groups. App Store Connect notifies testers per build rather than per group, so
+// the build can't be given to both without notifying the silent groups or not notifying the others.
+type ErrMixedSilentBetaGroups struct {
+	App string
+}
+
+func (e ErrMixedSilentBetaGroups) Error() string {
+	return fmt.Sprintf("app %s gives builds to both silent and notifying beta groups with enableAutoNotify on, so either turn off enableAutoNotify or make every group silent", e.App)
+}
+
 // Pipe is a global hook pipe.
 type Pipe struct {
 	Client client.Client
+	Hooks  hooks.Runner
 }
 
 // String is the name of this pipe.
@@ -42,40 +58,81 @@ func (Pipe) String() string {
 	return "committing to testflight"
 }
 
+// Default validates the Testflight configuration of every app.
+func (Pipe) Default(ctx *context.Context) error {
+	names := make([]string, 0, len(ctx.Config))
+	for name := range ctx.Config {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := validateBetaGroups(name, ctx.Config[name].Testflight); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Publish to Testflight.
 func (p *Pipe) Publish(ctx *context.Context) error {
 	if p.Client == nil {
 		p.Client = client.New(ctx)
 	}
 
-	for _, name := range ctx.AppsToRelease {
-		app, ok := ctx.Config[name]
-		if !ok {
-			return pipe.ErrMissingApp{Name: name}
-		}
-
+	return pipe.ForEachApp(ctx, func(ctx *context.Context, name string, app config.App) error {
 		ctx.Log.WithField("name", name).Info("preparing")
 
-		err := p.doRelease(ctx, app)
-		if err != nil {
+		if err := validateBetaGroups(name, app.Testflight); err != nil {
 			return err
 		}
-	}
 
-	return nil
+		return p.Hooks.Around(ctx, app.Hooks, hooks.AppEnv(name, app), func() error {
+			return p.doRelease(ctx, name, app)
+		})
+	})
 }
 
-func (p *Pipe) doRelease(ctx *context.Context, config config.App) error {
+func (p *Pipe) doRelease(ctx *context.Context, name string, config config.App) error {
 	app, err := p.Client.GetAppForBundleID(ctx, config.BundleID)
 	if err != nil {
 		return err
 	}
 
-	build, err := p.Client.GetBuild(ctx, app)
+	ctx.Resources.Add(config.BundleID, "apps", app.ID)
+
+	return pipe.ForEachPlatform(ctx, config, p.releasePlatform(name, config, app))
+}
+
+func (p *Pipe) releasePlatform(name string, appConfig config.App, app *asc.App) pipe.PlatformFunc {
+	return func(ctx *context.Context, versionConfig config.Version) error {
+		// Builds were never filtered by platform for Testflight before versions could be configured
+		// per platform, so only filter them when they are.
+		var platform config.Platform
+		if pipe.IsMultiPlatform(appConfig) {
+			platform = versionConfig.Platform
+		}
+
+		step := func(stepName string) string {
+			return pipe.PlatformStep(appConfig, platform, stepName)
+		}
+
+		return p.releaseBuild(ctx, name, appConfig, app, platform, step)
+	}
+}
+
+func (p *Pipe) releaseBuild(ctx *context.Context, name string, config config.App, app *asc.App, platform config.Platform, step func(string) string) error {
+	build, err := p.Client.GetBuild(ctx, app, platform, config.BuildSelection)
 	if err != nil {
 		return err
 	}
 
+	if err := pipe.UseBuild(ctx, config.BundleID, string(platform), build.ID); err != nil {
+		return err
+	}
+
 	buildVersionLog := fmt.Sprintf("%s (%s)", ctx.Version, *build.Attributes.Version)
 
 	ctx.Log.WithFields(log.Fields{
@@ -83,23 +140,56 @@ func (p *Pipe) doRelease(ctx *context.Context, config config.App) error {
 		"build": buildVersionLog,
 	}).Info("found resources")
 
+	if config.ExportCompliance != nil {
+		ctx.Log.Info("updating export compliance")
+
+		if err := pipe.Step(ctx, config.BundleID, step("export compliance"), func() error {
+			return p.Client.UpdateBuildExportCompliance(ctx, app.ID, build, *config.ExportCompliance)
+		}); err != nil {
+			return err
+		}
+	}
+
 	if ctx.SkipUpdateMetadata {
 		ctx.Log.Warn("skipping updating metdata")
 	} else {
 		ctx.Log.Info("updating metadata")
-		if err := p.updateBetaDetails(ctx, config, app, build); err != nil {
+		if err := pipe.Step(ctx, config.BundleID, step("metadata"), func() error {
+			if err := p.saveSnapshot(ctx, config, platform, app, build); err != nil {
+				return err
+			}
+
+			return p.updateBetaDetails(ctx, config, app, build)
+		}); err != nil {
 			return err
 		}
 	}
 
 	if !ctx.SkipUpdateMetadata || ctx.OverrideBetaGroups {
-		if err := p.updateBetaGroups(ctx, config, app, build); err != nil {
+		if err := pipe.Step(ctx, config.BundleID, step("beta groups"), func() error {
+			return p.updateBetaGroups(ctx, config, app, build)
+		}); err != nil {
+			return err
+		}
+
+		if err := p.recordPublicLinks(ctx, name, config, app); err != nil {
+			return err
+		}
+	}
+
+	// Groups given on the command line replace the configured ones, so they can't tell which groups are unmanaged.
+	if !ctx.SkipUpdateMetadata && !ctx.OverrideBetaGroups {
+		if err := pipe.Step(ctx, config.BundleID, step("beta group sync"), func() error {
+			return p.syncBetaGroups(ctx, config, app)
+		}); err != nil {
 			return err
 		}
 	}
 
 	if !ctx.SkipUpdateMetadata || ctx.OverrideBetaTesters {
-		if err := p.updateBetaTesters(ctx, config, app, build); err != nil {
+		if err := pipe.Step(ctx, config.BundleID, step("beta testers"), func() error {
+			return p.updateBetaTesters(ctx, config, app, build)
+		}); err != nil {
 			return err
 		}
 	}
@@ -108,11 +198,42 @@ func (p *Pipe) doRelease(ctx *context.Context, config config.App) error {
 		return pipe.ErrSkipSubmitEnabled
 	}
 
+	if !needsBetaAppReview(config.Testflight) {
+		ctx.Log.
+			WithField("build", buildVersionLog).
+			Info("build is only for internal beta groups, skipping beta app review")
+
+		return nil
+	}
+
 	ctx.Log.
 		WithField("build", buildVersionLog).
 		Info("submitting to testflight")
 
-	return p.Client.SubmitBetaApp(ctx, build.ID)
+	return pipe.Step(ctx, config.BundleID, step("submit"), func() error {
+		return p.Client.SubmitBetaApp(ctx, build.ID)
+	})
+}
+
+func (p *Pipe) saveSnapshot(ctx *context.Context, config config.App, platform config.Platform, app *asc.App, build *asc.Build) error {
+	dir := ctx.StatePath(snapshot.Directory)
+	if dir == "" {
+		return nil
+	}
+
+	snap, err := snapshot.TakeTestflight(ctx, p.Client, config.BundleID, platform, app.ID, build.ID)
+	if err != nil {
+		return err
+	}
+
+	path, err := snap.Save(dir)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log.WithField("path", path).Info("saved metadata snapshot")
+
+	return nil
 }
 
 func (p *Pipe) updateBetaDetails(ctx *context.Context, config config.App, app *asc.App, build *asc.Build) error {
@@ -122,15 +243,20 @@ func (p *Pipe) updateBetaDetails(ctx *context.Context, config config.App, app *a
 		return err
 	}
 
+	buildDetails, err := betaBuildDetails(ctx, config.Testflight, app)
+	if err != nil {
+		return err
+	}
+
 	ctx.Log.Info("updating beta build details")
 
-	if err := p.Client.UpdateBetaBuildDetails(ctx, build.ID, config.Testflight); err != nil {
+	if err := p.Client.UpdateBetaBuildDetails(ctx, build.ID, buildDetails); err != nil {
 		return err
 	}
 
-	ctx.Log.Infof("updating %d beta build localizations", len(config.Testflight.Localizations))
+	ctx.Log.Infof("updating %d beta build localizations", len(buildDetails.Localizations))
 
-	if err := p.Client.UpdateBetaBuildLocalizations(ctx, build.ID, config.Testflight.Localizations); err != nil {
+	if err := p.Client.UpdateBetaBuildLocalizations(ctx, build.ID, buildDetails.Localizations); err != nil {
 		return err
 	}
 
@@ -157,8 +283,130 @@ func (p *Pipe) updateBetaGroups(ctx *context.Context, config config.App, app *as
 	return p.Client.AssignBetaGroups(ctx, app.ID, build.ID, config.Testflight.BetaGroups)
 }
 
+// recordPublicLinks logs the public links of the configured beta groups that enable them, and records
+// them under the app's name for the rest of the release.
+func (p *Pipe) recordPublicLinks(ctx *context.Context, name string, config config.App, app *asc.App) error {
+	var enabled = make(map[string]bool)
+
+	for _, group := range config.Testflight.BetaGroups {
+		if group.EnablePublicLink && !group.Internal {
+			enabled[group.Name] = true
+		}
+	}
+
+	if len(enabled) == 0 {
+		return nil
+	}
+
+	links, err := p.Client.GetBetaGroupPublicLinks(ctx, app.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range config.Testflight.BetaGroups {
+		link, ok := links[group.Name]
+		if !ok || !enabled[group.Name] {
+			continue
+		}
+
+		ctx.PublicLinks.Add(name, group.Name, link)
+		ctx.Log.WithFields(log.Fields{
+			"group": group.Name,
+			"url":   link,
+		}).Info("beta group public link")
+	}
+
+	return nil
+}
+
+func (p *Pipe) syncBetaGroups(ctx *context.Context, config config.App, app *asc.App) error {
+	ctx.Log.Info("syncing beta groups")
+
+	return p.Client.SyncBetaGroups(ctx, app.ID, config.Testflight)
+}
+
 func (p *Pipe) updateBetaTesters(ctx *context.Context, config config.App, app *asc.App, build *asc.Build) error {
 	ctx.Log.Info("updating build beta testers")
 
 	return p.Client.AssignBetaTesters(ctx, app.ID, build.ID, config.Testflight.BetaTesters)
 }
+
+// needsBetaAppReview reports whether the build goes to any external testers, who can only
+// test it once it passes Beta App Review.
+func needsBetaAppReview(testflight config.Testflight) bool {
+	if len(testflight.BetaGroups) == 0 || len(testflight.BetaTesters) > 0 {
+		return true
+	}
+
+	for _, group := range testflight.BetaGroups {
+		if !group.Internal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// betaBuildDetails returns the details of the beta build. Auto-notify is turned off when the build is only given to
+// silent beta groups, since testers are notified per build, and the "What to Test" text given for the release
+// replaces the configured one in every locale, or in the app's primary locale if none are configured.
+func betaBuildDetails(ctx *context.Context, testflight config.Testflight, app *asc.App) (config.Testflight, error) {
+	if testflight.EnableAutoNotify && hasSilentBetaGroups(testflight) {
+		ctx.Log.Warn("not notifying testers of the build automatically, because it's only given to silent beta groups")
+
+		testflight.EnableAutoNotify = false
+	}
+
+	if ctx.WhatsNew == "" {
+		return testflight, nil
+	}
+
+	whatsNew, err := template.New(ctx).Apply(ctx.WhatsNew)
+	if err != nil {
+		return testflight, err
+	}
+
+	var localizations = make(config.TestflightLocalizations, len(testflight.Localizations))
+
+	for locale, loc := range testflight.Localizations {
+		loc.WhatsNew = whatsNew
+		localizations[locale] = loc
+	}
+
+	if len(localizations) == 0 && app.Attributes != nil && app.Attributes.PrimaryLocale != nil {
+		localizations[*app.Attributes.PrimaryLocale] = config.TestflightLocalization{WhatsNew: whatsNew}
+	}
+
+	testflight.Localizations = localizations
+
+	return testflight, nil
+}
+
+// validateBetaGroups returns an error if the named app would give its builds to both silent and notifying beta groups.
+func validateBetaGroups(name string, testflight config.Testflight) error {
+	if testflight.EnableAutoNotify && hasSilentBetaGroups(testflight) && !allSilentBetaGroups(testflight) {
+		return ErrMixedSilentBetaGroups{App: name}
+	}
+
+	return nil
+}
+
+func hasSilentBetaGroups(testflight config.Testflight) bool {
+	for _, group := range testflight.BetaGroups {
+		if group.Silent {
+			return true
+		}
+	}
+
+	return false
+}
+
+func allSilentBetaGroups(testflight config.Testflight) bool {
+	for _, group := range testflight.BetaGroups {
+		if !group.Silent {
+			return false
+		}
+	}
+
+	return true
+}
@@ -21,10 +21,13 @@ along with Cider.  If not, see <http://www.gnu.org/licenses/>.
 package testflight
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/cidertool/cider/internal/client/clienttest"
 	"github.com/cidertool/cider/internal/pipe"
+	"github.com/cidertool/cider/internal/snapshot"
 	"github.com/cidertool/cider/pkg/config"
 	"github.com/cidertool/cider/pkg/context"
 	"github.com/stretchr/testify/assert"
@@ -36,6 +39,10 @@ func TestTestflight_Happy(t *testing.T) {
 	ctx := context.New(config.Project{
 		"TEST": {
 			BundleID: "com.test.TEST",
+			ExportCompliance: &config.ExportCompliance{
+				UsesNonExemptEncryption: true,
+				EncryptionDeclaration:   "TEST",
+			},
 			Testflight: config.Testflight{
 				ReviewDetails: &config.ReviewDetails{
 					Contact: &config.ContactPerson{
@@ -64,6 +71,62 @@ func TestTestflight_Happy(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTestflight_Happy_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+	ctx.CurrentDirectory = t.TempDir()
+	ctx.StateDirectory = ".cider"
+	ctx.SkipSubmit = true
+
+	p := Pipe{}
+	p.Client = &clienttest.Client{}
+
+	err := p.Publish(ctx)
+	assert.EqualError(t, err, pipe.ErrSkipSubmitEnabled.Error())
+
+	matches, err := filepath.Glob(filepath.Join(ctx.CurrentDirectory, ".cider", snapshot.Directory, "*-com.test.TEST-testflight.yaml"))
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+
+	info, err := os.Stat(matches[0])
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Size())
+}
+
+func TestTestflight_Happy_MultiplePlatforms(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Versions: config.Version{
+				Platforms: config.PlatformVersions{
+					config.PlatformMacOS: {},
+					config.PlatformiOS:   {},
+				},
+			},
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+
+	p := Pipe{}
+	p.Client = &clienttest.Client{}
+
+	err := p.Publish(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []context.Resource{
+		{App: "com.test.TEST", Type: "apps", ID: "TEST"},
+		{App: "com.test.TEST", Platform: "iOS", Type: "builds", ID: "TEST"},
+		{App: "com.test.TEST", Platform: "macOS", Type: "builds", ID: "TEST"},
+	}, ctx.Resources.List())
+}
+
 func TestTestflight_Happy_Skips(t *testing.T) {
 	t.Parallel()
 
@@ -83,6 +146,242 @@ func TestTestflight_Happy_Skips(t *testing.T) {
 	assert.EqualError(t, err, pipe.ErrSkipSubmitEnabled.Error())
 }
 
+type submitRecordingClient struct {
+	clienttest.Client
+	submitted bool
+}
+
+func (c *submitRecordingClient) SubmitBetaApp(ctx *context.Context, buildID string) error {
+	c.submitted = true
+
+	return nil
+}
+
+func TestTestflight_Happy_InternalGroupsOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Testflight: config.Testflight{
+				BetaGroups: []config.BetaGroup{
+					{Name: "Team", Internal: true, HasAccessToAllBuilds: true},
+					{Name: "QA", Internal: true},
+				},
+			},
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+
+	client := &submitRecordingClient{}
+	p := Pipe{}
+	p.Client = client
+
+	err := p.Publish(ctx)
+	assert.NoError(t, err)
+	assert.False(t, client.submitted)
+}
+
+func TestTestflight_Happy_PublicLinks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Testflight: config.Testflight{
+				BetaGroups: []config.BetaGroup{
+					{Name: "TEST", EnablePublicLink: true},
+					{Name: "Private"},
+				},
+			},
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+
+	p := Pipe{}
+	p.Client = &clienttest.Client{}
+
+	err := p.Publish(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []context.PublicLink{
+		{App: "TEST", Group: "TEST", URL: "https://testflight.apple.com/join/TEST"},
+	}, ctx.PublicLinks.List())
+}
+
+type buildDetailsRecordingClient struct {
+	clienttest.Client
+	details       config.Testflight
+	localizations config.TestflightLocalizations
+}
+
+func (c *buildDetailsRecordingClient) UpdateBetaBuildDetails(ctx *context.Context, buildID string, config config.Testflight) error {
+	c.details = config
+
+	return nil
+}
+
+func (c *buildDetailsRecordingClient) UpdateBetaBuildLocalizations(ctx *context.Context, buildID string, config config.TestflightLocalizations) error {
+	c.localizations = config
+
+	return nil
+}
+
+func TestTestflight_Happy_SilentGroups(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Testflight: config.Testflight{
+				EnableAutoNotify: true,
+				BetaGroups: []config.BetaGroup{
+					{Name: "Quiet", Silent: true},
+					{Name: "Quieter", Silent: true},
+				},
+			},
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+
+	client := &buildDetailsRecordingClient{}
+	p := Pipe{}
+	p.Client = client
+
+	err := p.Publish(ctx)
+	assert.NoError(t, err)
+	assert.False(t, client.details.EnableAutoNotify)
+	assert.True(t, ctx.Config["TEST"].Testflight.EnableAutoNotify)
+}
+
+func TestTestflight_Err_MixedSilentGroups(t *testing.T) {
+	t.Parallel()
+
+	testflight := config.Testflight{
+		EnableAutoNotify: true,
+		BetaGroups: []config.BetaGroup{
+			{Name: "TEST"},
+			{Name: "Quiet", Silent: true},
+		},
+	}
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID:   "com.test.TEST",
+			Testflight: testflight,
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+
+	client := &buildDetailsRecordingClient{}
+	p := Pipe{}
+	p.Client = client
+
+	err := p.Default(ctx)
+	assert.Equal(t, ErrMixedSilentBetaGroups{App: "TEST"}, err)
+	err = p.Publish(ctx)
+	assert.Equal(t, ErrMixedSilentBetaGroups{App: "TEST"}, err)
+	assert.Empty(t, client.details)
+
+	testflight.EnableAutoNotify = false
+	ctx = context.New(config.Project{
+		"TEST": {
+			BundleID:   "com.test.TEST",
+			Testflight: testflight,
+		},
+	})
+	err = p.Default(ctx)
+	assert.NoError(t, err)
+}
+
+func TestTestflight_Happy_WhatsNew(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+			Testflight: config.Testflight{
+				Localizations: config.TestflightLocalizations{
+					"en-US": {Description: "TEST", WhatsNew: "Configured"},
+					"fr-FR": {Description: "TEST", WhatsNew: "Configured"},
+				},
+			},
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+	ctx.Version = "1.0"
+	ctx.WhatsNew = "Try version {{ .version }}"
+
+	client := &buildDetailsRecordingClient{}
+	p := Pipe{}
+	p.Client = client
+
+	err := p.Publish(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, config.TestflightLocalizations{
+		"en-US": {Description: "TEST", WhatsNew: "Try version 1.0"},
+		"fr-FR": {Description: "TEST", WhatsNew: "Try version 1.0"},
+	}, client.localizations)
+	assert.Equal(t, "Configured", ctx.Config["TEST"].Testflight.Localizations["en-US"].WhatsNew)
+}
+
+func TestTestflight_Happy_WhatsNewPrimaryLocale(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+	ctx.WhatsNew = "Try the new onboarding"
+
+	client := &buildDetailsRecordingClient{}
+	p := Pipe{}
+	p.Client = client
+
+	err := p.Publish(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, config.TestflightLocalizations{
+		"en-US": {WhatsNew: "Try the new onboarding"},
+	}, client.localizations)
+}
+
+func TestTestflight_Err_WhatsNewTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{
+		"TEST": {
+			BundleID: "com.test.TEST",
+		},
+	})
+	ctx.AppsToRelease = []string{"TEST"}
+	ctx.WhatsNew = "{{ .Nope"
+
+	p := Pipe{}
+	p.Client = &clienttest.Client{}
+
+	err := p.Publish(ctx)
+	assert.Error(t, err)
+}
+
+func TestNeedsBetaAppReview(t *testing.T) {
+	t.Parallel()
+
+	internal := config.BetaGroup{Name: "Team", Internal: true}
+	external := config.BetaGroup{Name: "Public"}
+
+	assert.True(t, needsBetaAppReview(config.Testflight{}))
+	assert.True(t, needsBetaAppReview(config.Testflight{
+		BetaGroups: []config.BetaGroup{internal, external},
+	}))
+	assert.True(t, needsBetaAppReview(config.Testflight{
+		BetaGroups:  []config.BetaGroup{internal},
+		BetaTesters: []config.BetaTester{{Email: "test@example.com"}},
+	}))
+	assert.False(t, needsBetaAppReview(config.Testflight{
+		BetaGroups: []config.BetaGroup{internal},
+	}))
+}
+
 func TestTestflight_Happy_NoApps(t *testing.T) {
 	t.Parallel()
 
@@ -22,14 +22,20 @@ along with Cider.  If not, see <http://www.gnu.org/licenses/>.
 package pipeline
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/cidertool/cider/internal/pipe/announce"
+	"github.com/cidertool/cider/internal/pipe/artifact"
+	"github.com/cidertool/cider/internal/pipe/checkpoint"
 	"github.com/cidertool/cider/internal/pipe/defaults"
 	"github.com/cidertool/cider/internal/pipe/env"
 	"github.com/cidertool/cider/internal/pipe/git"
+	"github.com/cidertool/cider/internal/pipe/plugin"
 	"github.com/cidertool/cider/internal/pipe/publish"
 	"github.com/cidertool/cider/internal/pipe/semver"
 	"github.com/cidertool/cider/internal/pipe/template"
+	"github.com/cidertool/cider/pkg/config"
 	"github.com/cidertool/cider/pkg/context"
 )
 
@@ -45,9 +51,102 @@ type Piper interface {
 // nolint: gochecknoglobals
 var Pipeline = []Piper{
 	env.Pipe{},
+	artifact.Pipe{},
 	git.Pipe{},
 	semver.Pipe{},
 	template.Pipe{},
 	defaults.Pipe{},
+	checkpoint.Pipe{},
 	publish.Pipe{},
+	announce.Pipe{},
+}
+
+// ErrPluginPosition happens when a plugin does not set exactly one of before or after.
+var ErrPluginPosition = errors.New("plugins must set exactly one of before or after")
+
+// ErrUnknownStep happens when a plugin refers to a step that is not in the pipeline.
+type ErrUnknownStep struct {
+	Plugin string
+	Step   string
+}
+
+func (e ErrUnknownStep) Error() string {
+	return fmt.Sprintf("plugin %s refers to unknown step %s", e.Plugin, e.Step)
+}
+
+// StepName returns the name plugins use to refer to the given pipe, or an empty string
+// if plugins can't refer to it.
+func StepName(p Piper) string {
+	switch p.(type) {
+	case env.Pipe:
+		return "env"
+	case artifact.Pipe:
+		return "artifact"
+	case git.Pipe:
+		return "git"
+	case semver.Pipe:
+		return "semver"
+	case template.Pipe:
+		return "template"
+	case defaults.Pipe:
+		return "defaults"
+	case checkpoint.Pipe:
+		return "checkpoint"
+	case publish.Pipe:
+		return "publish"
+	case announce.Pipe:
+		return "announce"
+	}
+
+	return ""
+}
+
+// WithPlugins returns a copy of the pipeline with a pipe for each plugin inserted next to
+// the step it refers to. Plugins referring to the same step keep their configured order.
+func WithPlugins(pipeline []Piper, plugins []config.Plugin) ([]Piper, error) {
+	var before = map[string][]Piper{}
+
+	var after = map[string][]Piper{}
+
+	for _, p := range plugins {
+		var step = p.Before
+
+		var target = before
+
+		if p.After != "" {
+			step = p.After
+			target = after
+		}
+
+		if (p.Before == "") == (p.After == "") {
+			return nil, fmt.Errorf("%s: %w", p.Name, ErrPluginPosition)
+		}
+
+		var found bool
+
+		for _, pipe := range pipeline {
+			if StepName(pipe) == step {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, ErrUnknownStep{Plugin: p.Name, Step: step}
+		}
+
+		target[step] = append(target[step], plugin.Pipe{Plugin: p})
+	}
+
+	var result = make([]Piper, 0, len(pipeline)+len(plugins))
+
+	for _, pipe := range pipeline {
+		name := StepName(pipe)
+		result = append(result, before[name]...)
+		result = append(result, pipe)
+		result = append(result, after[name]...)
+	}
+
+	return result, nil
 }
@@ -20,4 +20,129 @@ along with Cider.  If not, see <http://www.gnu.org/licenses/>.
 
 package pipeline
 
-// this package has no testable statements
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/cidertool/cider/internal/middleware"
+	"github.com/cidertool/cider/internal/pipe/checkpoint"
+	"github.com/cidertool/cider/internal/pipe/git"
+	"github.com/cidertool/cider/internal/pipe/plugin"
+	"github.com/cidertool/cider/internal/pipe/semver"
+	"github.com/cidertool/cider/internal/pipe/template"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPlugins(t *testing.T) {
+	t.Parallel()
+
+	pipes, err := WithPlugins(Pipeline, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Pipeline, pipes)
+
+	plugins := []config.Plugin{
+		{Name: "one", After: "publish"},
+		{Name: "two", Before: "env"},
+		{Name: "three", After: "git"},
+		{Name: "four", After: "publish"},
+	}
+
+	pipes, err = WithPlugins(Pipeline, plugins)
+	assert.NoError(t, err)
+	assert.Len(t, pipes, len(Pipeline)+len(plugins))
+
+	var names []string
+
+	for _, p := range pipes {
+		if pp, ok := p.(plugin.Pipe); ok {
+			names = append(names, pp.Plugin.Name)
+		} else {
+			names = append(names, StepName(p))
+		}
+	}
+
+	assert.Equal(t, []string{
+		"two", "env", "artifact", "git", "three", "semver", "template", "defaults", "checkpoint", "publish", "one", "four", "announce",
+	}, names)
+}
+
+func TestStepName_Pipeline(t *testing.T) {
+	t.Parallel()
+
+	var seen = map[string]bool{}
+
+	for _, p := range Pipeline {
+		name := StepName(p)
+		assert.NotEmpty(t, name, p.String())
+		assert.False(t, seen[name], name)
+
+		seen[name] = true
+	}
+}
+
+func TestWithPlugins_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := WithPlugins(Pipeline, []config.Plugin{{Name: "one"}})
+	assert.True(t, errors.Is(err, ErrPluginPosition))
+
+	_, err = WithPlugins(Pipeline, []config.Plugin{{Name: "one", Before: "env", After: "env"}})
+	assert.True(t, errors.Is(err, ErrPluginPosition))
+
+	_, err = WithPlugins(Pipeline, []config.Plugin{{Name: "one", After: "nothing"}})
+	assert.Equal(t, ErrUnknownStep{Plugin: "one", Step: "nothing"}, err)
+}
+
+func TestPipeline_ResumeWithStateDirectoryInWorkTree(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", "git@github.com:cidertool/cider.git"},
+		{"-c", "user.name=Cider", "-c", "user.email=cider@example.com", "commit", "--quiet", "--allow-empty", "-m", "initial"},
+		{"tag", "1.0.0"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	project := config.Project{"My App": {BundleID: "com.app"}}
+
+	// The pipes that run before anything is published to App Store Connect
+	pipes := []Piper{git.Pipe{}, semver.Pipe{}, template.Pipe{}, checkpoint.Pipe{}}
+
+	run := func(resume bool) *context.Context {
+		ctx := context.New(project)
+		ctx.CurrentDirectory = dir
+		ctx.StateDirectory = ".cider"
+		ctx.AppsToRelease = []string{"My App"}
+		ctx.PublishMode = context.PublishModeAppStore
+		ctx.Resume = resume
+
+		for _, pipe := range pipes {
+			err := middleware.ErrHandler(pipe.Run)(ctx)
+			assert.NoError(t, err, pipe.String())
+		}
+
+		return ctx
+	}
+
+	// An interrupted release leaves its checkpoint behind in the working tree
+	ctx := run(false)
+	assert.Equal(t, "1.0.0", ctx.Version)
+	err := ctx.Checkpoint.Complete("com.app", "metadata", nil)
+	assert.NoError(t, err)
+
+	ctx = run(true)
+	assert.True(t, ctx.Checkpoint.Done("com.app", "metadata"))
+}
@@ -0,0 +1,323 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plist
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	binaryTrailerSize = 32
+	maxDepth          = 512
+)
+
+// nolint: gochecknoglobals
+var (
+	binaryMagic = []byte("bplist00")
+	// Dates in binary property lists are seconds since the start of 2001.
+	referenceDate = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type binaryDecoder struct {
+	data       []byte
+	offsets    []uint64
+	offsetSize int
+	refSize    int
+	visiting   map[uint64]bool
+}
+
+func decodeBinary(data []byte) (interface{}, error) {
+	if len(data) < len(binaryMagic)+binaryTrailerSize {
+		return nil, ErrInvalid{"binary data is too short"}
+	}
+
+	trailer := data[len(data)-binaryTrailerSize:]
+	d := binaryDecoder{
+		data:       data,
+		offsetSize: int(trailer[6]),
+		refSize:    int(trailer[7]),
+		visiting:   map[uint64]bool{},
+	}
+	count := binary.BigEndian.Uint64(trailer[8:16])
+	top := binary.BigEndian.Uint64(trailer[16:24])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:32])
+
+	if d.offsetSize < 1 || d.offsetSize > 8 || d.refSize < 1 || d.refSize > 8 {
+		return nil, ErrInvalid{"bad integer sizes in trailer"}
+	}
+
+	tableEnd := uint64(len(data) - binaryTrailerSize)
+	if count == 0 || tableOffset >= tableEnd || count > (tableEnd-tableOffset)/uint64(d.offsetSize) {
+		return nil, ErrInvalid{"bad offset table"}
+	}
+
+	d.offsets = make([]uint64, count)
+
+	for i := range d.offsets {
+		start := tableOffset + uint64(i*d.offsetSize)
+		d.offsets[i] = readUint(data[start : start+uint64(d.offsetSize)])
+	}
+
+	return d.object(top, 0)
+}
+
+func (d *binaryDecoder) object(ref uint64, depth int) (interface{}, error) {
+	if ref >= uint64(len(d.offsets)) {
+		return nil, ErrInvalid{"object reference out of range"}
+	}
+
+	if depth > maxDepth || d.visiting[ref] {
+		return nil, ErrInvalid{"objects are nested too deeply"}
+	}
+
+	d.visiting[ref] = true
+	defer delete(d.visiting, ref)
+
+	offset := d.offsets[ref]
+	if offset >= uint64(len(d.data)) {
+		return nil, ErrInvalid{"object offset out of range"}
+	}
+
+	marker := d.data[offset]
+	kind, info := marker>>4, marker&0x0F
+	offset++
+
+	switch kind {
+	case 0x0:
+		switch info {
+		case 0x8:
+			return false, nil
+		case 0x9:
+			return true, nil
+		}
+
+		return nil, nil
+	case 0x1:
+		b, err := d.bytes(offset, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+
+		return readInt(b), nil
+	case 0x2:
+		b, err := d.bytes(offset, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+
+		return readReal(b)
+	case 0x3:
+		b, err := d.bytes(offset, 8)
+		if err != nil {
+			return nil, err
+		}
+
+		seconds := math.Float64frombits(binary.BigEndian.Uint64(b))
+
+		return referenceDate.Add(time.Duration(seconds * float64(time.Second))), nil
+	case 0x4:
+		offset, n, err := d.count(offset, info)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := d.bytes(offset, n)
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte(nil), b...), nil
+	case 0x5:
+		offset, n, err := d.count(offset, info)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := d.bytes(offset, n)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(b), nil
+	case 0x6:
+		offset, n, err := d.count(offset, info)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := d.bytes(offset, n*2)
+		if err != nil {
+			return nil, err
+		}
+
+		units := make([]uint16, n)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(b[i*2:])
+		}
+
+		return string(utf16.Decode(units)), nil
+	case 0x8:
+		b, err := d.bytes(offset, int(info)+1)
+		if err != nil {
+			return nil, err
+		}
+
+		return readUint(b), nil
+	case 0xA, 0xC:
+		return d.array(offset, info, depth)
+	case 0xD:
+		return d.dict(offset, info, depth)
+	}
+
+	return nil, ErrInvalid{"unknown object type"}
+}
+
+func (d *binaryDecoder) array(offset uint64, info byte, depth int) (interface{}, error) {
+	offset, n, err := d.count(offset, info)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := d.bytes(offset, n*d.refSize)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, n)
+
+	for i := range values {
+		values[i], err = d.object(readUint(refs[i*d.refSize:(i+1)*d.refSize]), depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func (d *binaryDecoder) dict(offset uint64, info byte, depth int) (interface{}, error) {
+	offset, n, err := d.count(offset, info)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := d.bytes(offset, n*d.refSize*2)
+	if err != nil {
+		return nil, err
+	}
+
+	dict := make(Dict, n)
+
+	for i := 0; i < n; i++ {
+		key, err := d.object(readUint(refs[i*d.refSize:(i+1)*d.refSize]), depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := key.(string)
+		if !ok {
+			return nil, ErrInvalid{"dictionary key is not a string"}
+		}
+
+		valueRef := readUint(refs[(n+i)*d.refSize : (n+i+1)*d.refSize])
+
+		dict[name], err = d.object(valueRef, depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dict, nil
+}
+
+// count returns the number of elements in an object and the offset its contents start at.
+// Counts of 15 or more are stored in an integer object following the marker.
+func (d *binaryDecoder) count(offset uint64, info byte) (uint64, int, error) {
+	if info != 0x0F {
+		return offset, int(info), nil
+	}
+
+	b, err := d.bytes(offset, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if b[0]>>4 != 0x1 || b[0]&0x0F > 3 {
+		return 0, 0, ErrInvalid{"bad object count"}
+	}
+
+	size := 1 << (b[0] & 0x0F)
+
+	b, err = d.bytes(offset+1, size)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n := readUint(b)
+	if n > uint64(len(d.data)) {
+		return 0, 0, ErrInvalid{"object count out of range"}
+	}
+
+	return offset + 1 + uint64(size), int(n), nil
+}
+
+func (d *binaryDecoder) bytes(offset uint64, n int) ([]byte, error) {
+	if n < 0 || offset > uint64(len(d.data)) || uint64(n) > uint64(len(d.data))-offset {
+		return nil, ErrInvalid{"object extends past the end of the data"}
+	}
+
+	return d.data[offset : offset+uint64(n)], nil
+}
+
+func readUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+
+	return n
+}
+
+func readInt(b []byte) interface{} {
+	switch len(b) {
+	case 8:
+		return int64(binary.BigEndian.Uint64(b))
+	case 16:
+		// 128-bit integers are only used for values that don't fit in a signed 64-bit one.
+		return binary.BigEndian.Uint64(b[8:])
+	}
+
+	return int64(readUint(b))
+}
+
+func readReal(b []byte) (interface{}, error) {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	}
+
+	return nil, ErrInvalid{"bad real size"}
+}
@@ -0,0 +1,84 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package plist decodes property lists in the XML and binary formats used by Apple platforms
+package plist
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrNotDict happens when the top-level value of a property list is not a dictionary.
+var ErrNotDict = errors.New("property list does not contain a dictionary")
+
+// ErrInvalid happens when a property list is malformed.
+type ErrInvalid struct {
+	Reason string
+}
+
+func (e ErrInvalid) Error() string {
+	return fmt.Sprintf("invalid property list: %s", e.Reason)
+}
+
+// Dict is a decoded property list dictionary. Values are strings, int64s, uint64s, float64s,
+// bools, []byte, time.Time, []interface{} or Dict.
+type Dict map[string]interface{}
+
+// String returns the string value stored under key, or an empty string if there is none.
+func (d Dict) String(key string) string {
+	s, _ := d[key].(string)
+
+	return s
+}
+
+// Dict returns the dictionary stored under key, or nil if there is none.
+func (d Dict) Dict(key string) Dict {
+	dict, _ := d[key].(Dict)
+
+	return dict
+}
+
+// Decode decodes a property list whose top-level value is a dictionary. The format is
+// detected from the contents of data.
+func Decode(data []byte) (Dict, error) {
+	var (
+		value interface{}
+		err   error
+	)
+
+	if bytes.HasPrefix(data, binaryMagic) {
+		value, err = decodeBinary(data)
+	} else {
+		value, err = decodeXML(data)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	dict, ok := value.(Dict)
+	if !ok {
+		return nil, ErrNotDict
+	}
+
+	return dict, nil
+}
@@ -0,0 +1,107 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	expected := Dict{
+		"CFBundleIdentifier":         "com.app.bundleid",
+		"CFBundleShortVersionString": "1.2.3",
+		"CFBundleVersion":            "42",
+		"CFBundleDisplayName":        "Café App",
+		"LSRequiresIPhoneOS":         true,
+		"UIDeviceFamily":             []interface{}{int64(1), int64(2)},
+		"MinimumOSVersion":           "14.0",
+		"Scale":                      2.5,
+		"Large":                      int64(1 << 40),
+		"Blob":                       []byte{0, 1, 2},
+		"Built":                      time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC),
+		"LongDescription":            "a string that is longer than fifteen characters",
+		"Nested":                     Dict{"Key": "Value"},
+	}
+
+	for _, name := range []string{"Info.bplist", "Info.xml.plist"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		assert.NoError(t, err)
+
+		dict, err := Decode(data)
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected, dict, name)
+		assert.Equal(t, "1.2.3", dict.String("CFBundleShortVersionString"))
+		assert.Equal(t, "", dict.String("Scale"))
+		assert.Equal(t, Dict{"Key": "Value"}, dict.Dict("Nested"))
+		assert.Nil(t, dict.Dict("Missing"))
+	}
+}
+
+func TestDecode_NotDict(t *testing.T) {
+	t.Parallel()
+
+	dict, err := Decode([]byte(`<plist version="1.0"><array><string>a</string></array></plist>`))
+	assert.Equal(t, ErrNotDict, err)
+	assert.Nil(t, dict)
+}
+
+func TestDecode_ErrXML(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range []string{
+		``,
+		`<plist><dict><key>A</key>`,
+		`<plist><dict><string>A</string></dict></plist>`,
+		`<plist><dict><key>A</key><integer>one</integer></dict></plist>`,
+		`<plist><dict><key>A</key><unknown/></dict></plist>`,
+		`<plist><dict><key>A</key><data>!!</data></dict></plist>`,
+		`<plist><dict><key>A</key><date>yesterday</date></dict></plist>`,
+		`<plist><dict><key>A</key><real>pi</real></dict></plist>`,
+	} {
+		_, err := Decode([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestDecode_ErrBinary(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "Info.bplist"))
+	assert.NoError(t, err)
+
+	_, err = Decode(data[:20])
+	assert.EqualError(t, err, "invalid property list: binary data is too short")
+
+	_, err = Decode(data[:len(data)-40])
+	assert.Error(t, err)
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[len(corrupt)-9] = 0xFF
+	_, err = Decode(corrupt)
+	assert.EqualError(t, err, "invalid property list: object reference out of range")
+}
@@ -0,0 +1,194 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package plist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type xmlDecoder struct {
+	*xml.Decoder
+}
+
+func decodeXML(data []byte) (interface{}, error) {
+	d := xmlDecoder{xml.NewDecoder(bytes.NewReader(data))}
+	d.Strict = false
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, ErrInvalid{"no plist element found"}
+		} else if err != nil {
+			return nil, ErrInvalid{err.Error()}
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local != "plist" {
+				return d.value(start, 0)
+			}
+
+			return d.next(0)
+		}
+	}
+}
+
+// next decodes the next value element, skipping anything in between.
+func (d xmlDecoder) next(depth int) (interface{}, error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, ErrInvalid{"expected a value"}
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			return d.value(tok, depth)
+		case xml.EndElement:
+			return nil, ErrInvalid{"expected a value before </" + tok.Name.Local + ">"}
+		}
+	}
+}
+
+func (d xmlDecoder) value(start xml.StartElement, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, ErrInvalid{"values are nested too deeply"}
+	}
+
+	switch start.Name.Local {
+	case "dict":
+		return d.dict(depth)
+	case "array":
+		return d.array(depth)
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, ErrInvalid{err.Error()}
+		}
+
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return nil, ErrInvalid{err.Error()}
+	}
+
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+	case "integer":
+		return parseInteger(strings.TrimSpace(text))
+	case "real":
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, ErrInvalid{err.Error()}
+		}
+
+		return f, nil
+	case "date":
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(text))
+		if err != nil {
+			return nil, ErrInvalid{err.Error()}
+		}
+
+		return t, nil
+	case "data":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, ErrInvalid{err.Error()}
+		}
+
+		return b, nil
+	}
+
+	return nil, ErrInvalid{"unknown element <" + start.Name.Local + ">"}
+}
+
+func (d xmlDecoder) dict(depth int) (interface{}, error) {
+	dict := Dict{}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, ErrInvalid{"unterminated dict"}
+		}
+
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			return dict, nil
+		case xml.StartElement:
+			if tok.Name.Local != "key" {
+				return nil, ErrInvalid{"expected <key> in dict, found <" + tok.Name.Local + ">"}
+			}
+
+			var key string
+			if err := d.DecodeElement(&key, &tok); err != nil {
+				return nil, ErrInvalid{err.Error()}
+			}
+
+			dict[key], err = d.next(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (d xmlDecoder) array(depth int) (interface{}, error) {
+	values := []interface{}{}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, ErrInvalid{"unterminated array"}
+		}
+
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			return values, nil
+		case xml.StartElement:
+			value, err := d.value(tok, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, value)
+		}
+	}
+}
+
+func parseInteger(s string) (interface{}, error) {
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return i, nil
+	}
+
+	u, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return nil, ErrInvalid{err.Error()}
+	}
+
+	return u, nil
+}
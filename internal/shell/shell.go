@@ -25,6 +25,7 @@ import (
 	"bytes"
 	"os/exec"
 	"strings"
+	"sync"
 
 	"github.com/alessio/shellescape"
 	"github.com/cidertool/cider/internal/log"
@@ -35,6 +36,7 @@ import (
 // testing and client design easier.
 type Shell interface {
 	NewCommand(program string, args ...string) *exec.Cmd
+	NewScript(script string) *exec.Cmd
 	Exec(cmd *exec.Cmd) (*CompletedProcess, error)
 	Exists(program string) bool
 	CurrentDirectory() string
@@ -55,17 +57,27 @@ type CompletedProcess struct {
 	Stderr     string
 }
 
+type outputBuffer interface {
+	String() string
+}
+
 func newCompletedProcess(cmd *exec.Cmd) *CompletedProcess {
-	stdout, ok := cmd.Stdout.(*bytes.Buffer)
+	stdout, ok := cmd.Stdout.(outputBuffer)
 	if !ok {
 		return nil
 	}
 
-	stderr, ok := cmd.Stderr.(*bytes.Buffer)
+	stderr, ok := cmd.Stderr.(outputBuffer)
 	if !ok {
 		return nil
 	}
 
+	for _, w := range []outputBuffer{stdout, stderr} {
+		if w, ok := w.(*lineWriter); ok {
+			w.Flush()
+		}
+	}
+
 	var stdoutString, stderrString string
 
 	if stdout != nil {
@@ -104,6 +116,20 @@ func (sh *loginShell) NewCommand(program string, arg ...string) *exec.Cmd {
 	return cmd
 }
 
+// NewScript takes a script and constructs an exec.Cmd object that evaluates
+// it with sh, which can be manipulated and fed to Exec(). Unlike NewCommand,
+// the script is passed along as-is, so it can use pipes, variables, etc.
+func (sh *loginShell) NewScript(script string) *exec.Cmd {
+	cmd := exec.CommandContext(sh.Context, "sh", "-c", script) // #nosec
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Dir = sh.Context.CurrentDirectory
+
+	return cmd
+}
+
 func escapeArgs(args []string) []string {
 	cp := make([]string, len(args))
 
@@ -147,3 +173,67 @@ func (sh *loginShell) Exists(program string) bool {
 func (sh *loginShell) CurrentDirectory() string {
 	return sh.Context.CurrentDirectory
 }
+
+// StreamOutput forwards each line the command writes to its standard output
+// and error to fn as it is written. The output is still recorded in the
+// CompletedProcess returned by Exec. The command writes to its standard output
+// and error concurrently, so fn is called with a lock held.
+func StreamOutput(cmd *exec.Cmd, fn func(line string)) {
+	var mu sync.Mutex
+
+	locked := func(line string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fn(line)
+	}
+
+	StreamStdout(cmd, locked)
+	StreamStderr(cmd, locked)
+}
+
+// StreamStdout forwards each line the command writes to its standard output to fn.
+func StreamStdout(cmd *exec.Cmd, fn func(line string)) {
+	cmd.Stdout = &lineWriter{fn: fn}
+}
+
+// StreamStderr forwards each line the command writes to its standard error to fn.
+func StreamStderr(cmd *exec.Cmd, fn func(line string)) {
+	cmd.Stderr = &lineWriter{fn: fn}
+}
+
+// lineWriter is a buffer that calls a function for every complete line written to it.
+type lineWriter struct {
+	buf  bytes.Buffer
+	line []byte
+	fn   func(line string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	w.line = append(w.line, p...)
+
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.fn(strings.TrimRight(string(w.line[:i]), "\r"))
+		w.line = w.line[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush calls the function with the last line written, if it did not end in a newline.
+func (w *lineWriter) Flush() {
+	if len(w.line) > 0 {
+		w.fn(strings.TrimRight(string(w.line), "\r"))
+		w.line = nil
+	}
+}
+
+func (w *lineWriter) String() string {
+	return w.buf.String()
+}
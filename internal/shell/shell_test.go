@@ -48,6 +48,33 @@ func TestExec_Error(t *testing.T) {
 	assert.NotNil(t, ps)
 }
 
+func TestExec_Script(t *testing.T) {
+	t.Parallel()
+
+	sh := New(context.New(config.Project{}))
+	cmd := sh.NewScript("echo dogs | tr d c")
+	ps, err := sh.Exec(cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, "cogs", ps.Stdout)
+}
+
+func TestExec_StreamOutput(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+
+	sh := New(context.New(config.Project{}))
+	cmd := sh.NewScript("echo dogs; echo cats >&2; printf birds")
+	StreamOutput(cmd, func(line string) {
+		lines = append(lines, line)
+	})
+	ps, err := sh.Exec(cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, "dogs\nbirds", ps.Stdout)
+	assert.Equal(t, "cats", ps.Stderr)
+	assert.ElementsMatch(t, []string{"dogs", "cats", "birds"}, lines)
+}
+
 func TestEscapeArgs(t *testing.T) {
 	t.Parallel()
 
@@ -66,6 +66,11 @@ func (sh *Shell) NewCommand(name string, arg ...string) *exec.Cmd {
 	return exec.Command(name, arg...) // #nosec
 }
 
+// NewScript takes a script and constructs a new exec.Cmd instance that evaluates it with sh.
+func (sh *Shell) NewScript(script string) *exec.Cmd {
+	return exec.Command("sh", "-c", script) // #nosec
+}
+
 // Exec executes the command.
 func (sh *Shell) Exec(cmd *exec.Cmd) (*shell.CompletedProcess, error) {
 	if sh.index >= len(sh.Commands) {
@@ -83,6 +88,21 @@ func (sh *Shell) Exec(cmd *exec.Cmd) (*shell.CompletedProcess, error) {
 	}
 
 	currentCommand := sh.Commands[sh.index]
+
+	if cmd.Stdout != nil {
+		fmt.Fprint(cmd.Stdout, currentCommand.Stdout)
+	}
+
+	if cmd.Stderr != nil {
+		fmt.Fprint(cmd.Stderr, currentCommand.Stderr)
+	}
+
+	for _, w := range []interface{}{cmd.Stdout, cmd.Stderr} {
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+	}
+
 	ps := shell.CompletedProcess{
 		Name:       cmd.Path,
 		Args:       cmd.Args,
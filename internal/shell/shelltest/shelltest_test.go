@@ -64,6 +64,9 @@ func TestShell(t *testing.T) {
 	cmd := sh.NewCommand("echo", "true")
 	assert.NotNil(t, cmd)
 
+	script := sh.NewScript("echo true")
+	assert.Equal(t, []string{"sh", "-c", "echo true"}, script.Args)
+
 	proc, err := sh.Exec(cmd)
 	assert.NoError(t, err)
 	assert.NotNil(t, proc)
@@ -0,0 +1,357 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package snapshot records the App Store Connect metadata Cider is about to change, so it can be restored later
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"gopkg.in/yaml.v2"
+)
+
+// Directory is the name of the directory inside the state directory that snapshots are saved to.
+const Directory = "snapshots"
+
+const timestampLayout = "20060102T150405Z"
+
+// Snapshot is the remote metadata of an app as it was before a release changed it.
+//
+// Assets such as previews, screenshots and review attachments are not recorded, and neither
+// are the start dates of price tiers. The password of the demo account is left out so that
+// it isn't saved to disk.
+type Snapshot struct {
+	BundleID               string                         `yaml:"bundleID"`
+	Platform               config.Platform                `yaml:"platform,omitempty"`
+	Mode                   context.PublishMode            `yaml:"mode"`
+	Date                   time.Time                      `yaml:"date"`
+	AppID                  string                         `yaml:"appID"`
+	VersionID              string                         `yaml:"versionID,omitempty"`
+	BuildID                string                         `yaml:"buildID,omitempty"`
+	InitialRelease         bool                           `yaml:"initialRelease,omitempty"`
+	Localizations          config.AppLocalizations        `yaml:"localizations,omitempty"`
+	VersionLocalizations   config.VersionLocalizations    `yaml:"versionLocalizations,omitempty"`
+	ReviewDetails          *config.ReviewDetails          `yaml:"reviewDetails,omitempty"`
+	App                    *AppDetails                    `yaml:"app,omitempty"`
+	Version                *VersionDetails                `yaml:"version,omitempty"`
+	BetaAppLocalizations   config.TestflightLocalizations `yaml:"betaAppLocalizations,omitempty"`
+	BetaBuildLocalizations config.TestflightLocalizations `yaml:"betaBuildLocalizations,omitempty"`
+	BetaReviewDetails      *config.ReviewDetails          `yaml:"betaReviewDetails,omitempty"`
+}
+
+// AppDetails are the app-wide settings of an app, and the categories of the app info being prepared for submission.
+type AppDetails struct {
+	AppInfoID             string               `yaml:"appInfoID"`
+	PrimaryLocale         string               `yaml:"primaryLocale,omitempty"`
+	UsesThirdPartyContent *bool                `yaml:"usesThirdPartyContent,omitempty"`
+	Availability          *config.Availability `yaml:"availability,omitempty"`
+	Categories            *config.Categories   `yaml:"categories,omitempty"`
+}
+
+// VersionDetails are the settings of a version that aren't localized.
+type VersionDetails struct {
+	Copyright           string     `yaml:"copyright,omitempty"`
+	ReleaseType         string     `yaml:"releaseType,omitempty"`
+	EarliestReleaseDate *time.Time `yaml:"earliestReleaseDate,omitempty"`
+}
+
+// TakeAppStore reads the app info localizations, app details, version localizations, version
+// details and review details of the given app and version.
+func TakeAppStore(ctx *context.Context, c client.Client, bundleID string, platform config.Platform, appID, versionID string) (*Snapshot, error) {
+	snapshot := Snapshot{
+		BundleID:       bundleID,
+		Platform:       platform,
+		Mode:           context.PublishModeAppStore,
+		Date:           ctx.Date.UTC(),
+		AppID:          appID,
+		VersionID:      versionID,
+		InitialRelease: ctx.VersionIsInitialRelease,
+	}
+
+	var err error
+
+	if snapshot.Localizations, err = c.GetAppLocalizations(ctx, appID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.App, err = takeAppDetails(ctx, c, appID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.VersionLocalizations, err = c.GetVersionLocalizations(ctx, versionID); err != nil {
+		return nil, err
+	}
+
+	version, err := c.GetVersionDetails(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Version = &VersionDetails{
+		Copyright:           version.Copyright,
+		ReleaseType:         string(version.ReleaseType),
+		EarliestReleaseDate: version.EarliestReleaseDate,
+	}
+
+	reviewDetails, err := c.GetReviewDetails(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.ReviewDetails = withoutPassword(reviewDetails)
+
+	return &snapshot, nil
+}
+
+func takeAppDetails(ctx *context.Context, c client.Client, appID string) (*AppDetails, error) {
+	appInfo, err := c.GetAppInfo(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := c.GetAppDetails(ctx, appID, appInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppDetails{
+		AppInfoID:             appInfo.ID,
+		PrimaryLocale:         app.PrimaryLocale,
+		UsesThirdPartyContent: app.UsesThirdPartyContent,
+		Availability:          app.Availability,
+		Categories:            app.Categories,
+	}, nil
+}
+
+// TakeTestflight reads the beta app localizations, beta build localizations and beta review
+// details of the given app and build.
+func TakeTestflight(ctx *context.Context, c client.Client, bundleID string, platform config.Platform, appID, buildID string) (*Snapshot, error) {
+	snapshot := Snapshot{
+		BundleID: bundleID,
+		Platform: platform,
+		Mode:     context.PublishModeTestflight,
+		Date:     ctx.Date.UTC(),
+		AppID:    appID,
+		BuildID:  buildID,
+	}
+
+	var err error
+
+	if snapshot.BetaAppLocalizations, err = c.GetBetaAppLocalizations(ctx, appID); err != nil {
+		return nil, err
+	}
+
+	if snapshot.BetaBuildLocalizations, err = c.GetBetaBuildLocalizations(ctx, buildID); err != nil {
+		return nil, err
+	}
+
+	reviewDetails, err := c.GetBetaReviewDetails(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.BetaReviewDetails = withoutPassword(reviewDetails)
+
+	return &snapshot, nil
+}
+
+// Load reads a snapshot from the file at the given path.
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path) // #nosec
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := yaml.UnmarshalStrict(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+
+	return &snapshot, nil
+}
+
+// Filename returns the name the snapshot is saved under, made up of its timestamp, bundle ID,
+// platform and mode.
+func (s *Snapshot) Filename() string {
+	if s.Platform == "" {
+		return fmt.Sprintf("%s-%s-%s.yaml", s.Date.UTC().Format(timestampLayout), s.BundleID, s.Mode)
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%s.yaml", s.Date.UTC().Format(timestampLayout), s.BundleID, s.Platform, s.Mode)
+}
+
+// Save writes the snapshot to a timestamped file in the given directory and returns its path.
+// Snapshots may contain contact details and the demo account name, so the file is only readable by its owner.
+func (s *Snapshot) Save(dir string) (string, error) {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, s.Filename())
+
+	return path, os.WriteFile(path, data, 0600)
+}
+
+// Restore re-applies the snapshot's metadata through the same client methods used to release.
+//
+// Locales that were added after the snapshot was taken are left untouched, and so is the
+// password of the demo account.
+func (s *Snapshot) Restore(ctx *context.Context, c client.Client) error {
+	ctx.VersionIsInitialRelease = s.InitialRelease
+
+	if len(s.Localizations) > 0 {
+		ctx.Log.Infof("restoring %d app localizations", len(s.Localizations))
+
+		if err := c.UpdateAppLocalizations(ctx, s.AppID, s.Localizations); err != nil {
+			return err
+		}
+	}
+
+	if s.App != nil {
+		ctx.Log.Info("restoring app details")
+
+		if err := c.UpdateApp(ctx, s.AppID, s.App.AppInfoID, s.VersionID, config.App{
+			PrimaryLocale:         s.App.PrimaryLocale,
+			UsesThirdPartyContent: s.App.UsesThirdPartyContent,
+			Availability:          s.App.Availability,
+			Categories:            s.App.Categories,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(s.VersionLocalizations) > 0 && s.VersionID != "" {
+		ctx.Log.Infof("restoring %d app store version localizations", len(s.VersionLocalizations))
+
+		if err := c.UpdateVersionLocalizations(ctx, s.VersionID, s.VersionLocalizations); err != nil {
+			return err
+		}
+	}
+
+	if s.Version != nil && s.VersionID != "" {
+		ctx.Log.Info("restoring version details")
+
+		if err := c.UpdateVersionDetails(ctx, s.VersionID, s.Version.configVersion()); err != nil {
+			return err
+		}
+	}
+
+	if s.ReviewDetails != nil && s.VersionID != "" {
+		ctx.Log.Info("restoring review details")
+
+		current, err := c.GetReviewDetails(ctx, s.VersionID)
+		if err != nil {
+			return err
+		}
+
+		if err := c.UpdateReviewDetails(ctx, s.VersionID, withPassword(*s.ReviewDetails, current)); err != nil {
+			return err
+		}
+	}
+
+	if len(s.BetaAppLocalizations) > 0 {
+		ctx.Log.Infof("restoring %d beta app localizations", len(s.BetaAppLocalizations))
+
+		if err := c.UpdateBetaAppLocalizations(ctx, s.AppID, s.BetaAppLocalizations); err != nil {
+			return err
+		}
+	}
+
+	if len(s.BetaBuildLocalizations) > 0 && s.BuildID != "" {
+		ctx.Log.Infof("restoring %d beta build localizations", len(s.BetaBuildLocalizations))
+
+		if err := c.UpdateBetaBuildLocalizations(ctx, s.BuildID, s.BetaBuildLocalizations); err != nil {
+			return err
+		}
+	}
+
+	if s.BetaReviewDetails != nil {
+		ctx.Log.Info("restoring beta review details")
+
+		current, err := c.GetBetaReviewDetails(ctx, s.AppID)
+		if err != nil {
+			return err
+		}
+
+		if err := c.UpdateBetaReviewDetails(ctx, s.AppID, withPassword(*s.BetaReviewDetails, current)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// configVersion returns the version details in the form the client takes them.
+func (d VersionDetails) configVersion() config.Version {
+	version := config.Version{
+		Copyright:           d.Copyright,
+		EarliestReleaseDate: d.EarliestReleaseDate,
+	}
+
+	switch d.ReleaseType {
+	case string(config.ReleaseTypeManual):
+		version.ReleaseType = config.ReleaseTypeManual
+	case string(config.ReleaseTypeAfterApproval):
+		version.ReleaseType = config.ReleaseTypeAfterApproval
+	case string(config.ReleaseTypeScheduled):
+		version.ReleaseType = config.ReleaseTypeScheduled
+	}
+
+	return version
+}
+
+// withoutPassword returns a copy of the review details without the password of the demo account.
+func withoutPassword(details *config.ReviewDetails) *config.ReviewDetails {
+	if details == nil || details.DemoAccount == nil {
+		return details
+	}
+
+	redacted := *details
+	account := *details.DemoAccount
+	account.Password = ""
+	redacted.DemoAccount = &account
+
+	return &redacted
+}
+
+// withPassword returns the review details with the password of the demo account in current, which snapshots
+// don't record.
+func withPassword(details config.ReviewDetails, current *config.ReviewDetails) config.ReviewDetails {
+	if details.DemoAccount == nil || current == nil || current.DemoAccount == nil {
+		return details
+	}
+
+	account := *details.DemoAccount
+	account.Password = current.DemoAccount.Password
+	details.DemoAccount = &account
+
+	return details
+}
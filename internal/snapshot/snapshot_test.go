@@ -0,0 +1,206 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cidertool/cider/internal/client/clienttest"
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/cidertool/cider/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot_AppStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.Date = time.Date(2020, time.June, 1, 12, 30, 0, 0, time.UTC)
+	ctx.VersionIsInitialRelease = true
+
+	snap, err := TakeAppStore(ctx, &clienttest.Client{}, "com.test.TEST", config.PlatformiOS, "APP", "VERSION")
+	assert.NoError(t, err)
+	assert.Equal(t, context.PublishModeAppStore, snap.Mode)
+	assert.Equal(t, "VERSION", snap.VersionID)
+	assert.True(t, snap.InitialRelease)
+	assert.Equal(t, "TEST", snap.Localizations["en-US"].Name)
+	assert.Equal(t, "TEST", snap.VersionLocalizations["en-US"].Description)
+	assert.Equal(t, "TEST", snap.ReviewDetails.Notes)
+	assert.Empty(t, snap.BetaAppLocalizations)
+
+	dir := filepath.Join(t.TempDir(), Directory)
+	path, err := snap.Save(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20200601T123000Z-com.test.TEST-iOS-appstore.yaml"), path)
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, snap, loaded)
+
+	ctx = context.New(config.Project{})
+	err = loaded.Restore(ctx, &clienttest.Client{})
+	assert.NoError(t, err)
+	assert.True(t, ctx.VersionIsInitialRelease)
+}
+
+func TestSnapshot_Testflight(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	ctx.Date = time.Date(2020, time.June, 1, 12, 30, 0, 0, time.UTC)
+
+	snap, err := TakeTestflight(ctx, &clienttest.Client{}, "com.test.TEST", "", "APP", "BUILD")
+	assert.NoError(t, err)
+	assert.Equal(t, context.PublishModeTestflight, snap.Mode)
+	assert.Equal(t, "BUILD", snap.BuildID)
+	assert.Equal(t, "TEST", snap.BetaAppLocalizations["en-US"].Description)
+	assert.Equal(t, "TEST", snap.BetaBuildLocalizations["en-US"].WhatsNew)
+	assert.Equal(t, "TEST", snap.BetaReviewDetails.Notes)
+	assert.Empty(t, snap.VersionLocalizations)
+	assert.Equal(t, "20200601T123000Z-com.test.TEST-testflight.yaml", snap.Filename())
+
+	err = snap.Restore(ctx, &clienttest.Client{})
+	assert.NoError(t, err)
+}
+
+type demoAccountClient struct {
+	clienttest.Client
+	password string
+	updated  []config.ReviewDetails
+}
+
+func (c *demoAccountClient) GetReviewDetails(ctx *context.Context, versionID string) (*config.ReviewDetails, error) {
+	return &config.ReviewDetails{
+		Notes:       "TEST",
+		DemoAccount: &config.DemoAccount{Required: true, Name: "demo", Password: c.password},
+	}, nil
+}
+
+func (c *demoAccountClient) UpdateReviewDetails(ctx *context.Context, versionID string, details config.ReviewDetails) error {
+	c.updated = append(c.updated, details)
+
+	return nil
+}
+
+func TestSnapshot_DemoAccountPassword(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+
+	snap, err := TakeAppStore(ctx, &demoAccountClient{password: "hunter2"}, "com.test.TEST", config.PlatformiOS, "APP", "VERSION")
+	assert.NoError(t, err)
+	assert.Equal(t, &config.DemoAccount{Required: true, Name: "demo"}, snap.ReviewDetails.DemoAccount)
+
+	path, err := snap.Save(t.TempDir())
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+
+	// Restoring keeps the password that is currently set
+	client := &demoAccountClient{password: "changed"}
+	err = snap.Restore(ctx, client)
+	assert.NoError(t, err)
+	assert.Len(t, client.updated, 1)
+	assert.Equal(t, &config.DemoAccount{Required: true, Name: "demo", Password: "changed"}, client.updated[0].DemoAccount)
+}
+
+type detailsClient struct {
+	clienttest.Client
+	releaseDate    time.Time
+	updatedApp     *config.App
+	updatedVersion *config.Version
+}
+
+func (c *detailsClient) GetVersionDetails(ctx *context.Context, versionID string) (*config.Version, error) {
+	return &config.Version{
+		Copyright:           "2020 Me",
+		ReleaseType:         config.ReleaseTypeScheduled,
+		EarliestReleaseDate: &c.releaseDate,
+	}, nil
+}
+
+func (c *detailsClient) UpdateApp(ctx *context.Context, appID, appInfoID, versionID string, app config.App) error {
+	c.updatedApp = &app
+
+	return nil
+}
+
+func (c *detailsClient) UpdateVersionDetails(ctx *context.Context, versionID string, version config.Version) error {
+	c.updatedVersion = &version
+
+	return nil
+}
+
+func TestSnapshot_AppAndVersionDetails(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.New(config.Project{})
+	client := &detailsClient{releaseDate: time.Date(2020, time.July, 1, 9, 0, 0, 0, time.UTC)}
+
+	snap, err := TakeAppStore(ctx, client, "com.test.TEST", config.PlatformiOS, "APP", "VERSION")
+	assert.NoError(t, err)
+	assert.Equal(t, &VersionDetails{
+		Copyright:           "2020 Me",
+		ReleaseType:         string(config.ReleaseTypeScheduled),
+		EarliestReleaseDate: &client.releaseDate,
+	}, snap.Version)
+
+	path, err := snap.Save(t.TempDir())
+	assert.NoError(t, err)
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, snap, loaded)
+
+	err = loaded.Restore(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.App{
+		PrimaryLocale:         snap.App.PrimaryLocale,
+		UsesThirdPartyContent: snap.App.UsesThirdPartyContent,
+		Availability:          snap.App.Availability,
+		Categories:            snap.App.Categories,
+	}, client.updatedApp)
+	assert.Equal(t, "en-US", client.updatedApp.PrimaryLocale)
+	assert.Equal(t, "SOCIAL_NETWORKING", client.updatedApp.Categories.Primary)
+	assert.Equal(t, []string{"USA"}, client.updatedApp.Availability.Territories)
+	assert.Equal(t, "2020 Me", client.updatedVersion.Copyright)
+	assert.Equal(t, config.ReleaseTypeScheduled, client.updatedVersion.ReleaseType)
+	assert.True(t, client.releaseDate.Equal(*client.updatedVersion.EarliestReleaseDate))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "invalid.yaml")
+	err = os.WriteFile(path, []byte("notAField: true"), 0600)
+	assert.NoError(t, err)
+
+	_, err = Load(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,319 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package testers reads and writes beta tester lists as CSV, and plans the changes that bring
+// an app's beta testers in line with a list
+package testers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+)
+
+// Header is the first row of the CSV files written by Write. Read skips it if present.
+var Header = []string{"email", "first name", "last name", "groups"}
+
+// groupSeparator separates the beta groups of a tester within the groups column.
+const groupSeparator = ";"
+
+// ErrMissingEmail happens when a row of a tester list has no email.
+type ErrMissingEmail struct {
+	Line int
+}
+
+func (e ErrMissingEmail) Error() string {
+	return fmt.Sprintf("beta tester on line %d has no email", e.Line)
+}
+
+// ErrDuplicateEmail happens when a tester list contains the same email more than once.
+type ErrDuplicateEmail struct {
+	Email string
+	Line  int
+}
+
+func (e ErrDuplicateEmail) Error() string {
+	return fmt.Sprintf("beta tester %s on line %d is listed more than once", e.Email, e.Line)
+}
+
+// Read parses a CSV list of beta testers with the columns email, first name, last name and groups.
+// Only the email is required. Groups are separated by semicolons.
+func Read(r io.Reader) ([]client.Tester, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var testers []client.Tester
+
+	var seen = make(map[string]bool)
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), Header[0]) {
+			continue
+		}
+
+		tester := client.Tester{Email: field(record, 0)}
+		if tester.Email == "" {
+			return nil, ErrMissingEmail{Line: line}
+		}
+
+		key := strings.ToLower(tester.Email)
+		if seen[key] {
+			return nil, ErrDuplicateEmail{Email: tester.Email, Line: line}
+		}
+
+		seen[key] = true
+		tester.FirstName = field(record, 1)
+		tester.LastName = field(record, 2)
+
+		for _, group := range strings.Split(field(record, 3), groupSeparator) {
+			if group = strings.TrimSpace(group); group != "" {
+				tester.Groups = append(tester.Groups, group)
+			}
+		}
+
+		testers = append(testers, tester)
+	}
+
+	return testers, nil
+}
+
+func field(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[i])
+}
+
+// Write writes a CSV list of beta testers that can be read with Read, sorted by email.
+func Write(w io.Writer, testers []client.Tester) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(Header); err != nil {
+		return err
+	}
+
+	for _, tester := range Sorted(testers) {
+		if err := writer.Write([]string{
+			tester.Email,
+			tester.FirstName,
+			tester.LastName,
+			strings.Join(tester.Groups, groupSeparator),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// Sorted returns a copy of the testers sorted by email.
+func Sorted(testers []client.Tester) []client.Tester {
+	var sorted = make([]client.Tester, len(testers))
+
+	copy(sorted, testers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Email) < strings.ToLower(sorted[j].Email)
+	})
+
+	return sorted
+}
+
+// Index returns a map of lowercased emails to the testers with that email.
+func Index(testers []client.Tester) map[string]client.Tester {
+	var index = make(map[string]client.Tester, len(testers))
+
+	for _, tester := range testers {
+		index[strings.ToLower(tester.Email)] = tester
+	}
+
+	return index
+}
+
+// Additions returns the changes that add the wanted testers to an app whose current testers are
+// remote. Testers that don't exist yet are returned without an ID and with all their groups. Testers
+// that exist but are missing from some of their groups are returned with their ID and the missing
+// groups. Nothing is ever removed.
+func Additions(remote, wanted []client.Tester) []client.Tester {
+	var existing = Index(remote)
+
+	var additions []client.Tester
+
+	for _, tester := range wanted {
+		current, ok := existing[strings.ToLower(tester.Email)]
+		if !ok {
+			additions = append(additions, tester)
+
+			continue
+		}
+
+		var inGroup = make(map[string]bool, len(current.Groups))
+
+		for _, group := range current.Groups {
+			inGroup[group] = true
+		}
+
+		var missing []string
+
+		for _, group := range tester.Groups {
+			if !inGroup[group] {
+				missing = append(missing, group)
+			}
+		}
+
+		if len(missing) > 0 {
+			additions = append(additions, client.Tester{
+				ID:        current.ID,
+				Email:     current.Email,
+				FirstName: current.FirstName,
+				LastName:  current.LastName,
+				Groups:    missing,
+			})
+		}
+	}
+
+	return additions
+}
+
+// PruneFilter selects the beta testers to prune. A tester is selected if they match any of the
+// enabled criteria, have been seen for long enough and aren't kept.
+type PruneFilter struct {
+	// InactiveDays selects the testers who had no sessions over the last number of days, if set.
+	InactiveDays int
+	// NotAccepted selects the testers who never accepted their invitation.
+	NotAccepted bool
+	// MinAge is how long ago testers must have been first seen to be selected. Testers that were
+	// never seen aren't selected unless MinAge is zero.
+	MinAge time.Duration
+	// Seen is when each tester was first seen, by ID.
+	Seen Seen
+	// Now is the time the age of testers is measured at.
+	Now time.Time
+	// Keep lists the emails of testers that are never selected.
+	Keep []string
+}
+
+// Prune returns the testers selected by the filter, given the activity of the testers by ID.
+func Prune(remote []client.Tester, activity map[string]client.TesterActivity, filter PruneFilter) []client.Tester {
+	var keep = make(map[string]bool, len(filter.Keep))
+
+	for _, email := range filter.Keep {
+		keep[strings.ToLower(email)] = true
+	}
+
+	var selected []client.Tester
+
+	for _, tester := range remote {
+		if keep[strings.ToLower(tester.Email)] {
+			continue
+		}
+
+		if filter.MinAge > 0 {
+			seen, ok := filter.Seen[tester.ID]
+			if !ok || filter.Now.Sub(seen) < filter.MinAge {
+				continue
+			}
+		}
+
+		act := activity[tester.ID]
+
+		if (filter.InactiveDays > 0 && act.Sessions == 0) ||
+			(filter.NotAccepted && act.State == client.BetaTesterStateInvited) {
+			selected = append(selected, tester)
+		}
+	}
+
+	return selected
+}
+
+// Seen maps the IDs of beta testers to when they were first seen. App Store Connect doesn't report when
+// testers were invited, so this is how long they are known to have had access for.
+type Seen map[string]time.Time
+
+// ReadSeen reads the file at the path, or returns an empty record if there is no file yet.
+func ReadSeen(path string) (Seen, error) {
+	var seen = make(Seen)
+
+	data, err := os.ReadFile(path) // #nosec
+	if errors.Is(err, os.ErrNotExist) {
+		return seen, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &seen); err != nil {
+		return nil, err
+	}
+
+	return seen, nil
+}
+
+// Update records the testers that weren't seen before as first seen now, and forgets the testers that
+// are gone.
+func (s Seen) Update(remote []client.Tester, now time.Time) {
+	var current = make(map[string]bool, len(remote))
+
+	for _, tester := range remote {
+		current[tester.ID] = true
+
+		if _, ok := s[tester.ID]; !ok {
+			s[tester.ID] = now.UTC()
+		}
+	}
+
+	for id := range s {
+		if !current[id] {
+			delete(s, id)
+		}
+	}
+}
+
+// Write saves the record to the file at the path.
+func (s Seen) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
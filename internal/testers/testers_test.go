@@ -0,0 +1,174 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package testers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cidertool/cider/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	list, err := Read(strings.NewReader(`Email,First Name,Last Name,Groups
+a@example.com, Ada, Lovelace, QA; Friends
+b@example.com,,,
+c@example.com
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Tester{
+		{Email: "a@example.com", FirstName: "Ada", LastName: "Lovelace", Groups: []string{"QA", "Friends"}},
+		{Email: "b@example.com"},
+		{Email: "c@example.com"},
+	}, list)
+}
+
+func TestRead_Err(t *testing.T) {
+	t.Parallel()
+
+	_, err := Read(strings.NewReader("a@example.com\n,Ada\n"))
+	assert.EqualError(t, err, "beta tester on line 2 has no email")
+
+	_, err = Read(strings.NewReader("a@example.com\nA@example.com\n"))
+	assert.EqualError(t, err, "beta tester A@example.com on line 2 is listed more than once")
+
+	_, err = Read(strings.NewReader("\"a@example.com\n"))
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	list := []client.Tester{
+		{ID: "2", Email: "b@example.com"},
+		{ID: "1", Email: "a@example.com", FirstName: "Ada", LastName: "Lovelace", Groups: []string{"QA", "Friends"}},
+	}
+
+	var b strings.Builder
+
+	err := Write(&b, list)
+	assert.NoError(t, err)
+	assert.Equal(t, `email,first name,last name,groups
+a@example.com,Ada,Lovelace,QA;Friends
+b@example.com,,,
+`, b.String())
+
+	read, err := Read(strings.NewReader(b.String()))
+	assert.NoError(t, err)
+	assert.Len(t, read, 2)
+}
+
+func TestAdditions(t *testing.T) {
+	t.Parallel()
+
+	remote := []client.Tester{
+		{ID: "1", Email: "a@example.com", Groups: []string{"QA"}},
+		{ID: "2", Email: "b@example.com", Groups: []string{"QA", "Friends"}},
+	}
+	wanted := []client.Tester{
+		{Email: "A@example.com", Groups: []string{"QA", "Friends"}},
+		{Email: "b@example.com", Groups: []string{"QA"}},
+		{Email: "c@example.com", FirstName: "Cee", Groups: []string{"Friends"}},
+	}
+
+	assert.Equal(t, []client.Tester{
+		{ID: "1", Email: "a@example.com", Groups: []string{"Friends"}},
+		{Email: "c@example.com", FirstName: "Cee", Groups: []string{"Friends"}},
+	}, Additions(remote, wanted))
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+
+	remote := []client.Tester{
+		{ID: "1", Email: "active@example.com"},
+		{ID: "2", Email: "inactive@example.com"},
+		{ID: "3", Email: "invited@example.com"},
+		{ID: "4", Email: "Kept@example.com"},
+	}
+	activity := map[string]client.TesterActivity{
+		"1": {State: "INSTALLED", Sessions: 4},
+		"2": {State: "INSTALLED"},
+		"3": {State: client.BetaTesterStateInvited},
+	}
+
+	assert.Empty(t, Prune(remote, activity, PruneFilter{}))
+	assert.Equal(t, []client.Tester{remote[2]}, Prune(remote, activity, PruneFilter{NotAccepted: true}))
+	assert.Equal(t, []client.Tester{remote[1], remote[2]}, Prune(remote, activity, PruneFilter{
+		InactiveDays: 30,
+		Keep:         []string{"kept@example.com"},
+	}))
+}
+
+func TestPrune_MinAge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+	remote := []client.Tester{
+		{ID: "1", Email: "old@example.com"},
+		{ID: "2", Email: "new@example.com"},
+		{ID: "3", Email: "unseen@example.com"},
+	}
+	activity := map[string]client.TesterActivity{
+		"1": {State: client.BetaTesterStateInvited},
+		"2": {State: client.BetaTesterStateInvited},
+		"3": {State: client.BetaTesterStateInvited},
+	}
+	filter := PruneFilter{
+		NotAccepted: true,
+		MinAge:      30 * 24 * time.Hour,
+		Seen: Seen{
+			"1": now.AddDate(0, 0, -45),
+			"2": now.AddDate(0, 0, -1),
+		},
+		Now: now,
+	}
+
+	assert.Equal(t, []client.Tester{remote[0]}, Prune(remote, activity, filter))
+}
+
+func TestSeen(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state", "testers.json")
+	before := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	seen, err := ReadSeen(path)
+	assert.NoError(t, err)
+	assert.Empty(t, seen)
+
+	seen.Update([]client.Tester{{ID: "1"}, {ID: "2"}}, before)
+	seen.Update([]client.Tester{{ID: "2"}, {ID: "3"}}, now)
+	assert.Equal(t, Seen{"2": before, "3": now}, seen)
+
+	err = seen.Write(path)
+	assert.NoError(t, err)
+
+	read, err := ReadSeen(path)
+	assert.NoError(t, err)
+	assert.Equal(t, seen, read)
+}
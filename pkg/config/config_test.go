@@ -38,6 +38,35 @@ func TestValidConfiguration(t *testing.T) {
 	assert.Len(t, f, 1)
 }
 
+func TestConfigurationWithSettings(t *testing.T) {
+	t.Parallel()
+
+	f, settings, err := LoadWithSettings("testdata/settings.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, Project{"My App": {BundleID: "com.myproject.MyApp"}}, f)
+	assert.Equal(t, Settings{
+		Hooks: &Hooks{
+			Before: []Hook{{Command: "make archive"}},
+		},
+		Plugins: []Plugin{
+			{Name: "upload dsyms", Command: "./scripts/upload-dsyms", After: "publish"},
+		},
+		TagTemplate: "release-{{ .version }}",
+	}, settings)
+
+	f2, err := Load("testdata/settings.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, f, f2)
+}
+
+func TestSettingsKeyIsReserved(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadReader(strings.NewReader("project:\n  id: com.myproject.MyApp\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `"project" is reserved for project-wide settings`)
+}
+
 func TestMissingConfiguration(t *testing.T) {
 	t.Parallel()
 
@@ -128,3 +157,53 @@ func TestAppsMatching(t *testing.T) {
 	matches = p.AppsMatching([]string{}, true)
 	assert.ElementsMatch(t, matches, []string{"App1", "App2", "App3"})
 }
+
+func TestVersionForPlatforms(t *testing.T) {
+	t.Parallel()
+
+	single := Version{Platform: PlatformiOS, Copyright: "2020 Me"}
+	assert.Equal(t, []Version{single}, single.ForPlatforms())
+
+	v := Version{
+		Platform:  PlatformiOS,
+		Copyright: "2020 Me",
+		Localizations: VersionLocalizations{
+			"en-US": {Description: "Shared"},
+			"ja":    {Description: "Shared"},
+		},
+		ReleaseType: ReleaseTypeManual,
+		Platforms: PlatformVersions{
+			PlatformTvOS: {
+				ReleaseType: ReleaseTypeAfterApproval,
+			},
+			PlatformMacOS: {
+				Copyright:            "2020 Mac",
+				PhasedReleaseEnabled: true,
+				Localizations: VersionLocalizations{
+					"en-US": {Description: "Mac"},
+				},
+			},
+		},
+	}
+
+	versions := v.ForPlatforms()
+	assert.Len(t, versions, 2)
+
+	mac := versions[0]
+	assert.Equal(t, PlatformMacOS, mac.Platform)
+	assert.Equal(t, "2020 Mac", mac.Copyright)
+	assert.Equal(t, ReleaseTypeManual, mac.ReleaseType)
+	assert.True(t, mac.PhasedReleaseEnabled)
+	assert.Equal(t, VersionLocalizations{
+		"en-US": {Description: "Mac"},
+		"ja":    {Description: "Shared"},
+	}, mac.Localizations)
+	assert.Nil(t, mac.Platforms)
+
+	tv := versions[1]
+	assert.Equal(t, PlatformTvOS, tv.Platform)
+	assert.Equal(t, "2020 Me", tv.Copyright)
+	assert.Equal(t, ReleaseTypeAfterApproval, tv.ReleaseType)
+	assert.False(t, tv.PhasedReleaseEnabled)
+	assert.Equal(t, v.Localizations, tv.Localizations)
+}
@@ -0,0 +1,297 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	checkpointFileName = "checkpoint.json"
+	buildResourceType  = "builds"
+)
+
+// CheckpointKey identifies the release a checkpoint belongs to. A checkpoint can only be
+// resumed by a release with the same key.
+type CheckpointKey struct {
+	Version     string `json:"version"`
+	Build       string `json:"build"`
+	AppVersions string `json:"appVersions,omitempty"`
+	PublishMode string `json:"publishMode"`
+	ConfigHash  string `json:"configHash"`
+}
+
+// Checkpoint is a thread-safe record of the progress of a release, which is saved to disk
+// as it changes so an interrupted release can be resumed.
+type Checkpoint struct {
+	path  string
+	mu    sync.Mutex
+	state checkpointState
+}
+
+type checkpointState struct {
+	Key     CheckpointKey               `json:"key"`
+	Apps    map[string]*checkpointApp   `json:"apps"`
+	Uploads map[string]checkpointUpload `json:"uploads"`
+}
+
+type checkpointApp struct {
+	Steps     []string   `json:"steps"`
+	Resources []Resource `json:"resources,omitempty"`
+}
+
+type checkpointUpload struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// NewCheckpoint returns an empty checkpoint that will be saved in the given directory.
+func NewCheckpoint(dir string, key CheckpointKey) *Checkpoint {
+	return &Checkpoint{
+		path: filepath.Join(dir, checkpointFileName),
+		state: checkpointState{
+			Key:     key,
+			Apps:    make(map[string]*checkpointApp),
+			Uploads: make(map[string]checkpointUpload),
+		},
+	}
+}
+
+// LoadCheckpoint loads the checkpoint saved in the given directory. If there is no saved
+// checkpoint, or it belongs to a release with a different key, an empty checkpoint is
+// returned instead, and resumed is false.
+func LoadCheckpoint(dir string, key CheckpointKey) (checkpoint *Checkpoint, resumed bool, err error) {
+	checkpoint = NewCheckpoint(dir, key)
+
+	data, err := os.ReadFile(checkpoint.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	var state checkpointState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false, err
+	}
+
+	if state.Key != key {
+		return checkpoint, false, nil
+	}
+
+	if state.Apps != nil {
+		checkpoint.state.Apps = state.Apps
+	}
+
+	if state.Uploads != nil {
+		checkpoint.state.Uploads = state.Uploads
+	}
+
+	return checkpoint, true, nil
+}
+
+// Done reports whether the given step has been completed for the given app. Calling Done
+// on a nil checkpoint always returns false.
+func (c *Checkpoint) Done(app, step string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if a, ok := c.state.Apps[app]; ok {
+		for _, s := range a.Steps {
+			if s == step {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Complete marks the given step as completed for the given app, along with the resources
+// used so far, and saves the checkpoint. Calling Complete on a nil checkpoint does nothing.
+func (c *Checkpoint) Complete(app, step string, resources []Resource) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	a, ok := c.state.Apps[app]
+	if !ok {
+		a = &checkpointApp{}
+		c.state.Apps[app] = a
+	}
+
+	a.Steps = append(a.Steps, step)
+
+	for _, r := range resources {
+		if r.App == app {
+			a.Resources = appendResource(a.Resources, r)
+		}
+	}
+
+	return c.save()
+}
+
+// Resources returns the resources recorded for every app, ordered by app. Calling Resources
+// on a nil checkpoint returns nil.
+func (c *Checkpoint) Resources() []Resource {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	apps := make([]string, 0, len(c.state.Apps))
+	for app := range c.state.Apps {
+		apps = append(apps, app)
+	}
+
+	sort.Strings(apps)
+
+	var resources []Resource
+	for _, app := range apps {
+		resources = append(resources, c.state.Apps[app].Resources...)
+	}
+
+	return resources
+}
+
+// UseBuild records the build the given app is released with on the given platform, and saves
+// the checkpoint. If a different build was recorded by a previous run, the steps and resources
+// recorded for the app are discarded first, and the ID of the previous build is returned.
+// Calling UseBuild on a nil checkpoint does nothing.
+func (c *Checkpoint) UseBuild(app, platform, buildID string) (previousBuildID string, err error) {
+	if c == nil {
+		return "", nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	a, ok := c.state.Apps[app]
+	if !ok {
+		a = &checkpointApp{}
+		c.state.Apps[app] = a
+	}
+
+	for _, r := range a.Resources {
+		if r.Platform == platform && r.Type == buildResourceType && r.ID != buildID {
+			previousBuildID = r.ID
+			a = &checkpointApp{}
+			c.state.Apps[app] = a
+
+			break
+		}
+	}
+
+	a.Resources = appendResource(a.Resources, Resource{App: app, Platform: platform, Type: buildResourceType, ID: buildID})
+
+	return previousBuildID, c.save()
+}
+
+// Uploaded reports whether the file described by info was already uploaded under the
+// given key, and hasn't changed since. Calling Uploaded on a nil checkpoint always
+// returns false.
+func (c *Checkpoint) Uploaded(key string, info os.FileInfo) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	upload, ok := c.state.Uploads[key]
+
+	return ok && upload.Size == info.Size() && upload.ModTime.Equal(info.ModTime())
+}
+
+// CompleteUpload records that the file described by info was uploaded under the given
+// key, and saves the checkpoint. Calling CompleteUpload on a nil checkpoint does nothing.
+func (c *Checkpoint) CompleteUpload(key string, info os.FileInfo) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state.Uploads[key] = checkpointUpload{Size: info.Size(), ModTime: info.ModTime()}
+
+	return c.save()
+}
+
+// Remove deletes the saved checkpoint. Calling Remove on a nil checkpoint does nothing.
+func (c *Checkpoint) Remove() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := os.Remove(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (c *Checkpoint) save() error {
+	data, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0750); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, c.path)
+}
+
+func appendResource(resources []Resource, resource Resource) []Resource {
+	for _, r := range resources {
+		if r == resource {
+			return resources
+		}
+	}
+
+	return append(resources, resource)
+}
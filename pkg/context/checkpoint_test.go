@@ -0,0 +1,154 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	key := CheckpointKey{Version: "1.0", Build: "1", PublishMode: "appstore", ConfigHash: "abc"}
+
+	checkpoint, resumed, err := LoadCheckpoint(dir, key)
+	assert.NoError(t, err)
+	assert.False(t, resumed)
+	assert.False(t, checkpoint.Done("com.app", "metadata"))
+
+	err = checkpoint.Complete("com.app", "metadata", []Resource{
+		{App: "com.app", Type: "apps", ID: "1"},
+		{App: "com.other", Type: "apps", ID: "2"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, checkpoint.Done("com.app", "metadata"))
+
+	path := filepath.Join(dir, "screenshot.png")
+	err = os.WriteFile(path, []byte("TEST"), 0600)
+	assert.NoError(t, err)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+
+	assert.False(t, checkpoint.Uploaded("set:"+path, info))
+	err = checkpoint.CompleteUpload("set:"+path, info)
+	assert.NoError(t, err)
+
+	checkpoint, resumed, err = LoadCheckpoint(dir, key)
+	assert.NoError(t, err)
+	assert.True(t, resumed)
+	assert.True(t, checkpoint.Done("com.app", "metadata"))
+	assert.False(t, checkpoint.Done("com.app", "submit"))
+	assert.False(t, checkpoint.Done("com.other", "metadata"))
+	assert.True(t, checkpoint.Uploaded("set:"+path, info))
+	assert.False(t, checkpoint.Uploaded("other:"+path, info))
+	assert.Equal(t, []Resource{{App: "com.app", Type: "apps", ID: "1"}}, checkpoint.state.Apps["com.app"].Resources)
+
+	err = os.WriteFile(path, []byte("CHANGED"), 0600)
+	assert.NoError(t, err)
+	info, err = os.Stat(path)
+	assert.NoError(t, err)
+	assert.False(t, checkpoint.Uploaded("set:"+path, info))
+
+	key.ConfigHash = "def"
+	checkpoint, resumed, err = LoadCheckpoint(dir, key)
+	assert.NoError(t, err)
+	assert.False(t, resumed)
+	assert.False(t, checkpoint.Done("com.app", "metadata"))
+
+	err = checkpoint.Remove()
+	assert.NoError(t, err)
+	err = checkpoint.Remove()
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, checkpointFileName))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCheckpoint_UseBuild(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	key := CheckpointKey{Version: "1.0", PublishMode: "testflight", ConfigHash: "abc"}
+
+	checkpoint := NewCheckpoint(dir, key)
+	previous, err := checkpoint.UseBuild("com.app", "iOS", "1")
+	assert.NoError(t, err)
+	assert.Empty(t, previous)
+	err = checkpoint.Complete("com.app", "metadata (iOS)", []Resource{{App: "com.app", Type: "apps", ID: "TEST"}})
+	assert.NoError(t, err)
+	_, err = checkpoint.UseBuild("com.app", "macOS", "2")
+	assert.NoError(t, err)
+
+	checkpoint, resumed, err := LoadCheckpoint(dir, key)
+	assert.NoError(t, err)
+	assert.True(t, resumed)
+	assert.Equal(t, []Resource{
+		{App: "com.app", Platform: "iOS", Type: "builds", ID: "1"},
+		{App: "com.app", Type: "apps", ID: "TEST"},
+		{App: "com.app", Platform: "macOS", Type: "builds", ID: "2"},
+	}, checkpoint.Resources())
+
+	previous, err = checkpoint.UseBuild("com.app", "iOS", "1")
+	assert.NoError(t, err)
+	assert.Empty(t, previous)
+	assert.True(t, checkpoint.Done("com.app", "metadata (iOS)"))
+
+	previous, err = checkpoint.UseBuild("com.app", "iOS", "3")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", previous)
+	assert.False(t, checkpoint.Done("com.app", "metadata (iOS)"))
+	assert.Equal(t, []Resource{{App: "com.app", Platform: "iOS", Type: "builds", ID: "3"}}, checkpoint.Resources())
+
+	checkpoint, _, err = LoadCheckpoint(dir, key)
+	assert.NoError(t, err)
+	assert.False(t, checkpoint.Done("com.app", "metadata (iOS)"))
+}
+
+func TestCheckpoint_Nil(t *testing.T) {
+	t.Parallel()
+
+	var checkpoint *Checkpoint
+
+	assert.False(t, checkpoint.Done("com.app", "metadata"))
+	assert.NoError(t, checkpoint.Complete("com.app", "metadata", nil))
+	assert.False(t, checkpoint.Uploaded("key", nil))
+	assert.NoError(t, checkpoint.CompleteUpload("key", nil))
+	assert.Nil(t, checkpoint.Resources())
+	previous, err := checkpoint.UseBuild("com.app", "", "1")
+	assert.NoError(t, err)
+	assert.Empty(t, previous)
+	assert.NoError(t, checkpoint.Remove())
+}
+
+func TestCheckpoint_Invalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, checkpointFileName), []byte("{"), 0600)
+	assert.NoError(t, err)
+
+	_, _, err = LoadCheckpoint(dir, CheckpointKey{})
+	assert.Error(t, err)
+}
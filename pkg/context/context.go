@@ -25,6 +25,7 @@ import (
 	ctx "context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -42,6 +43,16 @@ const (
 	PublishModeAppStore PublishMode = "appstore"
 )
 
+// AppErrorMode describes how errors are handled when releasing multiple apps.
+type AppErrorMode string
+
+const (
+	// AppErrorModeFailFast stops releasing apps as soon as one of them fails.
+	AppErrorModeFailFast AppErrorMode = "fail-fast"
+	// AppErrorModeAggregate finishes releasing the remaining apps when one of them fails, and reports all errors together.
+	AppErrorModeAggregate AppErrorMode = "aggregate"
+)
+
 type errInvalidPublishMode struct {
 	Value string
 }
@@ -50,11 +61,20 @@ func (e errInvalidPublishMode) Error() string {
 	return fmt.Sprintf("invalid value %s for publish mode", e.Value)
 }
 
+type errInvalidAppErrorMode struct {
+	Value string
+}
+
+func (e errInvalidAppErrorMode) Error() string {
+	return fmt.Sprintf("invalid value %s for app error mode", e.Value)
+}
+
 // Context carries along some data through the pipes.
 type Context struct {
 	ctx.Context
 	Config                  config.Project
 	RawConfig               config.Project
+	Settings                config.Settings
 	Env                     Env
 	Date                    time.Time
 	Git                     GitInfo
@@ -64,16 +84,35 @@ type Context struct {
 	PublishMode             PublishMode
 	Log                     log.Interface
 	MaxProcesses            int
+	AppConcurrency          int
+	AppErrorMode            AppErrorMode
 	SkipGit                 bool
 	SkipUpdatePricing       bool
 	SkipUpdateMetadata      bool
 	SkipSubmit              bool
+	MetadataOnly            bool
 	OverrideBetaGroups      bool
 	OverrideBetaTesters     bool
+	PreviewBetaRemovals     bool
+	WhatsNew                string
 	VersionIsInitialRelease bool
 	Version                 string
+	VersionFromGit          bool
 	Build                   string
+	BuildID                 string
 	Semver                  Semver
+	AppVersions             map[string]AppVersion
+	ArtifactPath            string
+	NextVersion             bool
+	TagNextVersion          bool
+	PushNextVersion         bool
+	Resources               *Resources
+	PublicLinks             *PublicLinks
+	KeepGoing               bool
+	Summary                 *Summary
+	Resume                  bool
+	StateDirectory          string
+	Checkpoint              *Checkpoint
 }
 
 // Env is the environment variables.
@@ -98,6 +137,15 @@ type Semver struct {
 	RawVersion string
 }
 
+// AppVersion is the version and build a single app is released with, for projects whose apps
+// are versioned independently of each other.
+type AppVersion struct {
+	Version string
+	Build   string
+	Tag     string
+	Semver  Semver
+}
+
 // New context.
 func New(config config.Project) *Context {
 	return Wrap(ctx.Background(), config)
@@ -113,16 +161,52 @@ func NewWithTimeout(config config.Project, timeout time.Duration) (*Context, ctx
 // Wrap wraps an existing context.
 func Wrap(ctx ctx.Context, config config.Project) *Context {
 	return &Context{
-		Context:      ctx,
-		Config:       config,
-		RawConfig:    config,
-		Env:          splitEnv(os.Environ()),
-		Date:         time.Now(),
-		Log:          log.New(),
-		MaxProcesses: 1,
+		Context:        ctx,
+		Config:         config,
+		RawConfig:      config,
+		Env:            splitEnv(os.Environ()),
+		Date:           time.Now(),
+		Log:            log.New(),
+		MaxProcesses:   1,
+		AppConcurrency: 1,
+		Resources:      &Resources{},
+		PublicLinks:    &PublicLinks{},
+		Summary:        &Summary{},
 	}
 }
 
+// StatePath joins the given path elements onto the state directory, resolving it relative to the
+// current directory. Returns an empty string if no state directory is set.
+func (ctx *Context) StatePath(elem ...string) string {
+	if ctx.StateDirectory == "" {
+		return ""
+	}
+
+	dir := ctx.StateDirectory
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(ctx.CurrentDirectory, dir)
+	}
+
+	return filepath.Join(append([]string{dir}, elem...)...)
+}
+
+// VersionForApp returns the version and build the named app is released with. Anything not set
+// for the app specifically falls back to the version and build shared by every app.
+func (ctx *Context) VersionForApp(name string) AppVersion {
+	version := ctx.AppVersions[name]
+
+	if version.Version == "" {
+		version.Version = ctx.Version
+		version.Semver = ctx.Semver
+	}
+
+	if version.Build == "" {
+		version.Build = ctx.Build
+	}
+
+	return version
+}
+
 // Copy returns a copy of the environment.
 func (e Env) Copy() Env {
 	var out = Env{}
@@ -180,3 +264,29 @@ func (m *PublishMode) Set(value string) error {
 func (m PublishMode) Type() string {
 	return "{appstore,testflight}"
 }
+
+// String returns the string value of the mode.
+func (m AppErrorMode) String() string {
+	return string(m)
+}
+
+// Set the mode to an allowed value, or return an error.
+func (m *AppErrorMode) Set(value string) error {
+	switch value {
+	case "fail-fast":
+		*m = AppErrorModeFailFast
+
+		return nil
+	case "aggregate":
+		*m = AppErrorModeAggregate
+
+		return nil
+	}
+
+	return errInvalidAppErrorMode{Value: value}
+}
+
+// Type returns a representation of permissible values.
+func (m AppErrorMode) Type() string {
+	return "{fail-fast,aggregate}"
+}
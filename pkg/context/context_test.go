@@ -21,6 +21,7 @@ along with Cider.  If not, see <http://www.gnu.org/licenses/>.
 package context
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -55,6 +56,45 @@ func TestEnv(t *testing.T) {
 	assert.Equal(t, []string{"DOG=FRIEND"}, env.Strings())
 }
 
+func TestStatePath(t *testing.T) {
+	t.Parallel()
+
+	ctx := New(config.Project{})
+	assert.Empty(t, ctx.StatePath("snapshots"))
+
+	ctx.CurrentDirectory = "project"
+	ctx.StateDirectory = ".cider"
+	assert.Equal(t, filepath.Join("project", ".cider", "snapshots"), ctx.StatePath("snapshots"))
+	assert.Equal(t, filepath.Join("project", ".cider"), ctx.StatePath())
+
+	abs, err := filepath.Abs("state")
+	assert.NoError(t, err)
+
+	ctx.StateDirectory = abs
+	assert.Equal(t, abs, ctx.StatePath())
+}
+
+func TestVersionForApp(t *testing.T) {
+	t.Parallel()
+
+	ctx := New(config.Project{})
+	ctx.Version = "1.0"
+	ctx.Semver = Semver{Major: 1, RawVersion: "1.0"}
+	ctx.Build = "5"
+	assert.Equal(t, AppVersion{Version: "1.0", Build: "5", Semver: ctx.Semver}, ctx.VersionForApp("watch"))
+
+	ctx.AppVersions = map[string]AppVersion{
+		"watch": {Version: "2.1", Semver: Semver{Major: 2, Minor: 1, RawVersion: "2.1"}},
+		"tv":    {Build: "12"},
+	}
+	assert.Equal(t, AppVersion{
+		Version: "2.1",
+		Build:   "5",
+		Semver:  Semver{Major: 2, Minor: 1, RawVersion: "2.1"},
+	}, ctx.VersionForApp("watch"))
+	assert.Equal(t, AppVersion{Version: "1.0", Build: "12", Semver: ctx.Semver}, ctx.VersionForApp("tv"))
+}
+
 func TestPublishMode(t *testing.T) {
 	t.Parallel()
 
@@ -79,3 +119,24 @@ func TestPublishMode(t *testing.T) {
 	err = mode.Set("bad")
 	assert.Error(t, err)
 }
+
+func TestAppErrorMode(t *testing.T) {
+	t.Parallel()
+
+	var mode AppErrorMode
+	mode = AppErrorModeFailFast
+	assert.Equal(t, "fail-fast", mode.String())
+	assert.Equal(t, "{fail-fast,aggregate}", mode.Type())
+	mode = AppErrorModeAggregate
+	assert.Equal(t, "aggregate", mode.String())
+
+	var err error
+	err = mode.Set("fail-fast")
+	assert.NoError(t, err)
+	assert.Equal(t, AppErrorModeFailFast, mode)
+	err = mode.Set("aggregate")
+	assert.NoError(t, err)
+	assert.Equal(t, AppErrorModeAggregate, mode)
+	err = mode.Set("bad")
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package context
+
+import "sync"
+
+// PublicLink is the TestFlight public link of a beta group. App is the name of the app in the
+// configuration, like the names in AppsToRelease.
+type PublicLink struct {
+	App   string `json:"app"`
+	Group string `json:"group"`
+	URL   string `json:"url"`
+}
+
+// PublicLinks is a thread-safe list of the TestFlight public links of beta groups.
+type PublicLinks struct {
+	mu    sync.Mutex
+	items []PublicLink
+}
+
+// Add records the public link of the given app's beta group, replacing any link recorded for the
+// same group before. Calling Add on a nil list does nothing.
+func (l *PublicLinks) Add(app, group, url string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, item := range l.items {
+		if item.App == app && item.Group == group {
+			l.items[i].URL = url
+
+			return
+		}
+	}
+
+	l.items = append(l.items, PublicLink{App: app, Group: group, URL: url})
+}
+
+// List returns a copy of the recorded public links, in the order they were added.
+func (l *PublicLinks) List() []PublicLink {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	items := make([]PublicLink, len(l.items))
+	copy(items, l.items)
+
+	return items
+}
+
+// ForApp returns a map of the names of the given app's beta groups to their public links.
+func (l *PublicLinks) ForApp(app string) map[string]string {
+	var links = make(map[string]string)
+
+	for _, item := range l.List() {
+		if item.App == app {
+			links[item.Group] = item.URL
+		}
+	}
+
+	return links
+}
@@ -0,0 +1,50 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package context
+
+import (
+	"testing"
+
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicLinks(t *testing.T) {
+	t.Parallel()
+
+	var nilLinks *PublicLinks
+
+	nilLinks.Add("My App", "Public", "https://testflight.apple.com/join/TEST")
+	assert.Empty(t, nilLinks.List())
+	assert.Empty(t, nilLinks.ForApp("My App"))
+
+	ctx := New(config.Project{})
+	ctx.PublicLinks.Add("My App", "Public", "https://testflight.apple.com/join/1")
+	ctx.PublicLinks.Add("Other App", "Public", "https://testflight.apple.com/join/2")
+	ctx.PublicLinks.Add("My App", "Public", "https://testflight.apple.com/join/3")
+	assert.Equal(t, []PublicLink{
+		{App: "My App", Group: "Public", URL: "https://testflight.apple.com/join/3"},
+		{App: "Other App", Group: "Public", URL: "https://testflight.apple.com/join/2"},
+	}, ctx.PublicLinks.List())
+	assert.Equal(t, map[string]string{
+		"Public": "https://testflight.apple.com/join/3",
+	}, ctx.PublicLinks.ForApp("My App"))
+}
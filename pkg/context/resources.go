@@ -0,0 +1,88 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package context
+
+import "sync"
+
+// Resource is an App Store Connect resource that was used or created during the release.
+type Resource struct {
+	App      string `json:"app"`
+	Platform string `json:"platform,omitempty"`
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+}
+
+// Resources is a thread-safe list of App Store Connect resources.
+type Resources struct {
+	mu    sync.Mutex
+	items []Resource
+}
+
+// Add records the given resource, unless it was already recorded. Calling Add on a nil list does nothing.
+func (r *Resources) Add(app, resourceType, id string) {
+	r.AddForPlatform(app, "", resourceType, id)
+}
+
+// AddForPlatform records the given resource that belongs to one of the app's platforms, unless
+// it was already recorded. Calling AddForPlatform on a nil list does nothing.
+func (r *Resources) AddForPlatform(app, platform, resourceType, id string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items = appendResource(r.items, Resource{App: app, Platform: platform, Type: resourceType, ID: id})
+}
+
+// Remove forgets the given resource. Calling Remove on a nil list does nothing.
+func (r *Resources) Remove(resource Resource) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, item := range r.items {
+		if item == resource {
+			r.items = append(r.items[:i], r.items[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// List returns a copy of the recorded resources, in the order they were added.
+func (r *Resources) List() []Resource {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := make([]Resource, len(r.items))
+	copy(items, r.items)
+
+	return items
+}
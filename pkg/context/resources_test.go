@@ -0,0 +1,56 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package context
+
+import (
+	"testing"
+
+	"github.com/cidertool/cider/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResources(t *testing.T) {
+	t.Parallel()
+
+	var nilResources *Resources
+
+	nilResources.Add("com.app", "apps", "TEST")
+	assert.Empty(t, nilResources.List())
+
+	ctx := New(config.Project{})
+	ctx.Resources.Add("com.app", "apps", "1")
+	ctx.Resources.Add("com.app", "builds", "2")
+	ctx.Resources.AddForPlatform("com.app", "macOS", "builds", "3")
+	assert.Equal(t, []Resource{
+		{App: "com.app", Type: "apps", ID: "1"},
+		{App: "com.app", Type: "builds", ID: "2"},
+		{App: "com.app", Platform: "macOS", Type: "builds", ID: "3"},
+	}, ctx.Resources.List())
+
+	ctx.Resources.Add("com.app", "apps", "1")
+	ctx.Resources.Remove(Resource{App: "com.app", Type: "builds", ID: "2"})
+	ctx.Resources.Remove(Resource{App: "com.app", Type: "builds", ID: "4"})
+	nilResources.Remove(Resource{App: "com.app", Type: "apps", ID: "TEST"})
+	assert.Equal(t, []Resource{
+		{App: "com.app", Type: "apps", ID: "1"},
+		{App: "com.app", Platform: "macOS", Type: "builds", ID: "3"},
+	}, ctx.Resources.List())
+}
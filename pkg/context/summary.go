@@ -0,0 +1,87 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package context
+
+import "sync"
+
+// AppStatus describes how releasing an app turned out.
+type AppStatus string
+
+const (
+	// AppStatusSucceeded means the app was released successfully.
+	AppStatusSucceeded AppStatus = "succeeded"
+	// AppStatusSkipped means releasing the app was skipped before it completed.
+	AppStatusSkipped AppStatus = "skipped"
+	// AppStatusFailed means releasing the app failed.
+	AppStatusFailed AppStatus = "failed"
+)
+
+// AppOutcome is the result of releasing a single app.
+type AppOutcome struct {
+	App     string
+	Status  AppStatus
+	Skipped []string
+	Err     error
+}
+
+// Summary is a thread-safe record of the outcome of releasing each app.
+type Summary struct {
+	mu       sync.Mutex
+	outcomes map[string]AppOutcome
+}
+
+// Record stores the outcome of releasing an app, replacing any previous outcome for the same app.
+// Calling Record on a nil summary does nothing.
+func (s *Summary) Record(outcome AppOutcome) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.outcomes == nil {
+		s.outcomes = make(map[string]AppOutcome)
+	}
+
+	s.outcomes[outcome.App] = outcome
+}
+
+// Outcome returns the recorded outcome of releasing the given app, if any.
+func (s *Summary) Outcome(app string) (AppOutcome, bool) {
+	if s == nil {
+		return AppOutcome{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	outcome, ok := s.outcomes[app]
+
+	return outcome, ok
+}
+
+// Failed reports whether releasing the given app failed.
+func (s *Summary) Failed(app string) bool {
+	outcome, ok := s.Outcome(app)
+
+	return ok && outcome.Status == AppStatusFailed
+}
@@ -0,0 +1,48 @@
+/**
+Copyright (C) 2020 Aaron Sky.
+
+This file is part of Cider, a tool for automating submission
+of apps to Apple's App Stores.
+
+Cider is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+Cider is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with Cider.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+
+	var nilSummary *Summary
+
+	nilSummary.Record(AppOutcome{App: "TEST", Status: AppStatusFailed})
+	_, ok := nilSummary.Outcome("TEST")
+	assert.False(t, ok)
+	assert.False(t, nilSummary.Failed("TEST"))
+
+	summary := &Summary{}
+	summary.Record(AppOutcome{App: "TEST", Status: AppStatusFailed})
+	assert.True(t, summary.Failed("TEST"))
+	summary.Record(AppOutcome{App: "TEST", Status: AppStatusSucceeded})
+	assert.False(t, summary.Failed("TEST"))
+
+	outcome, ok := summary.Outcome("TEST")
+	assert.True(t, ok)
+	assert.Equal(t, AppOutcome{App: "TEST", Status: AppStatusSucceeded}, outcome)
+}
@@ -152,6 +152,10 @@ func (r *docRenderer) gatherTypes() error {
 
 	r.insertTypeTree(root, 0)
 
+	if settings, ok := r.Types["Settings"]; ok && settings != nil {
+		r.insertTypeTree(settings, 0)
+	}
+
 	return nil
 }
 